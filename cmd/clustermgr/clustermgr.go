@@ -49,6 +49,8 @@ var (
 	flagVersion     bool
 	flagRateLimit   int
 	flagAMarkdown   bool
+	flagNsFile      string
+	flagForce       bool
 )
 
 func main() {
@@ -59,7 +61,7 @@ func main() {
 	flag.StringVar(&flagConfigNew, "new_config", "", "new configfile")
 	flag.BoolVar(&flagDryrun, "dryrun", false, "dry run -- do not save to etcd")
 	flag.BoolVar(&flagVerbose, "verbose", false, "verbose -- print more info")
-	flag.StringVar(&flagCmd, "cmd", "", "command -- store, redist, redistserv, zonemarkdown")
+	flag.StringVar(&flagCmd, "cmd", "", "command -- store, redist, redistserv, zonemarkdown, purge")
 	flag.StringVar(&flagType, "type", "cluster_info", "type -- cluster_info, auto, abort")
 	flag.IntVar(&flagZoneid, "zone", -1, "specify zone id")
 	flag.IntVar(&flagSkipZone, "skipzone", -1, "specify zone id to skip")
@@ -69,6 +71,8 @@ func main() {
 	flag.BoolVar(&flagVersion, "version", false, "display version information.")
 	flag.IntVar(&flagRateLimit, "ratelimit", 0, "rate limit for redistribution in KB, 0 means not set")
 	flag.BoolVar(&flagAMarkdown, "automarkdown", true, "mark down during redistribution")
+	flag.StringVar(&flagNsFile, "ns_file", "", "namespace purge file")
+	flag.BoolVar(&flagForce, "force", false, "replace or remove a purge request not completed by every node")
 
 	flag.Parse()
 
@@ -137,6 +141,12 @@ func main() {
 		cmd.RestoreCache(flagConfig, flagCache, flagDryrun)
 	} else if flagCmd == "zonemarkdown" {
 		cmd.ZoneMarkDown(flagConfig, flagType, flagZoneid)
+	} else if flagCmd == "purge" {
+		if flagType == "set" && len(flagNsFile) == 0 {
+			printUsage()
+			return
+		}
+		cmd.PurgeNamespace(flagConfig, flagType, flagNsFile, flagForce)
 	} else {
 		printUsage()
 		return
@@ -181,4 +191,7 @@ func printUsage() {
 	fmt.Printf("Dump redist commit to stdout:    ./%s --new_config redist.toml --cmd redist --type commit --dryrun\n", progName)
 	fmt.Printf("Dump redist resume: ./%s --new_config redist.toml --cmd redist --type resume --zone [n] --ratelimit 10000 (optional, in kb)\n", progName)
 	fmt.Printf("Zone markdown:    ./%s --config config.toml --cmd zonemarkdown --type set/get/delete --zone [n] (--zone -1 disables markdwon)\n", progName)
+	fmt.Printf("Namespace purge:  ./%s --config config.toml --cmd purge --type set --ns_file purge.toml\n", progName)
+	fmt.Printf("Purge status:     ./%s --config config.toml --cmd purge --type get/delete\n", progName)
+	fmt.Printf("                  (set/delete require every node to have completed the previous purge, unless --force)\n")
 }
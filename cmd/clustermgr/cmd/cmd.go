@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -31,6 +32,7 @@ import (
 
 	"juno/pkg/cluster"
 	"juno/pkg/etcd"
+	redistst "juno/pkg/stats/redist"
 )
 
 func GetStatus(configFile string) {
@@ -642,3 +644,107 @@ func ZoneMarkDown(configFile string, flagType string, zoneid int) {
 		glog.Infof("Zone markdown, zoneid=%s", val)
 	}
 }
+
+// unfinishedPurgeNodes returns the nodes that have not reported the completion
+// of the purge request. The purged records are only hidden by the request
+// until they have been compacted away, so the request can't be replaced or
+// removed before.
+func unfinishedPurgeNodes(etcdcli *etcd.EtcdClient, rw *etcd.EtcdReadWriter) (nodes []string, err error) {
+	var req *etcd.PurgeRequest
+	if req, err = rw.ReadPurgeRequest(); err != nil || req == nil {
+		return
+	}
+	var c cluster.Cluster
+	if _, err = c.Read(rw); err != nil {
+		return
+	}
+	id := fmt.Sprint(req.PurgeTime)
+	for zoneid := 0; zoneid < int(c.NumZones); zoneid++ {
+		for nodeid := range c.Zones[zoneid].Nodes {
+			key := etcd.KeyPurgeNodeState(zoneid, nodeid)
+			val, _ := etcdcli.GetValue(key)
+			kvs := redistst.NewKVPairs(val)
+			if kvs.GetValue(etcd.TagPurgeId, "") != id || kvs.GetValue(etcd.TagPurgeStatus, "") != etcd.TagPurgeStateFinish {
+				nodes = append(nodes, key)
+			}
+		}
+	}
+	return
+}
+
+func PurgeNamespace(configFile string, flagType string, nsFile string, force bool) {
+	LoadConfig(configFile)
+
+	etcdcli := etcd.NewEtcdClient(&cfg.Etcd, cfg.ClusterName)
+	if etcdcli == nil {
+		glog.Exit("[ERROR] failed to connect to etcd server")
+	}
+	defer etcdcli.Close()
+
+	maxretry := 2
+	rw := etcd.NewEtcdReadWriter(etcdcli)
+
+	if (flagType == "set" || flagType == "delete") && !force {
+		nodes, err := unfinishedPurgeNodes(etcdcli, rw)
+		if err != nil {
+			glog.Exitf("[ERROR] %s", err)
+		}
+		if len(nodes) > 0 {
+			glog.Exitf("[ERROR] purge request not completed by %v. "+
+				"Records not compacted yet would reappear, use --force to %s anyway.", nodes, flagType)
+		}
+	}
+
+	if flagType == "set" {
+		req, err := etcd.NewPurgeRequest(nsFile)
+		if err != nil {
+			glog.Exitf("[ERROR] %s", err)
+		}
+		val, err := req.Encode()
+		if err != nil {
+			glog.Exitf("[ERROR] %s", err)
+		}
+
+		// Clear the state of the previous request.
+		if err = etcdcli.DeleteKeyWithPrefix(etcd.Key(etcd.TagPurgeStatePrefix), true); err != nil {
+			glog.Exit("[ERROR] remove purge state failed")
+		}
+		if err = etcdcli.PutValue(etcd.TagPurgeNamespace, val, maxretry); err != nil {
+			glog.Exit("[ERROR] set purge request failed")
+		}
+		glog.Infof("Purge request %d set: %v", req.PurgeTime, req.Delete)
+
+	} else if flagType == "delete" {
+		if err := etcdcli.DeleteKey(etcd.TagPurgeNamespace); err != nil {
+			glog.Exit("[ERROR] remove purge request failed")
+		}
+		if err := etcdcli.DeleteKeyWithPrefix(etcd.Key(etcd.TagPurgeStatePrefix), true); err != nil {
+			glog.Exit("[ERROR] remove purge state failed")
+		}
+		glog.Info("Purge request removed")
+
+	} else {
+		req, err := rw.ReadPurgeRequest()
+		if err != nil {
+			glog.Exitf("[ERROR] %s", err)
+		}
+		if req == nil {
+			glog.Info("No purge request")
+			return
+		}
+		glog.Infof("Purge request %d: %v", req.PurgeTime, req.Delete)
+
+		state, err := rw.ReadPurgeState()
+		if err != nil {
+			glog.Exitf("[ERROR] %s", err)
+		}
+		keys := make([]string, 0, len(state))
+		for k := range state {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			glog.Infof("%s: %s", k, state[k])
+		}
+	}
+}
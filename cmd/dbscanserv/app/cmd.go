@@ -21,7 +21,6 @@ package app
 
 import (
 	"bufio"
-	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"os"
@@ -32,7 +31,6 @@ import (
 	"juno/third_party/forked/tecbot/gorocksdb"
 
 	"juno/cmd/dbscanserv/prime"
-	"juno/cmd/storageserv/compact"
 	"juno/cmd/storageserv/storage/db"
 )
 
@@ -180,42 +178,10 @@ func (c *CmdLine) doPatch() {
 	CloseConnect()
 }
 
+// Namespaces are now purged online by the storage servers.
 func (c *CmdLine) deleteNamespace() {
-	clusterMap := InitRemoteMap(c.cfgFile)
-
-	event := compact.NewEventConfig(c.nsFile)
-	if event == nil {
-		return
-	}
-	buf := new(bytes.Buffer)
-	if err := compact.EncodeEventConfig(buf, event); err != nil {
-		glog.Exitf("[ERROR] Bad format in %s: %s", c.nsFile, err)
-	}
-	glog.Infof("Delete list: %v", event.Delete)
-
-	if c.zoneid < 0 {
-		glog.Exitf("zone=%d is not allowed.", c.zoneid)
-	}
-
-	ok, addr := LockSession()
-	if !ok {
-		CloseConnect()
-		return
-	}
-
-	for i := c.zoneid; i < clusterMap.GetNumZones(); i++ {
-		PingServersByZone(cmdClearNamespace, i, addr, buf.Bytes())
-		if i == clusterMap.GetNumZones()-1 {
-			break
-		}
-		for j := 0; j < 12; j++ {
-			RefreshLock(addr)
-			time.Sleep(2 * time.Second)
-		}
-	}
-
-	UnlockSession(addr)
-	CloseConnect()
+	glog.Exitf("[ERROR] delete_ns is replaced by online purge: "+
+		"clustermgr --cmd purge --type set --ns_file %s", c.nsFile)
 }
 
 func (c *CmdLine) testGetKey() {
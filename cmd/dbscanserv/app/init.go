@@ -42,12 +42,11 @@ type CmdConfig cmd.Config
 
 type ClusterConfig struct {
 	CmdConfig
-	DB             *db.Config
-	DbScanLog      string
-	DbWatchEnabled bool
-	Sec            sec.Config
-	DbScan         config.DbScan
-	NumConns       int
+	DB        *db.Config
+	DbScanLog string
+	Sec       sec.Config
+	DbScan    config.DbScan
+	NumConns  int
 }
 
 type ClusterMap struct {
@@ -187,7 +186,7 @@ func newClusterMap(file string) (*ClusterMap, string) {
 func (c *ClusterMap) setOtherConfig() {
 
 	if c.DbScan.ListenPort > 0 {
-		SetListenPort(c.DbScan.ListenPort, c.DbWatchEnabled)
+		SetListenPort(c.DbScan.ListenPort)
 	}
 
 	prime.InitFileWriter(c.DbScanLog)
@@ -23,11 +23,8 @@ import (
 	"fmt"
 	"net"
 	"net/rpc"
-	"os"
-	"path/filepath"
 	"strconv"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"juno/third_party/forked/golang/glog"
@@ -83,22 +80,6 @@ type CmdReply struct {
 type KeyPrefix [][]byte
 type NSMap map[string]KeyPrefix
 
-type CmdNamespaceRequest struct {
-	Cmd            int
-	ServerZoneid   int
-	LockServerAddr string
-
-	NamespaceConfig []byte
-}
-
-type CmdNamespaceReply struct {
-	Cmd          int
-	ServerZoneid int
-	Locked       bool
-	Disabled     bool
-	Failed       bool
-}
-
 const (
 	cmdInit = iota + 1
 	cmdRun
@@ -106,24 +87,13 @@ const (
 	cmdStop
 	cmdGet
 	cmdPatch
-
-	cmdAcquireLock
-	cmdRefreshLock
-	cmdReleaseLock
-	cmdClearNamespace
 )
 
 var (
-	listenPort     = 62200
-	dbWatchEnabled bool
+	listenPort = 62200
 
 	cmdQueue = make(chan CmdRequest, 5)
 	initOnce sync.Once
-
-	// For namespace service
-	sessionLockExpireTime int64
-	token                 uint64
-	mutex                 sync.Mutex
 )
 
 func (r *CmdReply) DbRange() string {
@@ -131,9 +101,8 @@ func (r *CmdReply) DbRange() string {
 		r.FirstDbid&0xffff, r.LastDbid>>16, r.LastDbid&0xffff)
 }
 
-func SetListenPort(port int, b bool) {
+func SetListenPort(port int) {
 	listenPort = port
-	dbWatchEnabled = b
 }
 
 func getPeerPort(zoneid int) int {
@@ -156,13 +125,6 @@ func StartListener() chan CmdRequest {
 		// Thread to close DB if idle too long
 		prime.CloseIdleDb()
 
-		// Remove left-over event files
-		pattern := "events/zone_?"
-		files, _ := filepath.Glob(pattern)
-		for _, f := range files {
-			os.Remove(f)
-		}
-
 		// Listener thread
 		go func() {
 
@@ -283,122 +245,3 @@ func (r *Remote) Ping(req CmdRequest, reply *CmdReply) error {
 	}
 	return nil
 }
-
-func (r *Remote) NamespaceService(req CmdNamespaceRequest,
-	reply *CmdNamespaceReply) error {
-
-	reply.Cmd = req.Cmd
-	reply.ServerZoneid = req.ServerZoneid
-	now := time.Now().Unix()
-
-	switch req.Cmd {
-	case cmdAcquireLock:
-		if !dbWatchEnabled {
-			reply.Disabled = true
-			return nil
-		}
-		t := atomic.LoadInt64(&sessionLockExpireTime)
-		if t > now {
-			reply.Locked = true
-			return nil
-		}
-		atomic.StoreInt64(&sessionLockExpireTime, now+15)
-		return nil
-	case cmdRefreshLock:
-		atomic.StoreInt64(&sessionLockExpireTime, now+15)
-		return nil
-	case cmdReleaseLock:
-		atomic.StoreInt64(&sessionLockExpireTime, 0)
-		return nil
-	}
-
-	// Starts a session
-	glog.Infof("Start a new session for zone %d", req.ServerZoneid)
-	glog.Infof("event: %s", string(req.NamespaceConfig))
-
-	// Create an event file.
-	event := fmt.Sprintf("events/zone_%d", req.ServerZoneid)
-	tmp := fmt.Sprintf("events/tmp")
-	err := os.WriteFile(tmp, req.NamespaceConfig, 0644)
-
-	if err != nil {
-		glog.Errorf("%s", err)
-		reply.Failed = true
-		return nil
-	}
-
-	id := beginWork(func() {
-		err := os.Rename(tmp, event)
-		if err != nil {
-			glog.Errorf("%s", err)
-			reply.Failed = true
-		}
-	})
-
-	if reply.Failed {
-		return nil
-	}
-
-	// Wait for finish
-	RefreshLock(req.LockServerAddr)
-	time.Sleep(2 * time.Second)
-	for !isDeleteTaskDone(req.ServerZoneid, reply) {
-		RefreshLock(req.LockServerAddr)
-		time.Sleep(2 * time.Second)
-	}
-
-	// Remove event files
-	pattern := fmt.Sprintf("events/zone_%d*", req.ServerZoneid)
-	files, _ := filepath.Glob(pattern)
-
-	endWork(id, func() {
-		for _, f := range files {
-			os.Remove(f)
-		}
-		CloseConnect()
-	})
-
-	return nil
-}
-
-func beginWork(f func()) uint64 {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	token++
-	f()
-	return token
-}
-
-func endWork(id uint64, f func()) {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	if id != token {
-		return
-	}
-	f()
-}
-
-func fileExist(name string) bool {
-	files, _ := filepath.Glob(name)
-	return len(files) > 0
-}
-
-func isDeleteTaskDone(zoneid int, reply *CmdNamespaceReply) bool {
-
-	event := fmt.Sprintf("events/zone_%d", zoneid)
-	start := fmt.Sprintf("events/zone_%d_start_*", zoneid)
-	done := fmt.Sprintf("events/zone_%d_done_*", zoneid)
-
-	if fileExist(start) || (fileExist(event) && !fileExist(done)) {
-		return false
-	}
-
-	fail := fmt.Sprintf("events/zone_%d_done_*_fail", zoneid)
-	if fileExist(fail) {
-		reply.Failed = true
-	}
-
-	return true
-}
@@ -341,115 +341,3 @@ func IsValidAddr(addr string) bool {
 
 	return true
 }
-
-func (c *RpcClient) callServer(req CmdNamespaceRequest, result chan bool) {
-
-	var reply CmdNamespaceReply
-
-	err := c.Timeout(600, 15).Invoke("Remote.NamespaceService", req, &reply)
-	if err != nil {
-		glog.Errorf("[ERROR] Server=%s err=%s", c.serverIP, err)
-		result <- false
-		return
-	}
-
-	if reply.Failed {
-		glog.Errorf("[ERROR] Server=%s failed.", c.serverIP)
-		result <- false
-		return
-	}
-
-	result <- true
-}
-
-func LockSession() (bool, string) {
-	for _, client := range remoteMap {
-
-		if (client.zoneidHash & 0x1) == 0 {
-			continue
-		}
-
-		req := CmdNamespaceRequest{
-			Cmd:          cmdAcquireLock,
-			ServerZoneid: 0,
-		}
-
-		var reply CmdNamespaceReply
-		err := client.Invoke("Remote.NamespaceService", req, &reply)
-		if err != nil {
-			glog.Errorf("[ERROR] Server=%s err=%s", client.serverIP, err)
-			return false, ""
-		}
-
-		if reply.Locked {
-			glog.Errorf("[ERROR] Namespace deletion is still running. " +
-				"No new session can be started.")
-			return false, ""
-		}
-		if reply.Disabled {
-			glog.Errorf("[ERROR] Namespace deletion is not enabled.")
-			return false, ""
-		}
-
-		return true, client.serverIP
-	}
-
-	glog.Errorf("[ERROR] Namespace deletion failed to start.")
-	return false, ""
-}
-
-func UnlockSession(addr string) {
-	req := CmdNamespaceRequest{
-		Cmd:          cmdReleaseLock,
-		ServerZoneid: 0,
-	}
-
-	var reply CmdNamespaceReply
-	c := GetRpcClient(addr)
-	c.Invoke("Remote.NamespaceService", req, &reply)
-}
-
-func RefreshLock(addr string) {
-	req := CmdNamespaceRequest{
-		Cmd:          cmdRefreshLock,
-		ServerZoneid: 0,
-	}
-
-	var reply CmdNamespaceReply
-	c := GetRpcClient(addr)
-	c.Invoke("Remote.NamespaceService", req, &reply)
-}
-
-func PingServersByZone(cmd int, zoneid int, addr string, ns []byte) bool {
-
-	count := 0
-
-	result := make(chan bool, 200)
-	for _, client := range remoteMap {
-
-		if ((client.zoneidHash >> zoneid) & 0x1) == 0 {
-			continue // zoneid not matched
-		}
-		req := CmdNamespaceRequest{
-			Cmd:             cmd,
-			ServerZoneid:    zoneid,
-			LockServerAddr:  addr,
-			NamespaceConfig: ns,
-		}
-
-		go client.callServer(req, result)
-		count++
-	}
-
-	ok := 0
-	for i := 0; i < count; i++ {
-		w := <-result
-		if w {
-			ok++
-		}
-	}
-
-	glog.Infof("zone=%d success_count=%d failure_count=%d", zoneid, ok, count-ok)
-
-	return (count == ok)
-}
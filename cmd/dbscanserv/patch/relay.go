@@ -24,22 +24,11 @@ import (
 
 	"juno/third_party/forked/golang/glog"
 
-	"juno/cmd/dbscanserv/app"
-	"juno/cmd/dbscanserv/config"
 	"juno/cmd/storageserv/storage/db"
 	"juno/pkg/proto"
 )
 
-// Called by storageserv.
-func Init(cfg *config.DbScan) {
-	app.InitPatch(cfg)
-}
-
-// Called by storageserv.
-// key is recordId.GetKey().
-func RelayDelete(ns []byte, key []byte, rec *db.Record) error {
-	return app.RelayDelete(ns, key, rec)
-}
+const e9 = uint64(time.Second)
 
 // Called by storageserv.
 func DeleteNeeded(op *proto.OperationalMessage, rec *db.Record) bool {
@@ -59,7 +48,7 @@ func DeleteNeeded(op *proto.OperationalMessage, rec *db.Record) bool {
 			op.GetCreationTime(), rec.CreationTime,
 			op.GetVersion(), rec.Version,
 			op.GetExpirationTime()-now, rec.ExpirationTime-now,
-			op.GetLastModificationTime()/app.E9, rec.LastModificationTime/app.E9,
+			op.GetLastModificationTime()/e9, rec.LastModificationTime/e9,
 			key, tail)
 	}
 
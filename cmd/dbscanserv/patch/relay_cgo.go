@@ -0,0 +1,40 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build cgo
+// +build cgo
+
+package patch
+
+import (
+	"juno/cmd/dbscanserv/app"
+	"juno/cmd/dbscanserv/config"
+	"juno/cmd/storageserv/storage/db"
+)
+
+// Called by storageserv.
+func Init(cfg *config.DbScan) {
+	app.InitPatch(cfg)
+}
+
+// Called by storageserv.
+// key is recordId.GetKey().
+func RelayDelete(ns []byte, key []byte, rec *db.Record) error {
+	return app.RelayDelete(ns, key, rec)
+}
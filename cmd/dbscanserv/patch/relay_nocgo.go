@@ -0,0 +1,44 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !cgo
+// +build !cgo
+
+package patch
+
+import (
+	"juno/third_party/forked/golang/glog"
+
+	"juno/cmd/dbscanserv/config"
+	"juno/cmd/storageserv/storage/db"
+)
+
+// Without cgo, the dbscan relay, using rocksdb, is not available.
+
+// Called by storageserv.
+func Init(cfg *config.DbScan) {
+	if len(cfg.ReplicationAddr) != 0 {
+		glog.Exitf("DbScan.ReplicationAddr requires a build with cgo")
+	}
+}
+
+// Called by storageserv.
+func RelayDelete(ns []byte, key []byte, rec *db.Record) error {
+	return nil
+}
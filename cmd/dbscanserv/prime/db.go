@@ -221,7 +221,7 @@ func NewDbHandle(zoneid int, dbpath string, readOnly bool) (handle *gorocksdb.DB
 	if readOnly {
 		handle, err = gorocksdb.OpenDbForReadOnly(opts, dbpath, true)
 	} else {
-		handle, err = gorocksdb.OpenDb(opts, dbpath)
+		handle, err = db.OpenDb(opts, dbpath)
 	}
 	if err != nil {
 		msg := fmt.Sprintf("dbpath=%s, %s", dbpath, err)
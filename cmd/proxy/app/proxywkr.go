@@ -125,7 +125,7 @@ func (c *Worker) Exec() {
 	if cfg.EtcdEnabled {
 		initmgr.RegisterWithFuncs(watcher.Initialize, watcher.Finalize, cfg.ClusterName, etcd.GetEtcdCli(), &cfg.Etcd)
 	}
-	udf.Init("")
+	udf.InitWithConfig(cfg.UDF)
 
 	initmgr.Init()
 
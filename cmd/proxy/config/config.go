@@ -39,6 +39,7 @@ import (
 	otel "juno/pkg/logging/otel/config"
 	"juno/pkg/sec"
 	"juno/pkg/service"
+	"juno/pkg/udf"
 	"juno/pkg/util"
 	"juno/pkg/version"
 )
@@ -94,6 +95,7 @@ var (
 			Environment: "PayPal",
 			Poolname:    "junoproxy",
 		},
+		UDF: udf.DefaultConfig,
 	}
 )
 
@@ -141,6 +143,7 @@ type Config struct {
 	Etcd         etcd.Config
 	Sec          sec.Config
 	OTEL         otel.Config
+	UDF          udf.Config
 }
 
 func (c *Config) GetNumWrites() uint32 {
@@ -173,6 +176,9 @@ func (c *Config) validatePathAndFileNames() (err error) {
 	c.validatePath(&c.Sec.CertPemFilePath)
 	c.validatePath(&c.Sec.KeyPemFilePath)
 	c.validatePath(&c.Sec.KeyStoreFilePath)
+	if len(c.UDF.Dir) != 0 {
+		c.validatePath(&c.UDF.Dir)
+	}
 	c.validatePath(&c.Etcd.CacheDir)
 	c.validatePath(&c.PidFileName)
 	return
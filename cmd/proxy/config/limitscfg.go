@@ -45,16 +45,31 @@ var (
 )
 
 type (
+	// RateLimits are enforced by each proxy worker independently with token
+	// buckets. 0 means no limit. Bytes are those of the key and the value of
+	// the requests.
+	RateLimits struct {
+		MaxRequestsPerSec uint32
+		MaxBytesPerSec    uint32
+	}
+
 	Limits struct {
 		MaxTimeToLive    uint32
 		MaxPayloadLength uint32
 		MaxKeyLength     uint32
+		RateLimits
 	}
 
+	// LimitsConfig holds the default limits, which apply to each namespace without its
+	// own, and the limits of the namespaces. The default rate limits are shared
+	// by the namespaces without their own, as a whole. App holds the rate limits
+	// of the applications, by the appname of the source info of the requests, on
+	// top of the namespace ones.
 	LimitsConfig struct {
 		Limits
 		Timestamp int64
 		Namespace map[string]Limits
+		App       map[string]RateLimits
 	}
 )
 
@@ -167,6 +182,31 @@ func GetLimits(namespace []byte) (limits Limits) {
 	return
 }
 
+// GetNamespaceRateLimits returns the rate limits of the given namespace, and
+// false if they are the default ones
+func GetNamespaceRateLimits(namespace []byte) (limits RateLimits, ok bool) {
+	if cfg := getLimitsConfig(); cfg != nil {
+		var l Limits
+		if l, ok = cfg.Namespace[string(namespace)]; ok {
+			limits = l.RateLimits
+		} else {
+			limits = cfg.RateLimits
+		}
+	} else {
+		limits = defaultLimits().RateLimits
+	}
+	return
+}
+
+// GetAppRateLimits returns the rate limits of the given application, and false
+// if it has none
+func GetAppRateLimits(app []byte) (limits RateLimits, ok bool) {
+	if cfg := getLimitsConfig(); cfg != nil {
+		limits, ok = cfg.App[string(app)]
+	}
+	return
+}
+
 // Copy deep copy the given LimitsConfig
 func (lc *LimitsConfig) Copy(ilc *LimitsConfig) {
 	lc.Timestamp = ilc.Timestamp
@@ -175,6 +215,10 @@ func (lc *LimitsConfig) Copy(ilc *LimitsConfig) {
 	for k, v := range ilc.Namespace {
 		lc.Namespace[k] = v
 	}
+	lc.App = make(map[string]RateLimits)
+	for k, v := range ilc.App {
+		lc.App[k] = v
+	}
 }
 
 func (lc *LimitsConfig) Merge(cmap *cfg.Config) {
@@ -91,6 +91,14 @@ func (rh *RequestHandler) Process(reqCtx io.IRequestContext) error {
 		return rh.ProcessNoop(reqCtx)
 	}
 
+	if op == proto.OpCodeVerHandshake {
+		return rh.ProcessVerHandshake(reqCtx)
+	}
+
+	if op == proto.OpCodeScan {
+		return rh.ProcessScan(reqCtx)
+	}
+
 	if op >= proto.OpCodeLastProxyOp {
 		glog.Error("wrong opcode: ", op)
 		rh.ReplyStatus(op, reqCtx, proto.OpStatusNotSupported)
@@ -156,6 +164,41 @@ func (rh *RequestHandler) ProcessNoop(reqCtx io.IRequestContext) (err error) {
 	return nil
 }
 
+// ProcessVerHandshake replies with the protocol features supported among the
+// ones requested by the client.
+func (rh *RequestHandler) ProcessVerHandshake(reqCtx io.IRequestContext) (err error) {
+	var opmsg proto.OperationalMessage
+	if err = opmsg.Decode(reqCtx.GetMessage()); err != nil {
+		glog.Warningf("failed to decode VerHandshake message: %s", err.Error())
+		rh.ReplyStatus(proto.OpCodeVerHandshake, reqCtx, proto.OpStatusBadMsg)
+		return nil
+	}
+	resp := opmsg.CreateVerHandshakeResponse()
+	if glog.LOG_DEBUG {
+		glog.Debugf("VerHandshake requested: %s negotiated: %s", opmsg.GetHandshakeFeatures(), resp.GetHandshakeFeatures())
+	}
+	var rawResp proto.RawMessage
+	if err = resp.Encode(&rawResp); err != nil {
+		glog.Errorf("failed to encode VerHandshake response: %s", err)
+		return
+	}
+	reqCtx.Reply(io.NewInboundRespose(proto.OpCodeVerHandshake, &rawResp))
+	return nil
+}
+
+// ProcessScan serves a Scan request, which is sent to all the shards instead of
+// being processed by a request processor of the key.
+func (rh *RequestHandler) ProcessScan(reqCtx io.IRequestContext) (err error) {
+	opmsg := &proto.OperationalMessage{}
+	if err = opmsg.Decode(reqCtx.GetMessage()); err != nil {
+		glog.Warningf("failed to decode Scan message: %s", err.Error())
+		rh.ReplyStatus(proto.OpCodeScan, reqCtx, proto.OpStatusBadMsg)
+		return nil
+	}
+	proc.ProcessScan(reqCtx, opmsg)
+	return nil
+}
+
 func (rh *RequestHandler) OnKeepAlive(connector *io.Connector, reqCtx io.IRequestContext) (err error) {
 	rh.ProcessNoop(reqCtx)
 	connector.OnKeepAlive()
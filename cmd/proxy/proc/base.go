@@ -28,6 +28,8 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"juno/third_party/forked/golang/glog"
 
 	"juno/cmd/proxy/config"
@@ -77,6 +79,9 @@ type (
 
 		needApplyUDF() bool
 		applyUDF(opmsg *proto.OperationalMessage)
+		// Sets the value to commit from the prepare responses. Returns false,
+		// with the OpStatus and payload to reply set in opmsg, on failure.
+		applyUDFOnCommit(opmsg *proto.OperationalMessage) bool
 	}
 
 	SSRequestContext struct {
@@ -91,6 +96,7 @@ type (
 		ssResponseOpStatus proto.OpStatus
 		ssIndex            uint32
 		state              ssReqContextState
+		span               trace.Span
 	}
 
 	ProxyInResponseContext struct {
@@ -127,6 +133,7 @@ type (
 		pendingResponseQueue []*SSRequestContext
 		responseTimer        *util.TimerWrapper
 		hasRepliedClient     bool
+		span                 trace.Span
 
 		self IRequestProcessor
 	}
@@ -198,6 +205,10 @@ func NewProxyInRespose(clientRequest *proto.OperationalMessage, m *proto.RawMess
 	rawMsg := r.GetMessage()
 	*rawMsg = *m
 	proto.SetOpCode(rawMsg, opCode)
+	// SS responses have the protocol version of the SS request, version 2 if
+	// the client request of version 1 is traced, see setSSTraceContext. The
+	// response to the client has the version of its request.
+	proto.SetProtocolVersion(rawMsg, clientRequest.GetProtocolVersion())
 	rawMsg.SetAsResponse() ///
 	rawMsg.SetOpaque(clientRequest.GetOpaque())
 	m.GiveUpBufferOwnership()
@@ -231,6 +242,7 @@ func (p *ProcessorBase) Init() {
 		p.responseTimer.Stop()
 	}
 	p.hasRepliedClient = false
+	p.span = nil
 	p.numSSRequestSent = 0
 	p.numSSResponseReceived = 0
 	p.numSSResponseIOError = 0
@@ -243,6 +255,10 @@ func (p *ProcessorBase) needApplyUDF() bool {
 func (p *ProcessorBase) applyUDF(opmsg *proto.OperationalMessage) {
 }
 
+func (p *ProcessorBase) applyUDFOnCommit(opmsg *proto.OperationalMessage) bool {
+	return true
+}
+
 func (p *ProcessorBase) isDone() bool {
 	return (p.numSSRequestSent == p.numSSResponseReceived)
 }
@@ -346,6 +362,7 @@ func (p *ProcessorBase) replyToClient(resp *ResponseWrapper) {
 					msg.Encode(&raw)
 					resp := NewProxyInRespose(&p.clientRequest, &raw, p.requestContext.GetReceiveTime(), logData, callData)
 					p.requestContext.Reply(resp)
+					p.endSpan(proto.OpStatusInternal)
 					return
 
 				} else {
@@ -381,6 +398,7 @@ func (p *ProcessorBase) replyToClient(resp *ResponseWrapper) {
 			}
 
 			p.replicate(opstatus, resp.ssRequest)
+			p.endSpan(opstatus)
 		}
 	}
 }
@@ -409,6 +427,12 @@ func (p *ProcessorBase) replicate(opstatus proto.OpStatus, resp *SSRequestContex
 			repRequest.SetOpCode(proto.OpCodeGet)
 		}
 
+		if p.clientRequest.GetOpCode() == proto.OpCodeUDFSet {
+			// The payload has been set to the result of the UDF.
+			repRequest.SetOpCode(proto.OpCodeSet)
+			repRequest.SetUDFName(nil)
+		}
+
 		if len(opMsg.GetOriginatorRequestID()) != 16 {
 			glog.Warningf("oid not set. rid=%s", p.requestID)
 		}
@@ -437,9 +461,16 @@ func (p *ProcessorBase) replicate(opstatus proto.OpStatus, resp *SSRequestContex
 }
 
 func (p *ProcessorBase) replyStatusToClient(st proto.OpStatus) {
+	p.replyStatusAndValueToClient(st, nil)
+}
+
+func (p *ProcessorBase) replyStatusAndValueToClient(st proto.OpStatus, payload *proto.Payload) {
 	if !p.hasRepliedClient {
 		msg := p.clientRequest.CreateResponse()
 		msg.SetOpStatus(st)
+		if payload != nil {
+			msg.SetPayload(payload)
+		}
 		var rawMsg proto.RawMessage
 		err := msg.Encode(&rawMsg)
 		if err != nil {
@@ -462,10 +493,96 @@ func (p *ProcessorBase) replyStatusToClient(st proto.OpStatus) {
 			}
 			p.hasRepliedClient = true
 			p.requestContext.Reply(resp)
+			p.endSpan(st)
 		}
 	}
 }
 
+// startSpan starts the span of the client request, continuing the trace of the
+// client if any. The trace context of the request is set to the span, for it
+// to be the parent of the replication span.
+func (p *ProcessorBase) startSpan() {
+	if !otel.IsTraceEnabled() {
+		return
+	}
+	opcode := p.clientRequest.GetOpCodeText()
+	if p.clientRequest.IsForReplication() {
+		opcode = "R" + opcode
+	}
+	p.span = otel.StartSpan("proxy."+opcode, p.clientRequest.GetTraceContext(), trace.SpanKindServer,
+		otel.AttrOpCode.String(opcode),
+		otel.AttrNamespace.String(string(p.clientRequest.GetNamespace())),
+		otel.AttrRequestID.String(p.clientRequest.GetRequestIDString()))
+	if traceparent := otel.TraceParent(p.span); traceparent != nil {
+		p.clientRequest.SetTraceContext(traceparent)
+	}
+}
+
+func (p *ProcessorBase) endSpan(st proto.OpStatus) {
+	if p.span != nil {
+		otel.EndSpan(p.span, st)
+		p.span = nil
+	}
+}
+
+// startSSSpan starts the span of the SS request. The SS request, encoded once
+// for all the SSs, has the trace context of the span of the request, set by
+// setSSTraceContext, the parent of the spans of the SSs.
+func (p *ProcessorBase) startSSSpan(st *SSRequestContext, op proto.OpCode, ssIndex uint32) {
+	st.span = nil
+	if p.span == nil || !p.span.IsRecording() {
+		return
+	}
+	st.span = otel.StartChildSpan(p.span, "ss."+op.String(), trace.SpanKindClient,
+		otel.AttrOpCode.String(op.String()),
+		otel.AttrPeer.String(p.ssGroup.processors[ssIndex].Name()))
+}
+
+// setSSTraceContext sets the trace context of the SS request to the span of
+// the request, if recorded, before it is encoded.
+func (p *ProcessorBase) setSSTraceContext(request *proto.OperationalMessage) {
+	if p.span == nil || !p.span.IsRecording() {
+		return
+	}
+	request.SetTraceContext(otel.TraceParent(p.span))
+	request.EnableFeatures(request.GetEnabledFeatures() | proto.FeatureTraceContext)
+}
+
+// setSSRequestFromClientMessage sets the SS request to the message of the
+// client request, or, if the request is traced, to the client request encoded
+// with the trace context.
+func (p *ProcessorBase) setSSRequestFromClientMessage(request *RequestAndStats) error {
+	if p.span == nil || !p.span.IsRecording() {
+		request.raw.ShallowCopy(p.requestContext.GetMessage())
+		return nil
+	}
+	opMsg := p.clientRequest
+	p.setSSTraceContext(&opMsg)
+	return request.resetFromOpMsg(&opMsg)
+}
+
+// endSpan ends the span of the SS request with the time and the status it
+// completed with.
+func (st *SSRequestContext) endSpan() {
+	if st.span == nil {
+		return
+	}
+	status := st.ssResponseOpStatus
+	switch st.state {
+	case stSSResponseReceived, stSSRequestTimeout:
+	case stSSRequestIOError, stSSResponseIOError:
+		status = proto.OpStatusNoStorageServer
+	default:
+		status = proto.OpStatusBusy
+	}
+	end := st.timeRespReceived
+	if end.IsZero() {
+		end = time.Now()
+	}
+	otel.EndSpan(st.span, status, trace.WithTimestamp(end))
+	st.span = nil
+}
+
 func (p *ProcessorBase) setSSOpRequestFromClientRequest(request *proto.OperationalMessage, op proto.OpCode, version uint32, keepValue bool) {
 	*request = p.clientRequest
 	request.SetOpCode(op)
@@ -474,6 +591,7 @@ func (p *ProcessorBase) setSSOpRequestFromClientRequest(request *proto.Operation
 	if keepValue == false {
 		request.ClearPayload()
 	}
+	p.setSSTraceContext(request)
 }
 
 func (p *ProcessorBase) send(request *RequestAndStats, ssIndex uint32) (ok bool) {
@@ -519,6 +637,7 @@ func (p *ProcessorBase) sendMessage(msg *proto.RawMessage, ssIndex uint32) *erro
 	//	c, cancelFunc := context.WithTimeout(p.ctx, confSSRequestTimeout)
 
 	st := &p.ssRequestContexts[p.numSSRequestSent]
+	p.startSSSpan(st, op, ssIndex)
 
 	st.timeReqSent = time.Now()
 	st.timeRespReceived = time.Time{}
@@ -555,6 +674,8 @@ func (p *ProcessorBase) sendMessage(msg *proto.RawMessage, ssIndex uint32) *erro
 			errStr := strings.Replace(err.Error(), " ", "_", -1)
 			calLogReqProcEvent(fmt.Sprintf("SS_%s", errStr), buf.Bytes())
 		}
+		st.state = stSSRequestIOError
+		st.endSpan()
 		return err
 	}
 	return nil
@@ -640,12 +761,18 @@ func (p *ProcessorBase) Process(request io.IRequestContext) bool {
 		p.OnComplete()
 		return true
 	}
+	p.startSpan()
 
 	if p.validateInboundRequest(&p.clientRequest) == false {
 		p.replyStatusToClient(proto.OpStatusBadParam)
 		p.OnComplete()
 		return true
 	}
+	if st, ok := checkRateLimits(&p.clientRequest); !ok {
+		p.replyStatusToClient(st)
+		p.OnComplete()
+		return true
+	}
 
 	p.requestID = p.clientRequest.GetRequestIDString()
 
@@ -740,7 +867,9 @@ func (p *ProcessorBase) OnComplete() {
 			io.ReleaseOutboundResponse(st.ssResponse)
 			st.ssResponse = nil
 		}
+		st.endSpan()
 	}
+	p.endSpan(proto.OpStatusInternal)
 	p.requestContext.Cancel()
 	p.requestContext.OnComplete()
 	p.responseTimer.Stop()
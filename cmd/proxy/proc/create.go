@@ -76,6 +76,7 @@ func (p *CreateProcessor) setInitSSRequest() bool {
 
 	if confEncryptionEnabled && p.clientRequest.GetPayload().GetLength() != 0 && p.clientRequest.GetPayload().GetPayloadType() == proto.PayloadTypeClear {
 		opMsg := p.clientRequest
+		p.setSSTraceContext(&opMsg)
 		if err := opMsg.GetPayload().Encrypt(proto.PayloadTypeEncryptedByProxy); err != nil {
 			errmsg := fmt.Sprintf("err=%s", err.Error())
 			glog.Error(errmsg)
@@ -92,7 +93,9 @@ func (p *CreateProcessor) setInitSSRequest() bool {
 		p.prepare.setShardId(p.shardId)
 	} else {
 		if reEncode {
-			if err := p.prepare.resetFromOpMsg(&p.clientRequest); err != nil {
+			opMsg := p.clientRequest
+			p.setSSTraceContext(&opMsg)
+			if err := p.prepare.resetFromOpMsg(&opMsg); err != nil {
 				return false
 			}
 			p.prepare.setShardId(p.shardId)
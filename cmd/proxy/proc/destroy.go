@@ -40,9 +40,11 @@ func newDestroyProcessor() *DestroyProcessor {
 }
 
 func (p *DestroyProcessor) sendInitRequests() {
-	raw := &p.request.raw
-	raw.ShallowCopy(p.requestContext.GetMessage())
-	proto.SetShardId(raw, p.shardId)
+	if err := p.setSSRequestFromClientMessage(&p.request.RequestAndStats); err != nil {
+		p.replyStatusToClient(proto.OpStatusBadMsg)
+		return
+	}
+	proto.SetShardId(&p.request.raw, p.shardId)
 	if err := p.request.setOpCode(p.ssRequestOpCode); err != nil {
 		p.replyStatusToClient(proto.OpStatusBadMsg)
 		return
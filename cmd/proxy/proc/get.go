@@ -65,6 +65,7 @@ func (p *GetProcessor) sendRepair(ssIndex uint32) {
 		opMsg.SetAsRequest()
 		opMsg.SetOpCode(proto.OpCodeRepair)
 		opMsg.SetShardId(p.shardId)
+		p.setSSTraceContext(&opMsg)
 		p.repair.setFromOpMsg(&opMsg)
 	}
 	p.send(&p.repair, ssIndex)
@@ -88,6 +89,7 @@ func (p *GetProcessor) replyToClientAndRepair() {
 					glog.DebugInfof("oid not set in read response. rid=%s", p.requestID)
 				}
 			}
+			p.setSSTraceContext(&opMsg)
 			opMsg.Encode(&markDelMsg)
 			for i := 0; i < p.request.getNumSuccessResponse(); i++ {
 				t := &p.request.successResponses[i]
@@ -32,6 +32,7 @@ import (
 
 var (
 	confNumWrites                    int
+	confNumScanReads                 int
 	confNumZones                     int
 	confSSRequestTimeout             time.Duration
 	confMaxNumFailures               int
@@ -51,6 +52,8 @@ func InitConfig() {
 	confNumZones = int(config.Conf.ClusterInfo.NumZones)
 	confSSRequestTimeout = config.Conf.ReqProc.SSReqTimeout.Duration
 	confMaxNumFailures = confNumZones - confNumWrites
+	// read quorum of Scan, overlapping every write quorum
+	confNumScanReads = confNumZones - confNumWrites + 1
 	confMaxKeyLength = config.Conf.MaxKeyLength
 	confMaxNamespaceLength = config.Conf.MaxNamespaceLength
 	confMaxPayloadLength = config.Conf.MaxPayloadLength
@@ -71,6 +71,9 @@ const (
 	kBadParamInvalidNsLen    = "BadParam_invalidNsLen"
 	kBadParamInvalidValueLen = "BadParam_InvalidValueLen"
 	kBadParamInvalidTTL      = "BadParam_InvalidTTL"
+
+	kRateLimitedNamespace = "RateLimited_Namespace"
+	kRateLimitedApp       = "RateLimited_App"
 )
 
 var (
@@ -99,8 +99,7 @@ func (p *OnePhaseProcessor) failed() bool {
 }
 
 func (p *OnePhaseProcessor) setInitSSRequest() bool {
-	p.request.raw.ShallowCopy(p.requestContext.GetMessage())
-	return true
+	return p.setSSRequestFromClientMessage(&p.request.RequestAndStats) == nil
 }
 
 func (p *OnePhaseProcessor) sendInitRequests() {
@@ -129,6 +128,7 @@ func (p *OnePhaseProcessor) sendInitRequests() {
 func (p *TwoPhaseProcessor) setInitSSRequest() bool {
 	if confEncryptionEnabled && p.clientRequest.GetPayload().GetLength() != 0 && p.clientRequest.GetPayload().GetPayloadType() == proto.PayloadTypeClear {
 		opMsg := p.clientRequest
+		p.setSSTraceContext(&opMsg)
 		if err := opMsg.GetPayload().Encrypt(proto.PayloadTypeEncryptedByProxy); err != nil {
 			errmsg := fmt.Sprintf("err=%s", err.Error())
 			glog.Error(errmsg)
@@ -142,9 +142,8 @@ func (p *TwoPhaseProcessor) setInitSSRequest() bool {
 		if err := p.prepare.resetFromOpMsg(&opMsg); err != nil {
 			return false
 		}
-	} else {
-		raw := &p.prepare.raw
-		raw.ShallowCopy(p.requestContext.GetMessage())
+	} else if err := p.setSSRequestFromClientMessage(&p.prepare.RequestAndStats); err != nil {
+		return false
 	}
 	p.prepare.setShardId(p.shardId)
 	return true
@@ -0,0 +1,141 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proc
+
+import (
+	"bytes"
+	"sync"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/cmd/proxy/config"
+	"juno/pkg/logging"
+	"juno/pkg/logging/otel"
+	"juno/pkg/proto"
+	"juno/pkg/service"
+)
+
+type (
+	rateLimiterT struct {
+		requests *service.TokenBucket
+		bytes    *service.TokenBucket
+	}
+
+	// rateLimitersT holds the rate limiters by namespace or by appname, of the
+	// ones in the limits config only, so that they are bounded. They are created
+	// on first use, and their rates are updated from the limits config on each
+	// request.
+	rateLimitersT struct {
+		mtx      sync.Mutex
+		limiters map[string]*rateLimiterT
+	}
+)
+
+// key of the rate limiter shared by the namespaces without their own limits
+const kDefaultRateLimiter = ""
+
+var (
+	nsRateLimiters  = rateLimitersT{limiters: make(map[string]*rateLimiterT)}
+	appRateLimiters = rateLimitersT{limiters: make(map[string]*rateLimiterT)}
+)
+
+func (l *rateLimitersT) get(name []byte, limits config.RateLimits) *rateLimiterT {
+	l.mtx.Lock()
+	limiter, ok := l.limiters[string(name)]
+	if !ok {
+		limiter = &rateLimiterT{
+			requests: service.NewTokenBucket(limits.MaxRequestsPerSec),
+			bytes:    service.NewTokenBucket(limits.MaxBytesPerSec),
+		}
+		l.limiters[string(name)] = limiter
+	}
+	l.mtx.Unlock()
+	if ok {
+		limiter.requests.SetRate(limits.MaxRequestsPerSec)
+		limiter.bytes.SetRate(limits.MaxBytesPerSec)
+	}
+	return limiter
+}
+
+// allow takes a request and size bytes, giving the request back if the bytes
+// are not allowed, not to count rejected requests.
+func (l *rateLimiterT) allow(size uint32) bool {
+	if !l.requests.Take(1) {
+		return false
+	}
+	if !l.bytes.Take(size) {
+		l.requests.Refund(1)
+		return false
+	}
+	return true
+}
+
+// refund gives back a request allowed, but rejected by another limiter.
+func (l *rateLimiterT) refund(size uint32) {
+	l.requests.Refund(1)
+	l.bytes.Refund(size)
+}
+
+// checkRateLimits returns false, with the status to reply, if the request
+// exceeds the rate limits of its namespace, or the default ones shared by the
+// namespaces without their own, in which case it is replied with
+// OpStatusBusy for the client to back off, or the rate limits of its
+// application, which is replied with OpStatusServiceDenied.
+func checkRateLimits(r *proto.OperationalMessage) (proto.OpStatus, bool) {
+	if r.IsForReplication() || bytes.Equal(r.GetNamespace(), []byte(config.JunoInternalNamespace())) {
+		return proto.OpStatusNoError, true
+	}
+	size := uint32(len(r.GetKey())) + r.GetPayloadValueLength()
+
+	var nsLimiter *rateLimiterT
+	limits, own := config.GetNamespaceRateLimits(r.GetNamespace())
+	if limits.MaxRequestsPerSec != 0 || limits.MaxBytesPerSec != 0 {
+		key := []byte(kDefaultRateLimiter)
+		if own {
+			key = r.GetNamespace()
+		}
+		nsLimiter = nsRateLimiters.get(key, limits)
+		if !nsLimiter.allow(size) {
+			logRateLimited(kRateLimitedNamespace, r, r.GetNamespace())
+			return proto.OpStatusBusy, false
+		}
+	}
+	if appLimits, ok := config.GetAppRateLimits(r.GetAppName()); ok {
+		if !appRateLimiters.get(r.GetAppName(), appLimits).allow(size) {
+			if nsLimiter != nil {
+				nsLimiter.refund(size)
+			}
+			logRateLimited(kRateLimitedApp, r, r.GetAppName())
+			return proto.OpStatusServiceDenied, false
+		}
+	}
+	return proto.OpStatusNoError, true
+}
+
+func logRateLimited(name string, r *proto.OperationalMessage, limitedBy []byte) {
+	if LOG_DEBUG {
+		glog.DebugInfof("rate limited by %s %s. rid=%s", name, limitedBy, r.GetRequestIDString())
+	}
+	data := logging.NewKVBuffer()
+	data.AddReqIdString(r.GetRequestIDString())
+	data.Add([]byte("by"), string(limitedBy))
+	calLogReqProcEvent(name, data.Bytes())
+	otel.RecordCount(otel.ReqProc, []otel.Tags{{otel.Status, name}})
+}
@@ -50,8 +50,7 @@ func NewRequestProcessorPool(chansize int32, maxsize int32, op proto.OpCode) *Re
 		case proto.OpCodeUDFGet:
 			p = NewUDFGetProcessor()
 		case proto.OpCodeUDFSet:
-			p = NewSetProcessor()
-			//p = NewUDFSetProcessor()
+			p = NewUDFSetProcessor()
 		default:
 			return nil
 		}
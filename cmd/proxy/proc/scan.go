@@ -0,0 +1,208 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proc
+
+import (
+	"context"
+	"sync"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/pkg/cluster"
+	"juno/pkg/io"
+	"juno/pkg/proto"
+	"juno/pkg/shard"
+)
+
+// Maximum number of shards a Scan request is sent to at a time.
+const kMaxScanConcurrency = 16
+
+// ProcessScan serves a Scan request. The request is sent to each shard, to all
+// the connected storage servers of the shard, and the pages returned by at least
+// confNumScanReads of them are merged into the page replied to the client.
+func ProcessScan(reqCtx io.IRequestContext, request *proto.OperationalMessage) {
+	ns := request.GetNamespace()
+	prefix := request.GetKey()
+	scanReq, err := request.GetScanRequest()
+	if err != nil || len(ns) == 0 || len(ns) > confMaxNamespaceLength || len(prefix) > confMaxKeyLength {
+		glog.Warningf("bad Scan request: ns=%q err=%v", ns, err)
+		replyScan(reqCtx, request, proto.OpStatusBadParam, nil)
+		return
+	}
+	if st, ok := checkRateLimits(request); !ok {
+		replyScan(reqCtx, request, st, nil)
+		return
+	}
+	limit := scanReq.GetLimit()
+
+	shardMgr := cluster.GetShardMgr()
+	numShards := int(shardMgr.GetNumShards())
+	pages := make([]proto.ScanPage, numShards)
+	statuses := make([]proto.OpStatus, numShards)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, kMaxScanConcurrency)
+	for i := 0; i < numShards; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			ssReq := proto.NewScanRequest(ns, prefix, &proto.ScanRequest{Limit: uint32(limit), Token: scanReq.Token})
+			ssReq.SetShardId(uint16(i))
+			ssReq.SetRequestID(request.GetRequestID())
+			pages[i], statuses[i] = scanShard(shardMgr, shard.ID(i), ssReq, limit)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, st := range statuses {
+		if st != proto.OpStatusNoError {
+			glog.Warningf("Scan incomplete: shard %d status %s rid=%s", i, st, request.GetRequestIDString())
+			replyScan(reqCtx, request, st, nil)
+			return
+		}
+	}
+
+	// The tombstones are dropped once merged. The token of the page is kept,
+	// as it may be beyond the last record returned.
+	page := proto.MergeScanPages(pages, limit)
+	entries := page.Entries[:0]
+	for _, e := range page.Entries {
+		if e.MarkedDelete {
+			continue
+		}
+		if payload := &e.Payload; payload.GetPayloadType() == proto.PayloadTypeEncryptedByProxy {
+			if err = payload.Decrypt(); err != nil {
+				glog.Errorf("Scan: fail to decrypt: %s", err)
+				replyScan(reqCtx, request, proto.OpStatusInternal, nil)
+				return
+			}
+		}
+		entries = append(entries, e)
+	}
+	page.Entries = entries
+	replyScan(reqCtx, request, proto.OpStatusNoError, &page)
+}
+
+// scanShard returns the page of the shard merged from the pages of its storage
+// servers, which may not be all up to date. The read quorum, confNumScanReads,
+// NumZones - NumWrites + 1, has a storage server of every write quorum, so the
+// merged page has all the writes acknowledged to clients.
+func scanShard(shardMgr *cluster.ShardManager, shardId shard.ID, ssReq *proto.OperationalMessage, limit int) (page proto.ScanPage, st proto.OpStatus) {
+	procs, err := shardMgr.GetProcessors(shardId)
+	if err != nil {
+		glog.Errorf("Scan: no storage server for shard %d: %s", shardId, err)
+		st = proto.OpStatusNoStorageServer
+		return
+	}
+
+	pages := make([]proto.ScanPage, len(procs))
+	statuses := make([]proto.OpStatus, len(procs))
+	oks := make([]bool, len(procs))
+	var wg sync.WaitGroup
+	for i, p := range procs {
+		if p == nil || p.GetIsConnected() == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, p *cluster.OutboundSSProcessor) {
+			defer wg.Done()
+			pages[i], statuses[i], oks[i] = sendScanRequest(p, ssReq)
+		}(i, p)
+	}
+	wg.Wait()
+
+	st = proto.OpStatusNoStorageServer
+	replies := make([]proto.ScanPage, 0, len(procs))
+	for i, p := range procs {
+		if oks[i] && statuses[i] == proto.OpStatusNoError {
+			replies = append(replies, pages[i])
+			continue
+		}
+		if p != nil && p.GetIsConnected() != 0 {
+			zoneId, hostId := p.GetNodeInfo()
+			glog.Warningf("Scan: shard %d ss %d-%d failed, status %s", shardId, zoneId, hostId, statuses[i])
+			if oks[i] {
+				st = statuses[i]
+			}
+		}
+	}
+	if len(replies) < confNumScanReads {
+		return
+	}
+	return proto.MergeScanPages(replies, limit), proto.OpStatusNoError
+}
+
+// sendScanRequest returns the page replied by the storage server, ok being
+// false if no valid response is received in time.
+func sendScanRequest(p *cluster.OutboundSSProcessor, ssReq *proto.OperationalMessage) (page proto.ScanPage, st proto.OpStatus, ok bool) {
+	var raw proto.RawMessage
+	if err := ssReq.Encode(&raw); err != nil {
+		glog.Errorf("Scan: fail to encode request: %s", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), confSSRequestTimeout)
+	defer cancel()
+	ch := make(chan io.IResponseContext, 1)
+	if err := p.SendRequest(io.NewOutboundRequestContext(&raw, 0, ctx, ch, confSSRequestTimeout)); err != nil {
+		return
+	}
+
+	select {
+	case r := <-ch:
+		defer io.ReleaseOutboundResponse(r)
+		if r.GetStatus() != 0 {
+			return
+		}
+		var resp proto.OperationalMessage
+		if err := resp.Decode(r.GetMessage()); err != nil {
+			glog.Errorf("Scan: fail to decode response: %s", err)
+			return
+		}
+		if st = resp.GetOpStatus(); st == proto.OpStatusNoError {
+			var err error
+			if page, err = resp.GetScanPage(); err != nil {
+				glog.Errorf("Scan: bad page: %s", err)
+				return
+			}
+		}
+		ok = true
+	case <-ctx.Done():
+	}
+	return
+}
+
+func replyScan(reqCtx io.IRequestContext, request *proto.OperationalMessage, st proto.OpStatus, page *proto.ScanPage) {
+	resp := request.CreateResponse()
+	resp.SetOpStatus(st)
+	if page != nil {
+		if err := resp.SetScanPage(page); err != nil {
+			glog.Errorf("Scan: %s rid=%s", err, request.GetRequestIDString())
+			resp.SetOpStatus(proto.OpStatusInternal)
+		}
+	}
+	var raw proto.RawMessage
+	if err := resp.Encode(&raw); err != nil {
+		glog.Errorf("Scan: fail to encode response: %s", err)
+		return
+	}
+	reqCtx.Reply(io.NewInboundRespose(proto.OpCodeScan, &raw))
+}
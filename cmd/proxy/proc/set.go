@@ -63,8 +63,13 @@ func (p *SetProcessor) onPrepareSuccess(rc *SSRequestContext) {
 	default:
 
 		if p.prepareSucceeded() {
+			if !p.setCommitMsg() {
+				opMsg := &p.commit.opMsg
+				p.replyStatusAndValueToClient(opMsg.GetOpStatus(), opMsg.GetPayload())
+				p.abortSucceededPrepares()
+				return
+			}
 			p.state = stTwoPhaseProcCommit
-			p.setCommitMsg()
 			p.sendCommits()
 		}
 	}
@@ -119,7 +124,9 @@ func (p *SetProcessor) onBadRequestID(rc *SSRequestContext) {
 	}
 }
 
-func (p *SetProcessor) setCommitMsg() {
+// setCommitMsg returns false if the UDF of a UDFSet fails, in which case the
+// OpStatus and payload of p.commit.opMsg are what to reply to the client.
+func (p *SetProcessor) setCommitMsg() bool {
 	opMsg := &p.commit.opMsg
 	isForReplication := p.clientRequest.IsForReplication()
 	var version, creationTime, ttl uint32
@@ -215,8 +222,12 @@ func (p *SetProcessor) setCommitMsg() {
 			}
 		}
 	}
+	if !p.self.applyUDFOnCommit(opMsg) {
+		return false
+	}
 	p.commit.setFromOpMsg(opMsg)
 	//opMsg.Encode(&p.p2.commitMsg)
+	return true
 }
 
 func (p *SetProcessor) OnResponseReceived(rc *SSRequestContext) {
@@ -51,14 +51,29 @@ func (p *UDFGetProcessor) needApplyUDF() bool {
 	return true
 }
 func (p *UDFGetProcessor) applyUDF(opmsg *proto.OperationalMessage) {
-	mgr := udf.GetUDFManager()
-	udfname := p.clientRequest.GetUDFName()
-
-	if udf := mgr.GetUDF(string(udfname)); udf != nil {
-		if res, err := udf.Call([]byte(""), opmsg.GetPayload().GetData(), p.clientRequest.GetPayload().GetData()); err == nil {
-			opmsg.GetPayload().SetPayload(proto.PayloadTypeClear, res)
-		} else {
-			glog.Info("udf not exist")
-		}
+	if st := opmsg.GetOpStatus(); st != proto.OpStatusNoError && st != proto.OpStatusInconsistent {
+		return
+	}
+	udfname := string(p.clientRequest.GetUDFName())
+	rec := &udf.RecordInfo{
+		Namespace:    p.clientRequest.GetNamespace(),
+		Key:          p.clientRequest.GetKey(),
+		Version:      opmsg.GetVersion(),
+		CreationTime: opmsg.GetCreationTime(),
+		TimeToLive:   opmsg.GetTimeToLive(),
+	}
+	res, err := udf.GetUDFManager().Call(udfname, rec, opmsg.GetPayload().GetData(), p.clientRequest.GetPayload().GetData())
+	if err == nil {
+		opmsg.GetPayload().SetPayload(proto.PayloadTypeClear, res)
+		return
+	}
+	if err == udf.ErrNoUDF {
+		glog.Warningf("udf %s not found", udfname)
+		opmsg.SetOpStatus(proto.OpStatusNoUDF)
+		opmsg.GetPayload().Clear()
+		return
 	}
+	glog.Warningf("udf %s failed: %s", udfname, err)
+	opmsg.SetOpStatus(proto.OpStatusUDFError)
+	opmsg.GetPayload().SetWithClearValue([]byte(err.Error()))
 }
@@ -19,18 +19,123 @@
 
 package proc
 
+import (
+	"juno/third_party/forked/golang/glog"
+
+	"juno/pkg/proto"
+	"juno/pkg/udf"
+)
+
 // SUCCESS: NoError, NoKey, MarkedDelete
 
 var _ ITwoPhaseProcessor = (*UDFSetProcessor)(nil)
 
+// UDFSetProcessor applies the UDF to the current value of the record, and sets
+// the record to the result, atomically.
+//
+// It goes through the same prepare/commit as Set. The prepare responses carry
+// the current value, and the record stays locked in the storage servers until
+// the commit, which carries the result of the UDF applied to the value of the
+// most updated prepare response. So concurrent UDFSets on the same key fail
+// with RecordLocked, which the client retries, rather than losing updates.
+//
+// The response to the client carries the result of the UDF.
 type UDFSetProcessor struct {
 	SetProcessor
+	result []byte
 }
 
 func NewUDFSetProcessor() *UDFSetProcessor {
 	p := &UDFSetProcessor{
-		SetProcessor: SetProcessor{},
+		SetProcessor: SetProcessor{
+			TwoPhaseProcessor: TwoPhaseProcessor{
+				prepareOpCode: proto.OpCodePrepareSet,
+			},
+		},
 	} //proto.OpCodeUDFSet
 	p.self = p
 	return p
 }
+
+func (p *UDFSetProcessor) Init() {
+	p.SetProcessor.Init()
+	p.result = nil
+}
+
+func (p *UDFSetProcessor) needApplyUDF() bool {
+	return true
+}
+
+// applyUDF sets the result of the UDF, computed on commit, as the value of the
+// response to the client.
+func (p *UDFSetProcessor) applyUDF(opmsg *proto.OperationalMessage) {
+	switch opmsg.GetOpStatus() {
+	case proto.OpStatusAlreadyFulfilled:
+		opmsg.SetOpStatus(proto.OpStatusNoError)
+	case proto.OpStatusNoError, proto.OpStatusInconsistent:
+	default:
+		return
+	}
+	opmsg.GetPayload().SetWithClearValue(p.result)
+}
+
+func (p *UDFSetProcessor) applyUDFOnCommit(opmsg *proto.OperationalMessage) bool {
+	udfname := string(p.clientRequest.GetUDFName())
+	rec := &udf.RecordInfo{
+		Namespace: p.clientRequest.GetNamespace(),
+		Key:       p.clientRequest.GetKey(),
+	}
+	var value []byte
+	var err error
+	alreadyFulfilled := false
+	if p.prepare.mostUpdatedOkResponse != nil {
+		resp := &p.prepare.mostUpdatedOkResponse.ssRequest.ssRespOpMsg
+		if resp.GetOpStatus() != proto.OpStatusInserting {
+			rec.Version = resp.GetVersion()
+			rec.CreationTime = resp.GetCreationTime()
+			rec.TimeToLive = resp.GetTimeToLive()
+			if value, err = resp.GetPayload().GetClearValue(); err != nil {
+				return p.onUDFError(opmsg, proto.OpStatusInternal, err)
+			}
+			// Retried request already committed by the storage server. The
+			// value is already the result of the UDF.
+			alreadyFulfilled = resp.GetOpStatus() == proto.OpStatusAlreadyFulfilled
+		}
+	}
+
+	res := value
+	if !alreadyFulfilled {
+		var params []byte
+		if params, err = p.clientRequest.GetPayload().GetClearValue(); err != nil {
+			return p.onUDFError(opmsg, proto.OpStatusBadParam, err)
+		}
+		if res, err = udf.GetUDFManager().Call(udfname, rec, value, params); err != nil {
+			if err == udf.ErrNoUDF {
+				return p.onUDFError(opmsg, proto.OpStatusNoUDF, err)
+			}
+			return p.onUDFError(opmsg, proto.OpStatusUDFError, err)
+		}
+	}
+
+	p.result = res
+	// The client request takes the result, for repairs and replication.
+	p.clientRequest.GetPayload().SetWithClearValue(res)
+	opmsg.SetPayload(p.clientRequest.GetPayload())
+	if confEncryptionEnabled && len(res) != 0 {
+		if err = opmsg.GetPayload().Encrypt(proto.PayloadTypeEncryptedByProxy); err != nil {
+			return p.onUDFError(opmsg, proto.OpStatusInternal, err)
+		}
+	}
+	return true
+}
+
+func (p *UDFSetProcessor) onUDFError(opmsg *proto.OperationalMessage, st proto.OpStatus, err error) bool {
+	glog.Warningf("udfset %s failed: %s. rid=%s", p.clientRequest.GetUDFName(), err, p.requestID)
+	opmsg.SetOpStatus(st)
+	if st == proto.OpStatusUDFError {
+		opmsg.GetPayload().SetWithClearValue([]byte(err.Error()))
+	} else {
+		opmsg.ClearPayload()
+	}
+	return false
+}
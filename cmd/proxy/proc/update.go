@@ -79,6 +79,7 @@ func (p *UpdateProcessor) actIfDoneWithPrepare() bool {
 					markDelete.SetShardId(p.shardId)
 					markDelete.SetRequestID(p.clientRequest.GetRequestID())
 
+					p.setSSTraceContext(&markDelete)
 					var raw proto.RawMessage
 					if markDelete.Encode(&raw) != nil {
 						panic("fail to encode markdelete request")
@@ -24,6 +24,8 @@ import (
 	goio "io"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"juno/third_party/forked/golang/glog"
 
 	"juno/pkg/io"
@@ -62,6 +64,7 @@ type (
 		this              io.IRequestContext
 		dropCnt           *util.AtomicShareCounter
 		errCnt            *util.AtomicShareCounter
+		span              trace.Span
 	}
 
 	mayflyRepRequestT struct {
@@ -78,7 +81,7 @@ type (
 // xuli: revisit. may be better to set deadline when adding it to ringbuffer. Race condition may still exist.
 // Need to consider how to make it consistant for outbound connection to SS and replication targets
 func (r *repReqCreatorT) newRequestContext(recExpirationTime uint32, msg *proto.RawMessage, reqCh chan io.IRequestContext,
-	dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter) io.IRequestContext {
+	dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter, span trace.Span) io.IRequestContext {
 	ctx := &RepRequestContext{
 		targetId:          r.targetId,
 		try_cnt:           1,
@@ -87,6 +90,7 @@ func (r *repReqCreatorT) newRequestContext(recExpirationTime uint32, msg *proto.
 		reqCh:             reqCh,
 		dropCnt:           dropCnt,
 		errCnt:            errCnt,
+		span:              span,
 	}
 	ctx.this = ctx
 	ctx.SetQueTimeout(REPLICATION_RESP_TIMEOUT)
@@ -148,6 +152,7 @@ func (r *RepRequestContext) complete(calstatus string, opStatus string, rht time
 	}
 
 	otel.RecordReplication(opCode, opStatus, target, rht.Microseconds())
+	otel.EndSpanWithStatus(r.span, opStatus, calstatus == cal.StatusSuccess)
 
 	r.this.OnComplete()
 }
@@ -282,7 +287,7 @@ func (r *RepRequestContext) GetReceiveTime() time.Time {
 }
 
 func (c *mayflyRepReqCreatorT) newRequestContext(recExpirationTime uint32, msg *proto.RawMessage,
-	reqCh chan io.IRequestContext, dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter) io.IRequestContext {
+	reqCh chan io.IRequestContext, dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter, span trace.Span) io.IRequestContext {
 	r := &mayflyRepRequestT{
 		RepRequestContext: RepRequestContext{
 			targetId:          c.targetId,
@@ -292,6 +297,7 @@ func (c *mayflyRepReqCreatorT) newRequestContext(recExpirationTime uint32, msg *
 			reqCh:             reqCh,
 			dropCnt:           dropCnt,
 			errCnt:            errCnt,
+			span:              span,
 		},
 	}
 	r.this = r
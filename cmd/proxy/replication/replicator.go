@@ -28,6 +28,8 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"juno/third_party/forked/golang/glog"
 
 	repconfig "juno/cmd/proxy/replication/config"
@@ -36,6 +38,7 @@ import (
 	"juno/pkg/io"
 	"juno/pkg/logging"
 	"juno/pkg/logging/cal"
+	"juno/pkg/logging/otel"
 	"juno/pkg/proto"
 	"juno/pkg/util"
 )
@@ -60,7 +63,7 @@ type (
 	}
 	repReqCtxCreatorI interface {
 		newRequestContext(recExpirationTime uint32, msg *proto.RawMessage, reqCh chan io.IRequestContext,
-			dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter) io.IRequestContext
+			dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter, span trace.Span) io.IRequestContext
 		newKeepAliveRequestContext() io.IRequestContext
 	}
 
@@ -69,6 +72,7 @@ type (
 		reqCtxCreator repReqCtxCreatorI
 		specNsMap     map[string]bool
 		byPassLTM     bool
+		targetId      string
 	}
 )
 
@@ -191,7 +195,7 @@ func (r *Replicator) SendRequest(opMsg *proto.OperationalMessage) { //expiration
 		errCnt := mgr.GetReplicatorErrorCounter(i)
 		if processor.IsReplicable(opMsg) {
 			// deep copy for each replication destination
-			processor.replicate(expirationTime, &msg, dropCnt, errCnt)
+			processor.replicate(expirationTime, &msg, opMsg.GetTraceContext(), dropCnt, errCnt)
 		}
 	}
 	msg.ReleaseBuffer()
@@ -246,6 +250,7 @@ func newReplicationProcessor(target *repconfig.ReplicationTarget, iocfg *io.Outb
 	p := &replicationProcessorT{
 		reqCtxCreator: reqCtxCreator,
 		specNsMap:     nsMap,
+		targetId:      target.Name,
 	}
 	p.Init(target.ServiceEndpoint, iocfg, false)
 	p.SetConnEventHandler(p)
@@ -266,9 +271,29 @@ func (r *replicationProcessorT) IsReplicable(opMsg *proto.OperationalMessage) bo
 	return true
 }
 
-func (r *replicationProcessorT) replicate(recExpirationTime uint32, msg *proto.RawMessage,
+// replicate sends msg to the target. If tracing is enabled, the replication
+// span continues the trace of traceparent, and is the parent of the request
+// to the target.
+func (r *replicationProcessorT) replicate(recExpirationTime uint32, msg *proto.RawMessage, traceparent []byte,
 	dropCnt *util.AtomicShareCounter, errCnt *util.AtomicShareCounter) {
-	req := r.reqCtxCreator.newRequestContext(recExpirationTime, msg, r.GetRequestCh(), dropCnt, errCnt)
+	var span trace.Span
+	if otel.IsTraceEnabled() {
+		span = otel.StartSpan("replicate", traceparent, trace.SpanKindProducer,
+			otel.AttrTarget.String(r.targetId))
+		if span.IsRecording() {
+			var request proto.OperationalMessage
+			if err := request.Decode(msg); err == nil {
+				request.SetTraceContext(otel.TraceParent(span))
+				request.EnableFeatures(request.GetEnabledFeatures() | proto.FeatureTraceContext)
+				var raw proto.RawMessage
+				if err = request.Encode(&raw); err == nil {
+					defer raw.ReleaseBuffer()
+					msg = &raw
+				}
+			}
+		}
+	}
+	req := r.reqCtxCreator.newRequestContext(recExpirationTime, msg, r.GetRequestCh(), dropCnt, errCnt, span)
 	glog.Verbosef("send replication request")
 
 	if err := r.SendRequest(req); err != nil {
@@ -284,6 +309,7 @@ func (r *replicationProcessorT) replicate(recExpirationTime uint32, msg *proto.R
 				buf.Bytes())
 		}
 		dropCnt.Add(1)
+		otel.EndSpanWithStatus(span, "RR_Drop_QueueFull", false)
 		req.OnComplete()
 	}
 }
@@ -29,6 +29,7 @@ import (
 	"juno/cmd/proxy/stats/shmstats"
 	"juno/pkg/cluster"
 	"juno/pkg/stats"
+	"juno/pkg/udf"
 )
 
 type (
@@ -39,6 +40,7 @@ type (
 	htmlSectReplicationStatsT struct{}
 	htmlSectClientStatsT      struct{}
 	htmlSectLimitsConfigT     struct{}
+	htmlSectUDFStatsT         struct{}
 )
 
 func (s *htmlSectServerInfoT) Title() template.HTML {
@@ -201,15 +203,44 @@ func (s *htmlSectLimitsConfigT) Body() template.HTML {
 	conf := config.GetCopyOfLimitsConfig()
 	var buf bytes.Buffer
 	fmt.Fprint(&buf, `<div id="id-limits-config"><table title="limits-config">`)
-	fmt.Fprintf(&buf, "<tr><th>Namespace</th><th>Max Key Length</th><th>Max Payload length</th><th>Max Time to Live</th></tr>\n")
-	fmt.Fprintf(&buf, "<tr><td></td><td>%d</td><td>%d</td><td>%d</td></tr>\n",
-		conf.MaxKeyLength, conf.MaxPayloadLength, conf.MaxTimeToLive)
+	fmt.Fprintf(&buf, "<tr><th>Namespace</th><th>Max Key Length</th><th>Max Payload length</th><th>Max Time to Live</th><th>Max Requests/Sec</th><th>Max Bytes/Sec</th></tr>\n")
+	fmt.Fprintf(&buf, "<tr><td></td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>\n",
+		conf.MaxKeyLength, conf.MaxPayloadLength, conf.MaxTimeToLive, conf.MaxRequestsPerSec, conf.MaxBytesPerSec)
 	for k, v := range conf.Namespace {
 		if k != config.JunoInternalNamespace() {
-			fmt.Fprintf(&buf, "<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td></tr>\n",
-				k, v.MaxKeyLength, v.MaxPayloadLength, v.MaxTimeToLive)
+			fmt.Fprintf(&buf, "<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>\n",
+				k, v.MaxKeyLength, v.MaxPayloadLength, v.MaxTimeToLive, v.MaxRequestsPerSec, v.MaxBytesPerSec)
 		}
 	}
+	fmt.Fprint(&buf, "</table>")
+	if len(conf.App) != 0 {
+		fmt.Fprint(&buf, `<table title="app-rate-limits">`)
+		fmt.Fprintf(&buf, "<tr><th>App</th><th>Max Requests/Sec</th><th>Max Bytes/Sec</th></tr>\n")
+		for k, v := range conf.App {
+			fmt.Fprintf(&buf, "<tr><td>%s</td><td>%d</td><td>%d</td></tr>\n", k, v.MaxRequestsPerSec, v.MaxBytesPerSec)
+		}
+		fmt.Fprint(&buf, "</table>")
+	}
+	fmt.Fprint(&buf, "</div>")
+	return template.HTML(buf.String())
+}
+
+func (s *htmlSectUDFStatsT) Title() template.HTML {
+	return "UDF Calls"
+}
+
+func (s *htmlSectUDFStatsT) Body() template.HTML {
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, `<div id="id-udf-stats"><table title="udf-stats">`)
+	fmt.Fprintf(&buf, "<tr><th>UDF</th><th>Calls</th><th>Errors</th><th>Avg Latency</th><th>Max Latency</th></tr>\n")
+	for _, st := range udf.GetCallStats() {
+		var avg time.Duration
+		if st.NumCalls != 0 {
+			avg = st.TotalLatency / time.Duration(st.NumCalls)
+		}
+		fmt.Fprintf(&buf, "<tr><td>%s</td><td>%d</td><td>%d</td><td>%s</td><td>%s</td></tr>\n",
+			template.HTMLEscapeString(st.Name), st.NumCalls, st.NumErrors, avg, st.MaxLatency)
+	}
 	fmt.Fprint(&buf, "</table></div>")
 	return template.HTML(buf.String())
 }
@@ -109,6 +109,7 @@ func initStatsForWorker(workerId int) {
 		htmlstats.AddSection(&htmlSectReplicationStatsT{})
 	}
 	htmlstats.AddSection(&htmlSectLimitsConfigT{})
+	htmlstats.AddSection(&htmlSectUDFStatsT{})
 	htmlstats.AddSection(&htmlSectClientStatsT{})
 
 	workerIdString = fmt.Sprintf("%d", workerId)
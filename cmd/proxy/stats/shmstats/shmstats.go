@@ -207,7 +207,13 @@ func (s *WorkerStats) PrettyPrint(w goio.Writer) {
 }
 
 func (s *ListenerStats) GetListenAddress() (addr string) {
-	if s.Type != 0 {
+	if io.ListenerType(s.Type) == io.ListenerTypeHTTP {
+		addr = fmt.Sprintf("http:%d", s.Port)
+	} else if io.ListenerType(s.Type) == io.ListenerTypeGRPC {
+		addr = fmt.Sprintf("grpc:%d", s.Port)
+	} else if io.ListenerType(s.Type) == io.ListenerTypeRESP {
+		addr = fmt.Sprintf("resp:%d", s.Port)
+	} else if s.Type != 0 {
 		addr = fmt.Sprintf("ssl:%d", s.Port)
 	} else {
 		addr = fmt.Sprintf(":%d", s.Port)
@@ -656,8 +662,14 @@ func (s *ShmStatsManager) setServerStats(pid int) (err error) {
 		} else {
 			return err
 		}
-		if cfg.Listener[i].SSLEnabled {
-			lsnr.Type = 1
+		if cfg.Listener[i].HTTPEnabled {
+			lsnr.Type = uint16(io.ListenerTypeHTTP)
+		} else if cfg.Listener[i].GRPCEnabled {
+			lsnr.Type = uint16(io.ListenerTypeGRPC)
+		} else if cfg.Listener[i].RESPEnabled {
+			lsnr.Type = uint16(io.ListenerTypeRESP)
+		} else if cfg.Listener[i].SSLEnabled {
+			lsnr.Type = uint16(io.ListenerTypeTCPwSSL)
 		}
 	}
 	numRep := len(cfg.Replication.Targets)
@@ -281,6 +281,15 @@ func SetListeners(lsnrs []io.IListener) {
 		if lsnr.GetType() == io.ListenerTypeTCPwSSL {
 			name = "ssl_conns"
 			width = 12
+		} else if lsnr.GetType() == io.ListenerTypeHTTP {
+			name = "http_conns"
+			width = 12
+		} else if lsnr.GetType() == io.ListenerTypeGRPC {
+			name = "grpc_conns"
+			width = 12
+		} else if lsnr.GetType() == io.ListenerTypeRESP {
+			name = "resp_conns"
+			width = 12
 		} else {
 			name = "conns"
 		}
@@ -92,6 +92,12 @@ func (l *statsLoggerT) Init() {
 					var name string
 					if io.ListenerType(lsnr.Type) == io.ListenerTypeTCPwSSL {
 						name = "ssl_conns"
+					} else if io.ListenerType(lsnr.Type) == io.ListenerTypeHTTP {
+						name = "http_conns"
+					} else if io.ListenerType(lsnr.Type) == io.ListenerTypeGRPC {
+						name = "grpc_conns"
+					} else if io.ListenerType(lsnr.Type) == io.ListenerTypeRESP {
+						name = "resp_conns"
 					} else {
 						name = "conns"
 					}
@@ -0,0 +1,134 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/cmd/storageserv/config"
+	"juno/cmd/storageserv/stats"
+	"juno/cmd/storageserv/storage/db"
+	"juno/pkg/initmgr"
+)
+
+type (
+	// Backup asks the running storage server, through its monitoring
+	// address, to back up the shards of its workers.
+	Backup struct {
+		CmdStorageCommon
+		optDir         string
+		optIncremental bool
+		optWorkerId    int
+		optMonAddr     string
+	}
+
+	// Restore rebuilds the shards of a node from a backup, with the storage
+	// server stopped.
+	Restore struct {
+		CmdStorageCommon
+		optDir   string
+		optForce bool
+	}
+)
+
+func (c *Backup) Init(name string, desc string) {
+	c.CmdStorageCommon.Init(name, desc)
+	c.StringOption(&c.optDir, "dir", "", "specify the backup directory, within DB.BackupDir. DB.BackupDir if not set")
+	c.BoolOption(&c.optIncremental, "incremental", false, "share the sst files with the previous backups in the backup directory")
+	c.IntOption(&c.optWorkerId, "wid|worker-id", -1, "specify the worker to back up. all the workers if not set")
+	c.StringOption(&c.optMonAddr, "mon-addr|monitoring-address", "", "specify the http monitoring address. \n\toverride HttpMonAddr in config file")
+	c.AddExample(name+" -c config.toml -dir /backup/juno -incremental", "\tback up all the workers")
+}
+
+func (c *Backup) Exec() {
+	initmgr.Register(config.Initializer, c.optConfigFile)
+	initmgr.Init()
+
+	addr := config.ServerConfig().HttpMonAddr
+	if len(c.optMonAddr) != 0 {
+		addr = c.optMonAddr
+	}
+	if _, err := strconv.Atoi(addr); err == nil {
+		addr = ":" + addr
+	}
+	if host, port, err := net.SplitHostPort(addr); err == nil && len(host) == 0 {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+
+	query := url.Values{}
+	query.Set("dir", c.optDir)
+	query.Set("incremental", strconv.FormatBool(c.optIncremental))
+	if c.optWorkerId >= 0 {
+		query.Set("wid", strconv.Itoa(c.optWorkerId))
+	}
+	resp, err := http.PostForm("http://"+addr+stats.UrlPathBackup, query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup failed: %s\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+	io.Copy(os.Stdout, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "backup failed: %s\n", resp.Status)
+		os.Exit(1)
+	}
+}
+
+func (c *Restore) Init(name string, desc string) {
+	c.CmdStorageCommon.Init(name, desc)
+	c.StringOption(&c.optDir, "dir", "", "specify the backup, a directory created by the backup command")
+	c.BoolOption(&c.optForce, "force", false, "rename the existing dbs of the node aside instead of failing")
+	c.AddExample(name+" -c config.toml -dir /backup/juno/0-1-20240102T030405Z", "\trestore the shards of node 1 of zone 0")
+}
+
+func (c *Restore) Parse(args []string) (err error) {
+	if err = c.CmdStorageCommon.Parse(args); err != nil {
+		return
+	}
+	if len(c.optDir) == 0 {
+		err = fmt.Errorf("missing -dir option")
+	}
+	return
+}
+
+func (c *Restore) Exec() {
+	initmgr.Register(config.Initializer, c.optConfigFile)
+	initmgr.Init()
+
+	cfg := config.ServerConfig()
+	initmgr.RegisterWithFuncs(glog.Initialize, glog.Finalize, c.optLogLevel, "[restore] ")
+	initmgr.Init()
+
+	m, err := db.Restore(c.optDir, int(cfg.NumPrefixDbs), c.optForce)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore failed: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("restored %d shard(s) of zone %d node %d, backup taken at %s\n",
+		len(m.Shards), m.ZoneId, m.NodeId, m.StartTime)
+}
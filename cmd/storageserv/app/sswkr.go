@@ -125,7 +125,7 @@ func (c *Worker) Exec() {
 
 	reqHandler := handler.NewRequestHandler()
 
-	service, suspend := service.NewService(cfg.Config, reqHandler)
+	service, _ := service.NewService(cfg.Config, reqHandler)
 
 	if len(cfg.HttpMonAddr) != 0 {
 		if c.optIsChild {
@@ -152,7 +152,11 @@ func (c *Worker) Exec() {
 
 	service.Zoneid = int(c.optZoneId)
 	if cfg.DbWatchEnabled {
-		go compact.Watch(int(c.optZoneId), int(c.optMachineIndex), suspend)
+		if cfg.EtcdEnabled {
+			compact.Watch(cfg.ClusterName, int(c.optZoneId), int(c.optMachineIndex), &(cfg.Etcd))
+		} else {
+			glog.Warningf("DbWatchEnabled requires EtcdEnabled")
+		}
 	}
 	service.Run()
 }
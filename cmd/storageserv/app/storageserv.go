@@ -36,13 +36,19 @@ func init() {
 		cmdManager          Manager
 		cmdWorker           Worker
 		cmdMonitoringWorker MonitoringWorker
+		cmdBackup           Backup
+		cmdRestore          Restore
 	)
 	cmdManager.Init("manager", "start as storage server manager")
 	cmdWorker.Init("worker", "start as storage worker")
 	cmdMonitoringWorker.Init("monitor", "start as storage monitoring worker")
+	cmdBackup.Init("backup", "back up the shards of the running storage server")
+	cmdRestore.Init("restore", "restore the shards of a node from a backup")
 	cmd.Register(&cmdManager)
 	cmd.Register(&cmdWorker)
 	cmd.Register(&cmdMonitoringWorker)
+	cmd.Register(&cmdBackup)
+	cmd.Register(&cmdRestore)
 }
 
 func Main() {
@@ -0,0 +1,172 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package compact
+
+import (
+	"fmt"
+	"time"
+
+	"juno/cmd/storageserv/storage/db"
+	"juno/pkg/etcd"
+	redistst "juno/pkg/stats/redist"
+	"juno/third_party/forked/golang/glog"
+)
+
+const (
+	purgePollInterval = 5 * time.Second
+
+	purgeTagDbs     = "dbs"
+	purgeTagKeys    = "keys"
+	purgeTagElapsed = "et"
+)
+
+type purgeWatcherT struct {
+	etcdcli   *etcd.EtcdClient
+	rw        *etcd.EtcdReadWriter
+	stateKey  string
+	purgeTime int64 // of the applied request
+}
+
+// Watch applies the namespace purge request published in etcd while the
+// storage server keeps serving. Purged records become invisible as soon as
+// the request is seen and are removed by a background compaction. Progress
+// is reported under purge_state_<zone>_<node>.
+//
+// The request in etcd is applied before returning, so that the records purged
+// but not compacted yet before a restart are not served.
+func Watch(clustername string, zoneid, nodeid int, cfg *etcd.Config) {
+
+	etcdcli := etcd.GetEtcdCli()
+	if etcdcli == nil {
+		etcdcli = etcd.NewEtcdClient(cfg, clustername)
+	}
+	if etcdcli == nil {
+		glog.Errorf("purge watcher: failed to connect to etcd")
+		return
+	}
+
+	w := &purgeWatcherT{
+		etcdcli:  etcdcli,
+		rw:       etcd.NewEtcdReadWriter(etcdcli),
+		stateKey: etcd.KeyPurgeNodeState(zoneid, nodeid),
+	}
+	applied := w.apply()
+	go w.run(applied)
+}
+
+func (w *purgeWatcherT) run(applied bool) {
+	if applied {
+		w.compactIfNeeded()
+	}
+
+	ticker := time.NewTicker(purgePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if w.apply() {
+				w.compactIfNeeded()
+			}
+		case <-w.etcdcli.GetDoneCh():
+			return
+		}
+	}
+}
+
+// apply reads the purge request and returns true if a new request is applied.
+func (w *purgeWatcherT) apply() bool {
+
+	req, err := w.rw.ReadPurgeRequest()
+	if err != nil {
+		glog.Warningf("purge watcher: %s", err)
+		return false
+	}
+
+	if req == nil {
+		if w.purgeTime != 0 {
+			db.ClearPurge()
+			w.purgeTime = 0
+		}
+		return false
+	}
+
+	if req.PurgeTime == w.purgeTime {
+		return false
+	}
+	w.purgeTime = req.PurgeTime
+
+	entries := make([]db.PurgeEntry, len(req.Delete))
+	for i, e := range req.Delete {
+		entries[i] = db.PurgeEntry{Namespace: e.Namespace, Prefix: e.Prefix}
+	}
+	db.SetPurge(req.PurgeTime, entries)
+	return true
+}
+
+func (w *purgeWatcherT) compactIfNeeded() {
+	// Already compacted before a restart.
+	if val, err := w.etcdcli.GetValue(w.stateKey); err == nil {
+		kvs := redistst.NewKVPairs(val)
+		if kvs.GetValue(etcd.TagPurgeId, "") == fmt.Sprint(w.purgeTime) &&
+			kvs.GetValue(etcd.TagPurgeStatus, "") == etcd.TagPurgeStateFinish {
+			glog.Infof("purge %d already completed", w.purgeTime)
+			return
+		}
+	}
+
+	w.compact()
+}
+
+func (w *purgeWatcherT) compact() {
+
+	start := time.Now()
+	d := db.GetCompactable()
+	if d == nil {
+		glog.Errorf("purge: not supported by the storage engine")
+		w.report(etcd.TagPurgeStateFail, 0, 0, start)
+		return
+	}
+
+	w.report(etcd.TagPurgeStateInprogress, 0, d.NumDbs(), start)
+	err := d.CompactAll(func(i int, total int) {
+		w.report(etcd.TagPurgeStateInprogress, i+1, total, start)
+	})
+
+	if err != nil {
+		w.report(etcd.TagPurgeStateFail, 0, d.NumDbs(), start)
+		return
+	}
+	w.report(etcd.TagPurgeStateFinish, d.NumDbs(), d.NumDbs(), start)
+	glog.Infof("purge %d completed: purged_keys=%d", w.purgeTime, db.GetPurgeCount())
+}
+
+func (w *purgeWatcherT) report(status string, done int, total int, start time.Time) {
+	val := fmt.Sprintf("%s=%s&%s=%d&%s=%d/%d&%s=%d&%s=%d",
+		etcd.TagPurgeStatus, status,
+		etcd.TagPurgeId, w.purgeTime,
+		purgeTagDbs, done, total,
+		purgeTagKeys, db.GetPurgeCount(),
+		purgeTagElapsed, int(time.Since(start).Seconds()))
+
+	if err := w.etcdcli.PutValue(w.stateKey, val, 5, 5); err != nil {
+		glog.Warningf("purge: failed to report state: %s", err)
+	}
+}
@@ -0,0 +1,112 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/cmd/storageserv/storage/db"
+)
+
+// UrlPathBackup takes a backup of the shards of a worker, or of all the
+// workers if served by the monitor, on POST, to the directory of the dir
+// parameter, within DB.BackupDir, or to DB.BackupDir if not set. The
+// incremental=true parameter shares the sst files with the previous backups.
+const UrlPathBackup = "/admin/backup"
+
+func allowPostOnly(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+func httpBackupHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowPostOnly(w, r) {
+		return
+	}
+	dir := r.FormValue("dir")
+	incremental, _ := strconv.ParseBool(r.FormValue("incremental"))
+
+	path, _, err := db.Backup(dir, incremental)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, path)
+}
+
+// httpBackupHandler runs the backups of the workers one at a time, or of the
+// one of the wid query parameter.
+func (c *HttpHandlerForMonitor) httpBackupHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowPostOnly(w, r) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.Form
+	workerIds := make([]int, 0, c.GetNumWorkers())
+	if wid := query.Get("wid"); wid != "" {
+		id, err := strconv.Atoi(wid)
+		if err != nil || id < 0 || id >= c.GetNumWorkers() {
+			http.Error(w, fmt.Sprintf("invalid wid %s", wid), http.StatusBadRequest)
+			return
+		}
+		workerIds = append(workerIds, id)
+		query.Del("wid")
+	} else {
+		for i := 0; i < c.GetNumWorkers(); i++ {
+			workerIds = append(workerIds, i)
+		}
+	}
+
+	var buf bytes.Buffer
+	status := http.StatusOK
+	for _, id := range workerIds {
+		resp, err := http.PostForm(c.GetWorkerUrl(id)+UrlPathBackup, query)
+		if err != nil {
+			glog.Errorln(err)
+			status = http.StatusInternalServerError
+			fmt.Fprintf(&buf, "worker %d: %s\n", id, err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			status = resp.StatusCode
+		}
+		fmt.Fprintf(&buf, "worker %d: %s\n", id, strings.TrimSpace(string(body)))
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
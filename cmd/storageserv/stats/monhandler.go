@@ -94,6 +94,7 @@ func (h *HttpHandlerForMonitor) Init(isChild bool, addrs []string) {
 	HttpServerMux.HandleFunc("/stats/json", h.httpJsonStatsHandler)
 	HttpServerMux.HandleFunc("/stats/text", h.httpTextStatsHandler)
 	HttpServerMux.HandleFunc("/version", version.HttpHandler)
+	HttpServerMux.HandleFunc(UrlPathBackup, h.httpBackupHandler)
 }
 
 func (c *HttpHandlerForMonitor) getFromWorkerWithWorkerId(urlPath string, query url.Values, workerId int) (body []byte, err error) {
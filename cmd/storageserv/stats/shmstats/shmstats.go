@@ -70,6 +70,9 @@ type (
 		CompCountByInterval uint32
 		PendingCompKBytes   uint64
 		DelayedWriteRate    uint64
+		ReapedKeys          uint64
+		ReapedKBytes        uint64
+		ReaperLagSec        uint64
 	}
 	WorkerStats struct {
 		Pid              uint32
@@ -62,6 +62,9 @@ func (w *stateLogShmWriter) Write(now time.Time) error {
 			CompCountByInterval: atomic.LoadUint32(&statsCompCountByInterval),
 			PendingCompKBytes:   atomic.LoadUint64(&statsPendingCompKBytes),
 			DelayedWriteRate:    atomic.LoadUint64(&statsDelayedWriteRate),
+			ReapedKeys:          atomic.LoadUint64(&statsReapedKeys),
+			ReapedKBytes:        atomic.LoadUint64(&statsReapedKBytes),
+			ReaperLagSec:        atomic.LoadUint64(&statsReaperLagSec),
 		})
 	}
 
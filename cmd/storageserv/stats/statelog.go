@@ -66,6 +66,9 @@ var (
 	statsCompCountByInterval uint32
 	statsPendingCompKBytes   uint64
 	statsDelayedWriteRate    uint64
+	statsReapedKeys          uint64
+	statsReapedKBytes        uint64
+	statsReaperLagSec        uint64
 
 	theDbPaths  []string
 	rusage      *syscall.Rusage
@@ -335,6 +338,11 @@ func (l *StateLog) ProcessWrite(cnt int) {
 	n = db.GetDB().GetIntProperty("actual-delayed-write-rate")
 	atomic.StoreUint64(&statsDelayedWriteRate, n)
 
+	reaper := db.GetExpiryReaperStats()
+	atomic.StoreUint64(&statsReapedKeys, reaper.ReapedKeys)
+	atomic.StoreUint64(&statsReapedKBytes, reaper.ReapedBytes/1000)
+	atomic.StoreUint64(&statsReaperLagSec, uint64(reaper.Lag/time.Second))
+
 	if statsCompSecPrev == 0 || compSec < statsCompSecPrev {
 		statsCompSecPrev = compSec
 	}
@@ -57,6 +57,7 @@ func initForWorker(isChild bool, workerId int, zoneId uint32, machineIndex uint3
 
 	addPage("/debug/dbstats/", httpDebugDbStatsHandler)
 	addPage("/debug/config", debugConfigHandler)
+	HttpServerMux.HandleFunc(UrlPathBackup, httpBackupHandler)
 
 	if debug.DEBUG {
 		addPage("/debug/memstats", debugMemStatsHandler)
@@ -114,6 +114,9 @@ func (l *statsLoggerT) Init() {
 						stats.NewUint32State(&st.StorageStats.CompCountByInterval, "compCount", "Compaction Count"),
 						stats.NewUint64State(&st.StorageStats.PendingCompKBytes, "pCompKB", "Pending Compaction KBytes"),
 						stats.NewUint64State(&st.StorageStats.DelayedWriteRate, "stall", "Actural Delayed Write Rate"),
+						stats.NewUint64State(&st.StorageStats.ReapedKeys, "reaped", "Number of Expired Keys Reaped"),
+						stats.NewUint64State(&st.StorageStats.ReapedKBytes, "reapedKB", "Expired KBytes Reaped"),
+						stats.NewUint64State(&st.StorageStats.ReaperLagSec, "reapLag", "Expiry Reaper Lag (sec)"),
 						stats.NewFloat32State(&st.ProcCpuUsage, "pCPU", "Process CPU usage percentage", 1),
 						stats.NewFloat32State(&st.MachCpuUsage, "mCPU", "Machine CPU usage percentage", 1),
 					}...)
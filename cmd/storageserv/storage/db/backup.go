@@ -0,0 +1,492 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/pkg/shard"
+)
+
+// A backup of a storage node is a directory under the backup directory,
+// named <zone>-<node>-<time>, with a sub directory per rocksdb instance,
+// holding the files of its checkpoint, or a log file per shard for the memory
+// engine, and the manifest, written last.
+//
+// With incremental backups, the sst files, immutable, are hard links to the
+// ones in the shared directory of the backup directory, copied only once, and
+// named <number>_<crc32c>_<size>.sst.
+const (
+	BackupManifestFile = "backup.json"
+
+	kBackupSharedDir  = "shared"
+	kBackupTimeLayout = "20060102T150405Z"
+)
+
+type (
+	BackupFile struct {
+		Name   string
+		Size   int64
+		Shared bool   `json:",omitempty"`
+		Crc32  uint32 `json:",omitempty"` // crc32c of the shared files
+	}
+
+	BackupDb struct {
+		Name           string
+		WalDir         string `json:",omitempty"`
+		Shards         []shard.ID
+		CheckpointTime time.Time
+		Files          []BackupFile
+	}
+
+	BackupManifest struct {
+		ZoneId             int
+		NodeId             int
+		Engine             string
+		NumPrefixDbs       int
+		MicroShardsEnabled bool
+		Incremental        bool
+		Shards             []shard.ID
+		StartTime          time.Time
+		EndTime            time.Time
+		Dbs                []BackupDb
+	}
+
+	// backuperI is implemented by the storage engines supporting Backup.
+	backuperI interface {
+		backup(dir string, incremental bool) (path string, m *BackupManifest, err error)
+	}
+)
+
+var backupInProgress int32
+
+// Backup takes a backup of the shards of the node, while serving, to a new
+// directory under dir, and returns its path. dir, relative to DBConfig.BackupDir
+// if not absolute, must be within DBConfig.BackupDir.
+func Backup(dir string, incremental bool) (path string, m *BackupManifest, err error) {
+	if dir, err = resolveBackupDir(DBConfig.BackupDir, dir); err != nil {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&backupInProgress, 0, 1) {
+		err = errors.New("backup in progress")
+		return
+	}
+	defer atomic.StoreInt32(&backupInProgress, 0)
+
+	if d, ok := GetDB().(backuperI); ok {
+		path, m, err = d.backup(dir, incremental)
+	} else {
+		err = errors.New("backup not supported by the storage engine")
+	}
+	if err != nil {
+		glog.Errorf("backup to %s failed: %s", dir, err)
+		return
+	}
+	glog.Infof("backup %s completed in %s", path, m.EndTime.Sub(m.StartTime))
+	return
+}
+
+// resolveBackupDir returns the absolute path of dir, with the symbolic links
+// of its existing part resolved, if it is within root.
+func resolveBackupDir(root string, dir string) (string, error) {
+	if len(root) == 0 {
+		return "", errors.New("backup not enabled, DB.BackupDir not set")
+	}
+	var err error
+	if root, err = realPath(root); err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	if dir, err = realPath(dir); err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s not within the backup directory %s", dir, root)
+	}
+	return dir, nil
+}
+
+// realPath returns the absolute path of path, with the symbolic links of its
+// longest existing prefix resolved.
+func realPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+func newBackupManifest(zoneId int, nodeId int, shards shard.Map) *BackupManifest {
+	m := &BackupManifest{
+		ZoneId:             zoneId,
+		NodeId:             nodeId,
+		Engine:             DBConfig.Engine,
+		MicroShardsEnabled: enableMircoShardId,
+		Shards:             shards.Keys(),
+		StartTime:          time.Now().UTC(),
+	}
+	if len(m.Engine) == 0 {
+		m.Engine = EngineRocksDB
+	}
+	sort.Slice(m.Shards, func(i, j int) bool { return m.Shards[i] < m.Shards[j] })
+	return m
+}
+
+func (m *BackupManifest) name() string {
+	return fmt.Sprintf("%d-%d-%s", m.ZoneId, m.NodeId, m.StartTime.Format(kBackupTimeLayout))
+}
+
+func (m *BackupManifest) write(path string) (err error) {
+	m.EndTime = time.Now().UTC()
+	var data []byte
+	if data, err = json.MarshalIndent(m, "", "  "); err != nil {
+		return
+	}
+	tmpPath := filepath.Join(path, BackupManifestFile+".tmp")
+	if err = os.WriteFile(tmpPath, data, 0644); err != nil {
+		return
+	}
+	return os.Rename(tmpPath, filepath.Join(path, BackupManifestFile))
+}
+
+func ReadBackupManifest(path string) (m *BackupManifest, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filepath.Join(path, BackupManifestFile)); err != nil {
+		return
+	}
+	m = &BackupManifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		m = nil
+	}
+	return
+}
+
+// copyCheckpoint copies the files of a checkpoint from src to dst. The sst
+// files are linked to the ones in shared instead, if not empty.
+func copyCheckpoint(src string, dst string, shared string) (files []BackupFile, err error) {
+	var entries []fs.DirEntry
+	if entries, err = os.ReadDir(src); err != nil {
+		return
+	}
+	if err = os.MkdirAll(dst, 0777); err != nil {
+		return
+	}
+	if len(shared) != 0 {
+		if err = os.MkdirAll(shared, 0777); err != nil {
+			return
+		}
+	}
+	for _, e := range entries {
+		var info fs.FileInfo
+		if info, err = e.Info(); err != nil {
+			return
+		}
+		f := BackupFile{Name: e.Name(), Size: info.Size()}
+		srcPath := filepath.Join(src, f.Name)
+		dstPath := filepath.Join(dst, f.Name)
+
+		if len(shared) != 0 && strings.HasSuffix(f.Name, ".sst") {
+			// sst file numbers are unique within a db, but restarting from
+			// a restored or rebuilt db may reuse them for different content
+			if f.Crc32, err = fileCrc32(srcPath); err != nil {
+				return
+			}
+			sharedPath := filepath.Join(shared, fmt.Sprintf("%s_%d_%d.sst", strings.TrimSuffix(f.Name, ".sst"), f.Crc32, f.Size))
+			if _, err = os.Stat(sharedPath); errors.Is(err, fs.ErrNotExist) {
+				err = copyFile(srcPath, sharedPath)
+			}
+			if err == nil {
+				err = os.Link(sharedPath, dstPath)
+			}
+			f.Shared = true
+		} else {
+			err = copyFile(srcPath, dstPath)
+		}
+		if err != nil {
+			return
+		}
+		files = append(files, f)
+	}
+	return
+}
+
+func fileCrc32(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	h := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	if _, err = io.Copy(h, f); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+func copyFile(src string, dst string) (err error) {
+	var in, out *os.File
+	if in, err = os.Open(src); err != nil {
+		return
+	}
+	defer in.Close()
+
+	tmpPath := dst + ".tmp"
+	if out, err = os.Create(tmpPath); err != nil {
+		return
+	}
+	if _, err = io.Copy(out, in); err == nil {
+		err = out.Sync()
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dst)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return
+}
+
+// backup takes a full backup, the log files of the memory engine not being
+// shared by incremental backups.
+func (m *MemDB) backup(dir string, incremental bool) (path string, manifest *BackupManifest, err error) {
+	m.mtx.RLock()
+	manifest = newBackupManifest(m.zoneId, m.nodeId, m.shards)
+	ids := make([]shard.ID, 0, len(m.dbs))
+	shards := make(map[shard.ID]*memShardT, len(m.dbs))
+	for id, s := range m.dbs {
+		ids = append(ids, id)
+		shards[id] = s
+	}
+	m.mtx.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	path = filepath.Join(dir, manifest.name())
+	if err = os.MkdirAll(dir, 0777); err != nil {
+		return
+	}
+	if err = os.Mkdir(path, 0777); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(path)
+		}
+	}()
+
+	for _, id := range ids {
+		b := BackupDb{
+			Name:           memWalName(m.zoneId, m.nodeId, id),
+			Shards:         []shard.ID{id},
+			CheckpointTime: time.Now().UTC(),
+		}
+		var w *walT
+		if w, err = createWal(filepath.Join(path, b.Name), shards[id].snapshot(), false); err != nil {
+			return
+		}
+		b.Files = []BackupFile{{Name: b.Name, Size: w.size}}
+		w.close()
+		manifest.Dbs = append(manifest.Dbs, b)
+	}
+	err = manifest.write(path)
+	return
+}
+
+// Restore rebuilds the shards of the node from the backup at path, with the
+// storage server stopped. The existing db directories, or logs of the memory
+// engine, are renamed with the .replaced-<time> suffix if force, otherwise
+// fail the restore. The expiry index of a prefix db, a column family of it,
+// is restored with the db, consistent with its records.
+func Restore(path string, numPrefixDbs int, force bool) (m *BackupManifest, err error) {
+	if m, err = ReadBackupManifest(path); err != nil {
+		return
+	}
+	engine := DBConfig.Engine
+	if len(engine) == 0 {
+		engine = EngineRocksDB
+	}
+	if m.Engine != engine {
+		err = fmt.Errorf("backup of engine %s, but %s configured", m.Engine, engine)
+		return
+	}
+	suffix := ".replaced-" + time.Now().UTC().Format(kBackupTimeLayout)
+
+	if engine == EngineMemory {
+		dir := memDBDir()
+		if len(dir) == 0 {
+			err = errors.New("DbPaths is not set in config")
+			return
+		}
+		if !force {
+			var paths []string
+			for _, b := range m.Dbs {
+				paths = append(paths, filepath.Join(dir, b.Name))
+			}
+			if err = checkNotExist(paths); err != nil {
+				return
+			}
+		}
+		if err = os.MkdirAll(dir, 0777); err != nil {
+			return
+		}
+		for _, b := range m.Dbs {
+			dst := filepath.Join(dir, b.Name)
+			if err = copyFile(filepath.Join(path, b.Name), dst+".restoring"); err != nil {
+				return
+			}
+			if err = replacePath(dst+".restoring", dst, suffix, force); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	if m.NumPrefixDbs != numPrefixDbs {
+		err = fmt.Errorf("backup of %d prefix dbs, but %d configured", m.NumPrefixDbs, numPrefixDbs)
+		return
+	}
+	if len(DBConfig.DbPaths) == 0 {
+		err = errors.New("DbPaths is not set in config")
+		return
+	}
+	withWalDir := !DBConfig.WriteDisableWAL && len(DBConfig.WalDir) != 0
+	dsts := make([]string, len(m.Dbs))
+	walDsts := make([]string, len(m.Dbs))
+	for i, b := range m.Dbs {
+		dsts[i] = filepath.Join(DBConfig.DbPaths[0].Path, b.Name)
+		if withWalDir && len(b.WalDir) != 0 {
+			walDsts[i] = filepath.Join(DBConfig.WalDir, b.WalDir)
+		}
+	}
+	if !force {
+		if err = checkNotExist(append(dsts, walDsts...)); err != nil {
+			return
+		}
+	}
+	for i, b := range m.Dbs {
+		if err = restoreDb(filepath.Join(path, b.Name), b.Files, dsts[i], walDsts[i], suffix, force); err != nil {
+			return
+		}
+		glog.Infof("%s restored", dsts[i])
+	}
+	return
+}
+
+// restoreDb copies the files of the checkpoint at src to dst, and the log
+// files to walDst if not empty.
+func restoreDb(src string, files []BackupFile, dst string, walDst string, suffix string, force bool) (err error) {
+	stagingDst := dst + ".restoring"
+	stagingWal := walDst + ".restoring"
+	if err = newDir(stagingDst); err != nil {
+		return
+	}
+	if len(walDst) != 0 {
+		if err = newDir(stagingWal); err != nil {
+			return
+		}
+	}
+	for _, f := range files {
+		target := filepath.Join(stagingDst, f.Name)
+		if len(walDst) != 0 && strings.HasSuffix(f.Name, ".log") {
+			target = filepath.Join(stagingWal, f.Name)
+		}
+		if err = copyFile(filepath.Join(src, f.Name), target); err != nil {
+			return
+		}
+	}
+	if err = replacePath(stagingDst, dst, suffix, force); err != nil {
+		return
+	}
+	if len(walDst) != 0 {
+		err = replacePath(stagingWal, walDst, suffix, force)
+	}
+	return
+}
+
+// newDir creates an empty directory, removing the existing one if any.
+func newDir(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	return os.MkdirAll(path, 0777)
+}
+
+// checkNotExist returns an error if any of paths exists.
+func checkNotExist(paths []string) error {
+	for _, path := range paths {
+		if len(path) == 0 {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func replacePath(src string, dst string, suffix string, force bool) (err error) {
+	if _, err = os.Stat(dst); err == nil {
+		if !force {
+			os.RemoveAll(src)
+			return fmt.Errorf("%s already exists", dst)
+		}
+		if err = os.Rename(dst, dst+suffix); err != nil {
+			return
+		}
+		glog.Infof("%s renamed to %s", dst, dst+suffix)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	return os.Rename(src, dst)
+}
@@ -0,0 +1,111 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build cgo
+// +build cgo
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"juno/third_party/forked/tecbot/gorocksdb"
+
+	"juno/pkg/shard"
+)
+
+type backupDbT struct {
+	name    string
+	walName string
+	db      *gorocksdb.DB
+	shards  []shard.ID
+}
+
+func (r *RocksDB) backup(dir string, incremental bool) (path string, m *BackupManifest, err error) {
+	if len(DBConfig.DbPaths) == 0 {
+		err = errors.New("DbPaths is not set in config")
+		return
+	}
+	m = newBackupManifest(r.zoneId, r.nodeId, r.shards)
+	m.Incremental = incremental
+	if p, ok := r.sharding.(*ShardingByPrefix); ok {
+		m.NumPrefixDbs = len(p.dbs)
+	}
+	path = filepath.Join(dir, m.name())
+	if err = os.MkdirAll(dir, 0777); err != nil {
+		return
+	}
+	if err = os.Mkdir(path, 0777); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(path)
+		}
+	}()
+
+	// The checkpoints, hard links to the files of the dbs, are all taken
+	// before copying, to be close in time.
+	staging := filepath.Join(DBConfig.DbPaths[0].Path, ".backup-"+m.name())
+	if err = os.MkdirAll(staging, 0777); err != nil {
+		return
+	}
+	defer os.RemoveAll(staging)
+
+	for _, b := range r.sharding.backupDbs(r.shards) {
+		if err = checkpoint(b.db, filepath.Join(staging, b.name)); err != nil {
+			err = fmt.Errorf("checkpoint of %s: %s", b.name, err)
+			return
+		}
+		m.Dbs = append(m.Dbs, BackupDb{
+			Name:           b.name,
+			WalDir:         b.walName,
+			Shards:         b.shards,
+			CheckpointTime: time.Now().UTC(),
+		})
+	}
+
+	for i := range m.Dbs {
+		b := &m.Dbs[i]
+		shared := ""
+		if incremental {
+			shared = filepath.Join(dir, kBackupSharedDir, b.Name)
+		}
+		if b.Files, err = copyCheckpoint(filepath.Join(staging, b.Name), filepath.Join(path, b.Name), shared); err != nil {
+			return
+		}
+	}
+	err = m.write(path)
+	return
+}
+
+func checkpoint(db *gorocksdb.DB, dir string) error {
+	cp, err := db.NewCheckpoint()
+	if err != nil {
+		return err
+	}
+	defer cp.Destroy()
+
+	// always flush the memtables, as the WAL may be disabled
+	return cp.CreateCheckpoint(dir, 0)
+}
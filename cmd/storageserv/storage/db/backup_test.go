@@ -0,0 +1,165 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"juno/pkg/shard"
+)
+
+func TestCopyCheckpoint(t *testing.T) {
+	src := t.TempDir()
+	dir := t.TempDir()
+	shared := filepath.Join(dir, kBackupSharedDir, "0-1-0.db")
+	for name, data := range map[string]string{
+		"000001.sst":      "sst1",
+		"000002.sst":      "sst2",
+		"CURRENT":         "MANIFEST-000003\n",
+		"MANIFEST-000003": "manifest",
+	} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, backup := range []string{"b1", "b2"} {
+		dst := filepath.Join(dir, backup, "0-1-0.db")
+		files, err := copyCheckpoint(src, dst, shared)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 4 {
+			t.Fatalf("%d files copied, expected 4", len(files))
+		}
+		for _, f := range files {
+			if f.Shared != (filepath.Ext(f.Name) == ".sst") {
+				t.Errorf("%s: shared=%v", f.Name, f.Shared)
+			}
+		}
+	}
+
+	// the sst files of both backups are the shared ones
+	st1, _ := os.Stat(filepath.Join(dir, "b1", "0-1-0.db", "000001.sst"))
+	st2, _ := os.Stat(filepath.Join(dir, "b2", "0-1-0.db", "000001.sst"))
+	if st1 == nil || st2 == nil || !os.SameFile(st1, st2) {
+		t.Error("sst file not shared")
+	}
+	if entries, _ := os.ReadDir(shared); len(entries) != 2 {
+		t.Errorf("%d shared files, expected 2", len(entries))
+	}
+
+	// same file number and size, different content
+	os.WriteFile(filepath.Join(src, "000001.sst"), []byte("SST1"), 0644)
+	if _, err := copyCheckpoint(src, filepath.Join(dir, "b3", "0-1-0.db"), shared); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "b3", "0-1-0.db", "000001.sst")); string(data) != "SST1" {
+		t.Errorf("got %q, expected the new content", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "b1", "0-1-0.db", "000001.sst")); string(data) != "sst1" {
+		t.Errorf("got %q, the previous backup should not be changed", data)
+	}
+	if entries, _ := os.ReadDir(shared); len(entries) != 3 {
+		t.Errorf("%d shared files, expected 3", len(entries))
+	}
+}
+
+func TestMemDBBackupRestore(t *testing.T) {
+	saved := DBConfig
+	defer func() { DBConfig = saved }()
+	DBConfig.WalDir = t.TempDir()
+	DBConfig.DbPaths = nil
+	DBConfig.Engine = EngineMemory
+
+	var buf bytes.Buffer
+	shards := shard.Map{1: struct{}{}, 2: struct{}{}}
+	m := newMemDB(0, 0, 0, 1, shards)
+	for _, id := range []shard.ID{1, 2} {
+		rec := &Record{RecordHeader: RecordHeader{ExpirationTime: uint32(time.Now().Unix() + 3600)}}
+		rec.Payload.SetWithClearValue([]byte("value"))
+		var v bytes.Buffer
+		rec.EncodeToBuffer(&v)
+		if err := m.Put(NewRecordIDWithBuffer(&buf, id, 0, []byte("ns"), []byte("key")), v.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, manifest, err := m.backup(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Dbs) != 2 || len(manifest.Shards) != 2 || manifest.Engine != EngineMemory {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	// overwrite shard 1 after the backup
+	m.Delete(NewRecordIDWithBuffer(&buf, 1, 0, []byte("ns"), []byte("key")))
+	m.Shutdown()
+
+	if _, err = Restore(path, 1, false); err == nil {
+		t.Error("restore over existing logs should fail without force")
+	}
+	restored, err := Restore(path, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ZoneId != 0 || restored.NodeId != 1 {
+		t.Errorf("unexpected manifest %+v", restored)
+	}
+
+	m = newMemDB(0, 0, 0, 1, shards)
+	defer m.Shutdown()
+	if rec, _ := m.Get(NewRecordIDWithBuffer(&buf, 1, 0, []byte("ns"), []byte("key")), false); rec == nil {
+		t.Error("record of shard 1 not restored")
+	}
+}
+
+func TestResolveBackupDir(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.Symlink(outside, filepath.Join(root, "link"))
+
+	tests := []struct {
+		dir string
+		ok  bool
+	}{
+		{"", true},
+		{"daily", true},
+		{filepath.Join(root, "a", "b"), true},
+		{"../x", false},
+		{"a/../../x", false},
+		{outside, false},
+		{"link/x", false},
+	}
+	for _, tc := range tests {
+		dir, err := resolveBackupDir(root, tc.dir)
+		if (err == nil) != tc.ok {
+			t.Errorf("%q: got %q %v", tc.dir, dir, err)
+		}
+	}
+	if _, err := resolveBackupDir("", root); err == nil {
+		t.Error("backup should be disabled without a backup dir")
+	}
+}
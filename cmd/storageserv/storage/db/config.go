@@ -27,7 +27,6 @@ import (
 	"os"
 
 	"juno/third_party/forked/golang/glog"
-	"juno/third_party/forked/tecbot/gorocksdb"
 )
 
 ///TODO need to add validation
@@ -150,14 +149,14 @@ type Config struct {
 	//  kLZ4HCCompression = 0x5,
 	//  kXpressCompression = 0x6,
 	//  kZSTD = 0x7
-	Compression gorocksdb.CompressionType
+	Compression CompressionType
 
 	//	DebugInfoLogLevel = InfoLogLevel(0)
 	//	InfoInfoLogLevel  = InfoLogLevel(1)
 	//	WarnInfoLogLevel  = InfoLogLevel(2)
 	//	ErrorInfoLogLevel = InfoLogLevel(3)
 	//	FatalInfoLogLevel = InfoLogLevel(4
-	InfoLogLevel gorocksdb.InfoLogLevel
+	InfoLogLevel InfoLogLevel
 
 	// write option
 	// rocksdb: bool sync (Default: false)
@@ -197,8 +196,31 @@ type Config struct {
 	DbPaths []DbPath
 
 	WalDir string
+
+	// Root of the backup directories. Online backups are disabled if not
+	// set, and can't be taken outside of it.
+	BackupDir string
+
+	// Index the records by expiration time, for the reaper to delete them
+	// once expired, ahead of compaction. Prefix sharding only.
+	ExpiryIndexEnabled bool
+
+	// Max number of expired records deleted per second by the reaper
+	// (Default: 1000)
+	ExpiryReaperRate int
+
+	// Storage engine, EngineRocksDB (Default) or EngineMemory.
+	// EngineMemory keeps the records in memory and persists them with a
+	// write ahead log per shard under WalDir, or DbPaths[0] if not set.
+	// WriteSync applies to the log.
+	Engine string
 }
 
+const (
+	EngineRocksDB = "rocksdb"
+	EngineMemory  = "memory"
+)
+
 type DbPath struct {
 	Path       string
 	TargetSize uint64
@@ -229,8 +251,8 @@ var defaultFlashConfig = Config{
 	KeepLogFileNum:                 2,
 	MaxBackgroundFlushes:           6,
 	MaxBackgroundCompactions:       10,
-	Compression:                    gorocksdb.NoCompression, //NoCompression,
-	InfoLogLevel:                   gorocksdb.InfoInfoLogLevel,
+	Compression:                    defaultCompression, //NoCompression,
+	InfoLogLevel:                   defaultInfoLogLevel,
 	RandomizeWriteBuffer:           true,
 	WriteSync:                      false,
 	WriteDisableWAL:                true,
@@ -238,107 +260,13 @@ var defaultFlashConfig = Config{
 	HighPriorityBackgroundThreads:  0,
 	LowPriorityBackgroundThreads:   0,
 	NewLRUCacheSizeInMB:            0,
+	ExpiryReaperRate:               1000,
 }
 
 var DBConfig = defaultFlashConfig
 
-//Note: rocksdb C binding does not support getters for option types
-func NewRocksDBptions() *gorocksdb.Options {
-	options := gorocksdb.NewDefaultOptions()
-
-	options.SetCreateIfMissing(true)
-	if DBConfig.RandomizeWriteBuffer {
-		if DBConfig.WriteBufferSize > 0 {
-			f := float32(DBConfig.WriteBufferSize)
-			sz := f*0.75 + rand.Float32()*f*0.25
-			if sz < 4096 {
-				sz = 4096
-			}
-			options.SetWriteBufferSize(int(sz))
-		}
-	} else {
-		if DBConfig.WriteBufferSize > 0 {
-			options.SetWriteBufferSize(DBConfig.WriteBufferSize)
-		}
-	}
-	if DBConfig.MaxWriteBufferNumber > 2 {
-		options.SetMaxWriteBufferNumber(DBConfig.MaxWriteBufferNumber)
-	}
-	if DBConfig.MinWriteBufferNumberToMerge > 1 { ///TODO
-		options.SetMinWriteBufferNumberToMerge(DBConfig.MinWriteBufferNumberToMerge)
-	}
-
-	if DBConfig.Level0FileNumCompactionTrigger != 0 {
-		options.SetLevel0FileNumCompactionTrigger(DBConfig.Level0FileNumCompactionTrigger)
-	}
-
-	if DBConfig.Level0SlowdownWritesTrigger != 0 {
-		options.SetLevel0SlowdownWritesTrigger(DBConfig.Level0SlowdownWritesTrigger)
-	}
-	if DBConfig.Level0StopWritesTrigger > 0 { ///TODO
-		options.SetLevel0StopWritesTrigger(DBConfig.Level0StopWritesTrigger)
-	}
-	if DBConfig.StatsDumpPeriodSec != 600 { ///TODO. to find out what if set it to zero
-		options.SetStatsDumpPeriodSec(DBConfig.StatsDumpPeriodSec)
-	}
-
-	if DBConfig.MaxBytesForLevelBase > 0 {
-		options.SetMaxBytesForLevelBase(DBConfig.MaxBytesForLevelBase)
-	}
-	if DBConfig.MaxBytesForLevelMultiplier > 0 {
-		options.SetMaxBytesForLevelMultiplier(DBConfig.MaxBytesForLevelMultiplier)
-	}
-	if DBConfig.TargetFileSizeBase > 0 {
-		options.SetTargetFileSizeBase(DBConfig.TargetFileSizeBase)
-	}
-	if DBConfig.TargetFileSizeMultiplier > 0 {
-		options.SetTargetFileSizeMultiplier(DBConfig.TargetFileSizeMultiplier)
-	}
-	if DBConfig.KeepLogFileNum > 0 { ///TODO
-		options.SetKeepLogFileNum(DBConfig.KeepLogFileNum)
-	}
-	if DBConfig.MaxBackgroundFlushes > 0 {
-		options.SetMaxBackgroundFlushes(DBConfig.MaxBackgroundFlushes)
-	}
-	if DBConfig.MaxBackgroundCompactions > 0 {
-		options.SetMaxBackgroundCompactions(DBConfig.MaxBackgroundCompactions)
-	}
-
-	if DBConfig.Compression == gorocksdb.NoCompression ||
-		DBConfig.Compression == gorocksdb.SnappyCompression ||
-		DBConfig.Compression == gorocksdb.ZLibCompression ||
-		DBConfig.Compression == gorocksdb.Bz2Compression ||
-		DBConfig.Compression == gorocksdb.LZ4Compression ||
-		DBConfig.Compression == gorocksdb.LZ4HCCompression {
-		options.SetCompression(DBConfig.Compression)
-	} else {
-		glog.Infof("unsupported compression type %v", DBConfig.Compression)
-	}
-
-	options.SetEnablePipelinedWrite(true)
-	if DBConfig.RateBytesPerSec > 0 {
-		rateLimiter := gorocksdb.NewRateLimiter(DBConfig.RateBytesPerSec, 100*1000, 10)
-		options.SetRateLimiter(rateLimiter)
-	}
-
-	env := gorocksdb.NewDefaultEnv()
-	if DBConfig.HighPriorityBackgroundThreads > 0 {
-		env.SetHighPriorityBackgroundThreads(DBConfig.HighPriorityBackgroundThreads)
-	}
-	if DBConfig.LowPriorityBackgroundThreads > 0 {
-		env.SetBackgroundThreads(DBConfig.LowPriorityBackgroundThreads)
-	}
-	options.SetEnv(env)
-
-	options.SetMaxBytesForLevelBase(uint64(DBConfig.WriteBufferSize) * uint64(DBConfig.MinWriteBufferNumberToMerge*DBConfig.Level0FileNumCompactionTrigger))
-	options.SetTargetFileSizeBase(DBConfig.TargetFileSizeBase)
-
-	return options
-}
-
 func (cfg *Config) OnLoad() {
-	writeOptions.SetSync(cfg.WriteSync)
-	writeOptions.DisableWAL(cfg.WriteDisableWAL)
+	setWriteOptions(cfg)
 
 	if !cfg.WriteDisableWAL && len(cfg.WalDir) > 0 {
 		if _, err := os.Stat(cfg.WalDir); errors.Is(err, fs.ErrNotExist) {
@@ -355,20 +283,6 @@ func init() {
 	rand.Seed(int64(os.Getpid()))
 }
 
-func ConfigBlockCache() *gorocksdb.BlockBasedTableOptions {
-	blockOpts := gorocksdb.NewDefaultBlockBasedTableOptions()
-	blockOpts.SetFilterPolicy(gorocksdb.NewBloomFilter(10))
-	if DBConfig.NewLRUCacheSizeInMB > 0 {
-		cache := gorocksdb.NewLRUCache(1024 * 1024 * DBConfig.NewLRUCacheSizeInMB)
-		blockOpts.SetBlockCache(cache)
-	}
-
-	msg := fmt.Sprintf("NewLRUCacheSizeInMB=%d ", DBConfig.NewLRUCacheSizeInMB)
-	glog.Info(msg)
-
-	return blockOpts
-}
-
 func (cfg *Config) Validate() (err error) {
 	if len(cfg.DbPaths) == 0 {
 		err = fmt.Errorf("db.Config error: DbPaths not defined")
@@ -0,0 +1,154 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build cgo
+// +build cgo
+
+package db
+
+import (
+	"fmt"
+	"math/rand"
+
+	"juno/third_party/forked/golang/glog"
+	"juno/third_party/forked/tecbot/gorocksdb"
+)
+
+type (
+	CompressionType = gorocksdb.CompressionType
+	InfoLogLevel    = gorocksdb.InfoLogLevel
+)
+
+const (
+	defaultCompression  = gorocksdb.NoCompression
+	defaultInfoLogLevel = gorocksdb.InfoInfoLogLevel
+)
+
+// Note: rocksdb C binding does not support getters for option types
+func NewRocksDBptions() *gorocksdb.Options {
+	options := gorocksdb.NewDefaultOptions()
+
+	options.SetCreateIfMissing(true)
+	if DBConfig.RandomizeWriteBuffer {
+		if DBConfig.WriteBufferSize > 0 {
+			f := float32(DBConfig.WriteBufferSize)
+			sz := f*0.75 + rand.Float32()*f*0.25
+			if sz < 4096 {
+				sz = 4096
+			}
+			options.SetWriteBufferSize(int(sz))
+		}
+	} else {
+		if DBConfig.WriteBufferSize > 0 {
+			options.SetWriteBufferSize(DBConfig.WriteBufferSize)
+		}
+	}
+	if DBConfig.MaxWriteBufferNumber > 2 {
+		options.SetMaxWriteBufferNumber(DBConfig.MaxWriteBufferNumber)
+	}
+	if DBConfig.MinWriteBufferNumberToMerge > 1 { ///TODO
+		options.SetMinWriteBufferNumberToMerge(DBConfig.MinWriteBufferNumberToMerge)
+	}
+
+	if DBConfig.Level0FileNumCompactionTrigger != 0 {
+		options.SetLevel0FileNumCompactionTrigger(DBConfig.Level0FileNumCompactionTrigger)
+	}
+
+	if DBConfig.Level0SlowdownWritesTrigger != 0 {
+		options.SetLevel0SlowdownWritesTrigger(DBConfig.Level0SlowdownWritesTrigger)
+	}
+	if DBConfig.Level0StopWritesTrigger > 0 { ///TODO
+		options.SetLevel0StopWritesTrigger(DBConfig.Level0StopWritesTrigger)
+	}
+	if DBConfig.StatsDumpPeriodSec != 600 { ///TODO. to find out what if set it to zero
+		options.SetStatsDumpPeriodSec(DBConfig.StatsDumpPeriodSec)
+	}
+
+	if DBConfig.MaxBytesForLevelBase > 0 {
+		options.SetMaxBytesForLevelBase(DBConfig.MaxBytesForLevelBase)
+	}
+	if DBConfig.MaxBytesForLevelMultiplier > 0 {
+		options.SetMaxBytesForLevelMultiplier(DBConfig.MaxBytesForLevelMultiplier)
+	}
+	if DBConfig.TargetFileSizeBase > 0 {
+		options.SetTargetFileSizeBase(DBConfig.TargetFileSizeBase)
+	}
+	if DBConfig.TargetFileSizeMultiplier > 0 {
+		options.SetTargetFileSizeMultiplier(DBConfig.TargetFileSizeMultiplier)
+	}
+	if DBConfig.KeepLogFileNum > 0 { ///TODO
+		options.SetKeepLogFileNum(DBConfig.KeepLogFileNum)
+	}
+	if DBConfig.MaxBackgroundFlushes > 0 {
+		options.SetMaxBackgroundFlushes(DBConfig.MaxBackgroundFlushes)
+	}
+	if DBConfig.MaxBackgroundCompactions > 0 {
+		options.SetMaxBackgroundCompactions(DBConfig.MaxBackgroundCompactions)
+	}
+
+	if DBConfig.Compression == gorocksdb.NoCompression ||
+		DBConfig.Compression == gorocksdb.SnappyCompression ||
+		DBConfig.Compression == gorocksdb.ZLibCompression ||
+		DBConfig.Compression == gorocksdb.Bz2Compression ||
+		DBConfig.Compression == gorocksdb.LZ4Compression ||
+		DBConfig.Compression == gorocksdb.LZ4HCCompression {
+		options.SetCompression(DBConfig.Compression)
+	} else {
+		glog.Infof("unsupported compression type %v", DBConfig.Compression)
+	}
+
+	options.SetEnablePipelinedWrite(true)
+	if DBConfig.RateBytesPerSec > 0 {
+		rateLimiter := gorocksdb.NewRateLimiter(DBConfig.RateBytesPerSec, 100*1000, 10)
+		options.SetRateLimiter(rateLimiter)
+	}
+
+	env := gorocksdb.NewDefaultEnv()
+	if DBConfig.HighPriorityBackgroundThreads > 0 {
+		env.SetHighPriorityBackgroundThreads(DBConfig.HighPriorityBackgroundThreads)
+	}
+	if DBConfig.LowPriorityBackgroundThreads > 0 {
+		env.SetBackgroundThreads(DBConfig.LowPriorityBackgroundThreads)
+	}
+	options.SetEnv(env)
+
+	options.SetMaxBytesForLevelBase(uint64(DBConfig.WriteBufferSize) * uint64(DBConfig.MinWriteBufferNumberToMerge*DBConfig.Level0FileNumCompactionTrigger))
+	options.SetTargetFileSizeBase(DBConfig.TargetFileSizeBase)
+
+	return options
+}
+
+func setWriteOptions(cfg *Config) {
+	writeOptions.SetSync(cfg.WriteSync)
+	writeOptions.DisableWAL(cfg.WriteDisableWAL)
+}
+
+func ConfigBlockCache() *gorocksdb.BlockBasedTableOptions {
+	blockOpts := gorocksdb.NewDefaultBlockBasedTableOptions()
+	blockOpts.SetFilterPolicy(gorocksdb.NewBloomFilter(10))
+	if DBConfig.NewLRUCacheSizeInMB > 0 {
+		cache := gorocksdb.NewLRUCache(1024 * 1024 * DBConfig.NewLRUCacheSizeInMB)
+		blockOpts.SetBlockCache(cache)
+	}
+
+	msg := fmt.Sprintf("NewLRUCacheSizeInMB=%d ", DBConfig.NewLRUCacheSizeInMB)
+	glog.Info(msg)
+
+	return blockOpts
+}
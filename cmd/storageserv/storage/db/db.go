@@ -20,10 +20,17 @@
 package db
 
 import (
+	"fmt"
 	"io"
+	"sync/atomic"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
 
 	"juno/cmd/storageserv/redist"
+	"juno/pkg/proto"
 	"juno/pkg/shard"
+	redistst "juno/pkg/stats/redist"
 )
 
 type IDatabase interface {
@@ -39,6 +46,8 @@ type IDatabase interface {
 	IsPresent(id RecordID) (bool, error, *Record)
 	IsRecordPresent(id RecordID, rec *Record) (bool, error)
 
+	Scan(shardId shard.ID, ns []byte, prefix []byte, startAfter []byte, limit int) ([]ScanRecord, error)
+
 	ReplicateSnapshot(shardId shard.ID, r *redist.Replicator, mshardid int32) bool
 	ShardSupported(shardId shard.ID) bool
 	UpdateRedistShards(shards shard.Map)
@@ -47,3 +56,170 @@ type IDatabase interface {
 	WriteProperty(propKey string, w io.Writer)
 	GetIntProperty(propKey string) uint64
 }
+
+type DBError struct {
+	err error
+}
+
+func (e *DBError) Error() string {
+	if e.err != nil {
+		return "DBError: " + e.err.Error()
+	}
+	return "DBErr: "
+}
+
+func NewDBError(e error) *DBError {
+	return &DBError{err: e}
+}
+
+var rocksdbIndex int32 = 0
+var rocksdb [2]IDatabase
+
+func GetDB() IDatabase {
+	var index int32 = atomic.LoadInt32(&rocksdbIndex)
+	return rocksdb[index]
+}
+
+// ICompactable is implemented by the storage engines able to drop the expired
+// and purged records on demand.
+type ICompactable interface {
+	CompactAll(onDone func(i int, total int)) error
+	NumDbs() int
+}
+
+// GetCompactable returns nil if not supported by the current storage engine.
+func GetCompactable() ICompactable {
+	if d, ok := GetDB().(interface{ compactable() ICompactable }); ok {
+		return d.compactable()
+	}
+	return nil
+}
+
+// only called once during start up
+func Initialize(
+	numShards int, numMicroShards int, numMicroShardGroups int,
+	numPrefixDbs int, zoneId int, nodeId int, shardMap shard.Map, lruCacheSizeInMB int) {
+	if numMicroShards > 0 {
+		SetEnableMircoShardId(true)
+		glog.Infof("Enable micro shards, NumMicroShards=%d, numMshardGroups=%d", numMicroShards, numMicroShardGroups)
+	}
+
+	if DBConfig.NewLRUCacheSizeInMB == 0 && lruCacheSizeInMB > 0 { // Use computed value
+		DBConfig.NewLRUCacheSizeInMB = lruCacheSizeInMB
+	}
+	var db IDatabase
+	switch DBConfig.Engine {
+	case "", EngineRocksDB:
+		db = newRocksDB(numShards, numMicroShards, numMicroShardGroups, numPrefixDbs, zoneId, nodeId, shardMap)
+	case EngineMemory:
+		db = newMemDB(numMicroShards, numMicroShardGroups, zoneId, nodeId, shardMap)
+	default:
+		glog.Exitf("unsupported DB.Engine %q", DBConfig.Engine)
+	}
+	rocksdb[rocksdbIndex] = db
+	// safe guard?
+	rocksdb[(rocksdbIndex+1)%2] = db
+}
+
+func Finalize() {
+	GetDB().Shutdown()
+}
+
+func sendRedistRep(shardId shard.ID, ns []byte, key []byte, rec *Record, rb *redist.Replicator) (err error) {
+
+	var rowMsg proto.RawMessage
+	rec.EncodeRedistMsg(shardId, ns, key, &rowMsg)
+
+	maxtry := redist.RedistConfig.MaxWaitTime * 1000 / 20
+
+	for i := 0; i < maxtry; i++ {
+		err := rb.SendRequest(&rowMsg, false, false)
+		if err == nil {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// one last try
+	return rb.SendRequest(&rowMsg, false, true)
+}
+
+type ShardingBase struct {
+}
+
+func (s *ShardingBase) waitForFinish(rb *redist.Replicator) bool {
+	if rb.IsSnapShotDone() {
+		return true
+	}
+
+	maxwait := redist.RedistConfig.MaxWaitTime * 1000 / 10
+
+	// wait till the requests are all processed or max wait time reached
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	ts_passed := 0
+	for {
+		select {
+		case <-ticker.C:
+			if rb.IsSnapShotDone() {
+				return true
+			}
+
+			ts_passed++
+			if ts_passed > maxwait {
+				return false
+			}
+		}
+	}
+}
+
+type MicroShardGroupStats struct {
+	start_id   uint8
+	end_id     uint8
+	cnt_keys   uint32
+	cnt_exp    uint32
+	cnt_err    uint32
+	start_time time.Time
+	mshards    string
+	lastgrp    bool
+}
+
+func (m *MicroShardGroupStats) reset(numMicroShards int, numMShardsPerGroup int, curGroupNum int) {
+
+	m.lastgrp = true
+	if numMicroShards > 0 {
+		m.start_id = uint8(curGroupNum * numMShardsPerGroup)
+		m.end_id = uint8(int(m.start_id) + numMShardsPerGroup - 1)
+
+		if numMicroShards-1-int(m.end_id) < numMShardsPerGroup { // last group, may have extras
+			m.end_id = uint8(numMicroShards - 1)
+			m.lastgrp = true
+		} else {
+			m.lastgrp = false
+		}
+
+		m.mshards = fmt.Sprintf(", micro shards (%d-%d) ", m.start_id, m.end_id)
+	}
+	m.cnt_keys = 0
+	m.cnt_exp = 0
+	m.cnt_err = 0
+	m.start_time = time.Now()
+	//glog.Infof("reset group: %d, %d, %d, %d, %d\n", m.start_id, m.end_id, curGroupNum, numMicroShards, numMShardsPerGroup)
+}
+
+func (m *MicroShardGroupStats) logStats(shardId shard.ID, rb *redist.Replicator) bool {
+	elapsed := time.Since(m.start_time)
+	glog.Infof("total %d records forwarded from shard %d%s in %s, excluding expired_cnt=%d, decode_err_cnt=%d",
+		m.cnt_keys, shardId, m.mshards, elapsed, m.cnt_exp, m.cnt_err)
+
+	rediststat := rb.GetSnapshotStats()
+	rediststat.SetMShardId(int32(m.end_id))
+	if m.lastgrp {
+		rediststat.SetStatus(redistst.StatsFinish)
+	} else {
+		rediststat.SetStatus(redistst.StatsInProgress)
+	}
+	return rb.LogStats(m.start_time, true, false)
+
+}
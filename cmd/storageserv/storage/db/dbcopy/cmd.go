@@ -17,6 +17,9 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 package main
 
 import (
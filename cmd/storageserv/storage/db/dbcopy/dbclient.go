@@ -17,6 +17,9 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 package main
 
 import (
@@ -60,7 +63,7 @@ func NewDbInstance(dbpath string, prefixLen int, compact bool) (instance *gorock
 		opts.SetCompactionFilter(&CompactionFilter{})
 	}
 
-	instance, err = gorocksdb.OpenDb(opts, dbpath)
+	instance, err = db.OpenDb(opts, dbpath)
 	if err != nil {
 		glog.Errorf("[ERROR] dbpath=%s, Open failed: %s", dbpath, err)
 		return nil, err
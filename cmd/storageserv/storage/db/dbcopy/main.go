@@ -17,6 +17,9 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 // main.go
 package main
 
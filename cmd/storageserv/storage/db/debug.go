@@ -17,8 +17,8 @@
 //  limitations under the License.
 //
 
-//go:build debug
-// +build debug
+//go:build debug && cgo
+// +build debug,cgo
 
 package db
 
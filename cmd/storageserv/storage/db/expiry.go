@@ -0,0 +1,222 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+)
+
+// Expiry index of a prefix db, in its column family named expiry. Its keys,
+// ordered by expiration time, are
+//
+//	Offset | Field            | Size
+//	-------+------------------+---------
+//	     0 | expiration time  | 4 bytes
+//	     4 | record key       | ...
+//
+// with empty values. An entry is written with each Put, in the same write
+// batch as the record. The entries of updated or deleted records are left,
+// and dropped by the reaper when it reaches them, after checking the
+// expiration time of the record.
+const (
+	kExpiryColumnFamily = "expiry"
+
+	kSzExpiryTime       = 4
+	kNumExpiryLocks     = 1024
+	kExpiryReapInterval = time.Second
+
+	// The reaper resumes from the expiration time it reached, to skip the
+	// deleted entries, and rescans the index from the start every
+	// kExpiryRescanPasses passes, for the records put already expired.
+	kExpiryRescanPasses = 60
+)
+
+type (
+	// kvStoreI is what the reaper uses of a rocksdb instance, or of a column
+	// family.
+	kvStoreI interface {
+		get(key []byte) ([]byte, error)
+		delete(key []byte) error
+		// scan calls fn with the keys from start, in order, until it
+		// returns false
+		scan(start []byte, fn func(key []byte) bool) error
+	}
+
+	expiryIndexT struct {
+		db kvStoreI
+
+		// serialize the Puts with the reaper deleting the same record
+		locks [kNumExpiryLocks]sync.Mutex
+
+		// used by the reaper only
+		start     []byte
+		numPasses int
+	}
+
+	ExpiryReaperStats struct {
+		ReapedKeys  uint64
+		ReapedBytes uint64
+		Lag         time.Duration // expiration age of the oldest expired record not reaped yet
+	}
+)
+
+var (
+	expiryReapedKeys  uint64
+	expiryReapedBytes uint64
+	expiryReaperLag   int64
+)
+
+func expiryIndexKey(expirationTime uint32, key []byte) []byte {
+	ikey := make([]byte, kSzExpiryTime+len(key))
+	binary.BigEndian.PutUint32(ikey, expirationTime)
+	copy(ikey[kSzExpiryTime:], key)
+	return ikey
+}
+
+func decodeExpiryIndexKey(ikey []byte) (expirationTime uint32, key []byte, err error) {
+	if len(ikey) <= kSzExpiryTime {
+		err = fmt.Errorf("invalid expiry index key %X", ikey)
+		return
+	}
+	expirationTime = binary.BigEndian.Uint32(ikey)
+	key = ikey[kSzExpiryTime:]
+	return
+}
+
+// valueExpirationTime returns the expiration time of an encoded record.
+func valueExpirationTime(value []byte) (expirationTime uint32, ok bool) {
+	if len(value) < kOffExpirationTime+kSzExpirationTime {
+		return
+	}
+	return binary.BigEndian.Uint32(value[kOffExpirationTime : kOffExpirationTime+kSzExpirationTime]), true
+}
+
+func (x *expiryIndexT) lock(key []byte) *sync.Mutex {
+	return &x.locks[RecordID(key).Key()%kNumExpiryLocks]
+}
+
+// put writes the record with write, holding the lock of its key, not to be
+// deleted by the reaper in between. write is passed the index key of the
+// record, nil if it has no expiration time, to write it with the record.
+func (x *expiryIndexT) put(key []byte, value []byte, write func(ikey []byte) error) error {
+	var ikey []byte
+	if expirationTime, ok := valueExpirationTime(value); ok && expirationTime != 0 {
+		ikey = expiryIndexKey(expirationTime, key)
+	}
+	mtx := x.lock(key)
+	mtx.Lock()
+	defer mtx.Unlock()
+	return write(ikey)
+}
+
+// reap deletes up to limit records of db expired before now, in the order of
+// their expiration, and returns the number of index entries processed, and
+// the expiration time of the oldest expired one left, if any.
+func (x *expiryIndexT) reap(db kvStoreI, now int64, limit int) (n int, oldest uint32) {
+	var start []byte
+	if x.numPasses%kExpiryRescanPasses != 0 {
+		start = x.start
+	}
+	x.numPasses++
+
+	err := x.db.scan(start, func(ikey []byte) bool {
+		expirationTime, key, err := decodeExpiryIndexKey(ikey)
+		if err != nil {
+			glog.Warning(err)
+		} else if int64(expirationTime) >= now {
+			return false
+		} else if n >= limit {
+			oldest = expirationTime
+			return false
+		} else {
+			x.reapRecord(db, key, now)
+		}
+		if err = x.db.delete(ikey); err != nil {
+			glog.Warningf("failed to delete expiry index entry %X: %s", ikey, err)
+			return false
+		}
+		if len(ikey) >= kSzExpiryTime {
+			x.start = ikey[:kSzExpiryTime]
+		}
+		n++
+		return true
+	})
+	if err != nil {
+		glog.Warningf("failed to scan the expiry index: %s", err)
+	}
+	return
+}
+
+func (x *expiryIndexT) reapRecord(db kvStoreI, key []byte, now int64) {
+	mtx := x.lock(key)
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	value, err := db.get(key)
+	if err != nil {
+		glog.Warningf("reaper failed to get %X: %s", key, err)
+		return
+	}
+	if value == nil {
+		return
+	}
+	// as the compaction filter
+	if expirationTime, ok := valueExpirationTime(value); ok && int64(expirationTime) >= now {
+		return
+	}
+	if err = db.delete(key); err != nil {
+		glog.Warningf("reaper failed to delete %X: %s", key, err)
+		return
+	}
+	atomic.AddUint64(&expiryReapedKeys, 1)
+	atomic.AddUint64(&expiryReapedBytes, uint64(len(key)+len(value)))
+}
+
+// reapIndexes runs a reaper pass on each index of its db, and returns the
+// expiration age of the oldest expired record left.
+func reapIndexes(indexes []*expiryIndexT, dbs []kvStoreI, now int64, limit int) (lag int64) {
+	var oldest uint32
+	for i, x := range indexes {
+		if x == nil || dbs[i] == nil {
+			continue
+		}
+		if _, o := x.reap(dbs[i], now, limit); o != 0 && (oldest == 0 || o < oldest) {
+			oldest = o
+		}
+	}
+	if oldest != 0 {
+		lag = now - int64(oldest)
+	}
+	return
+}
+
+func GetExpiryReaperStats() ExpiryReaperStats {
+	return ExpiryReaperStats{
+		ReapedKeys:  atomic.LoadUint64(&expiryReapedKeys),
+		ReapedBytes: atomic.LoadUint64(&expiryReapedBytes),
+		Lag:         time.Duration(atomic.LoadInt64(&expiryReaperLag)) * time.Second,
+	}
+}
@@ -0,0 +1,220 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build cgo
+// +build cgo
+
+package db
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"juno/third_party/forked/tecbot/gorocksdb"
+)
+
+// rocksKVStoreT is the kvStoreI of a rocksdb instance, or of its column
+// family cf if not nil.
+type rocksKVStoreT struct {
+	db *gorocksdb.DB
+	cf *gorocksdb.ColumnFamilyHandle
+}
+
+var (
+	expiryReaperOnce sync.Once
+
+	// held by a reaper pass
+	expiryReaperMtx     sync.Mutex
+	expiryReaperStopped bool
+)
+
+func (s rocksKVStoreT) get(key []byte) (data []byte, err error) {
+	var value *gorocksdb.Slice
+	if s.cf != nil {
+		value, err = s.db.GetCF(readOptions, s.cf, key)
+	} else {
+		value, err = s.db.Get(readOptions, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer value.Free()
+	if value.Data() == nil {
+		return nil, nil
+	}
+	return append([]byte(nil), value.Data()...), nil
+}
+
+func (s rocksKVStoreT) delete(key []byte) error {
+	if s.cf != nil {
+		return s.db.DeleteCF(writeOptions, s.cf, key)
+	}
+	return s.db.Delete(writeOptions, key)
+}
+
+func (s rocksKVStoreT) scan(start []byte, fn func(key []byte) bool) error {
+	var iter *gorocksdb.Iterator
+	if s.cf != nil {
+		iter = s.db.NewIteratorCF(readOptions, s.cf)
+	} else {
+		iter = s.db.NewIterator(readOptions)
+	}
+	defer iter.Close()
+	if start == nil {
+		iter.SeekToFirst()
+	} else {
+		iter.Seek(start)
+	}
+	for ; iter.Valid(); iter.Next() {
+		if !fn(append([]byte(nil), iter.Key().Data()...)) {
+			break
+		}
+	}
+	return iter.Err()
+}
+
+// OpenDb opens the rocksdb instance name, with its column families other
+// than the default one, if any, left unused. A prefix db has the one of its
+// expiry index, opened with options of its own, for the compaction filter
+// of the records not to be applied to it.
+func OpenDb(options *gorocksdb.Options, name string) (*gorocksdb.DB, error) {
+	cfNames, err := gorocksdb.ListColumnFamilies(options, name)
+	if err != nil || len(cfNames) <= 1 {
+		// created if missing
+		return gorocksdb.OpenDb(options, name)
+	}
+	db, cfs, err := openDbColumnFamilies(options, name, cfNames)
+	if err != nil {
+		return nil, err
+	}
+	for _, cf := range cfs {
+		cf.Destroy()
+	}
+	return db, nil
+}
+
+// openDbWithExpiryIndex opens the prefix db name, with the column family of
+// its expiry index, created if missing.
+func openDbWithExpiryIndex(options *gorocksdb.Options, name string) (*gorocksdb.DB, *expiryIndexT, error) {
+	options.SetCreateIfMissingColumnFamilies(true)
+	cfNames, err := gorocksdb.ListColumnFamilies(options, name)
+	if err != nil { // a new db
+		cfNames = []string{"default"}
+	}
+	found := false
+	for _, cfName := range cfNames {
+		found = found || cfName == kExpiryColumnFamily
+	}
+	if !found {
+		cfNames = append(cfNames, kExpiryColumnFamily)
+	}
+	db, cfs, err := openDbColumnFamilies(options, name, cfNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	var index *expiryIndexT
+	for i, cf := range cfs {
+		if cfNames[i] == kExpiryColumnFamily {
+			index = &expiryIndexT{db: rocksKVStoreT{db: db, cf: cf}}
+		} else {
+			cf.Destroy()
+		}
+	}
+	return db, index, nil
+}
+
+func openDbColumnFamilies(options *gorocksdb.Options, name string, cfNames []string) (*gorocksdb.DB, gorocksdb.ColumnFamilyHandles, error) {
+	cfOptions := make([]*gorocksdb.Options, len(cfNames))
+	for i, cfName := range cfNames {
+		if cfName == "default" {
+			cfOptions[i] = options
+		} else {
+			cfOptions[i] = NewRocksDBptions()
+		}
+	}
+	return gorocksdb.OpenDbColumnFamilies(options, name, cfNames, cfOptions)
+}
+
+// putWithIndex writes the record, and its index entry ikey if not nil, in
+// one write batch.
+func (x *expiryIndexT) putWithIndex(db *gorocksdb.DB, key []byte, value []byte, ikey []byte) error {
+	s, ok := x.db.(rocksKVStoreT)
+	if ikey == nil || !ok {
+		return db.Put(writeOptions, key, value)
+	}
+	batch := gorocksdb.NewWriteBatch()
+	defer batch.Destroy()
+	batch.Put(key, value)
+	batch.PutCF(s.cf, ikey, nil)
+	return db.Write(writeOptions, batch)
+}
+
+// close releases the column family of the index, before its db is closed.
+func (x *expiryIndexT) close() {
+	if s, ok := x.db.(rocksKVStoreT); ok && s.cf != nil {
+		s.cf.Destroy()
+	}
+}
+
+// startExpiryReaper starts the reaper of the expiry indexes of the current
+// db, once.
+func startExpiryReaper() {
+	expiryReaperOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(kExpiryReapInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				reapExpired()
+			}
+		}()
+	})
+}
+
+// stopExpiryReaper waits for the current reaper pass, if any, to complete,
+// before the dbs are closed.
+func stopExpiryReaper() {
+	expiryReaperMtx.Lock()
+	expiryReaperStopped = true
+	expiryReaperMtx.Unlock()
+}
+
+func reapExpired() {
+	expiryReaperMtx.Lock()
+	defer expiryReaperMtx.Unlock()
+	if expiryReaperStopped {
+		return
+	}
+
+	d := GetPrefixDB()
+	if d == nil || len(d.expiryIndexes) == 0 {
+		return
+	}
+	limit := DBConfig.ExpiryReaperRate * int(kExpiryReapInterval/time.Second) / len(d.expiryIndexes)
+	if limit <= 0 {
+		limit = 1
+	}
+	dbs := make([]kvStoreI, len(d.dbs))
+	for i, db := range d.dbs {
+		if db != nil {
+			dbs[i] = rocksKVStoreT{db: db}
+		}
+	}
+	atomic.StoreInt64(&expiryReaperLag, reapIndexes(d.expiryIndexes, dbs, time.Now().Unix(), limit))
+}
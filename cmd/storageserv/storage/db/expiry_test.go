@@ -0,0 +1,286 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memKVStoreT is a kvStoreI in memory. onGet, if set, is called by get.
+type memKVStoreT struct {
+	mtx   sync.Mutex
+	kvs   map[string][]byte
+	onGet func(key []byte)
+}
+
+func newMemKVStore() *memKVStoreT {
+	return &memKVStoreT{kvs: make(map[string][]byte)}
+}
+
+func (s *memKVStoreT) get(key []byte) ([]byte, error) {
+	if s.onGet != nil {
+		s.onGet(key)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.kvs[string(key)], nil
+}
+
+func (s *memKVStoreT) put(key []byte, value []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.kvs[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *memKVStoreT) delete(key []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.kvs, string(key))
+	return nil
+}
+
+func (s *memKVStoreT) scan(start []byte, fn func(key []byte) bool) error {
+	s.mtx.Lock()
+	var keys []string
+	for k := range s.kvs {
+		if k >= string(start) {
+			keys = append(keys, k)
+		}
+	}
+	s.mtx.Unlock()
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !fn([]byte(k)) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memKVStoreT) has(key []byte) bool {
+	v, _ := s.get(key)
+	return v != nil
+}
+
+func expiringValue(t *testing.T, expirationTime uint32) []byte {
+	rec := &Record{RecordHeader: RecordHeader{ExpirationTime: expirationTime}}
+	rec.Payload.SetWithClearValue([]byte("value"))
+	var value bytes.Buffer
+	if err := rec.EncodeToBuffer(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value.Bytes()
+}
+
+// putExpiring puts the record and indexes it, as RocksDB.Put.
+func putExpiring(t *testing.T, x *expiryIndexT, db *memKVStoreT, key []byte, expirationTime uint32) {
+	value := expiringValue(t, expirationTime)
+	err := x.put(key, value, func(ikey []byte) error {
+		if ikey != nil {
+			x.db.(*memKVStoreT).put(ikey, nil)
+		}
+		return db.put(key, value)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testRecordKey(name string) []byte {
+	var buf bytes.Buffer
+	return NewRecordIDWithBuffer(&buf, 1, 0, []byte("ns"), []byte(name))
+}
+
+func TestExpiryIndexKey(t *testing.T) {
+	var buf bytes.Buffer
+	id := NewRecordIDWithBuffer(&buf, 3, 0, []byte("ns"), []byte("key"))
+
+	k1 := expiryIndexKey(100, id)
+	k2 := expiryIndexKey(200, []byte{0})
+	if bytes.Compare(k1, k2) >= 0 {
+		t.Error("index keys should be ordered by expiration time first")
+	}
+	exp, key, err := decodeExpiryIndexKey(k1)
+	if err != nil || exp != 100 || !bytes.Equal(key, id) {
+		t.Errorf("decode: %d %X %v", exp, key, err)
+	}
+	if _, _, err = decodeExpiryIndexKey(k1[:kSzExpiryTime]); err == nil {
+		t.Error("key without record key should be invalid")
+	}
+}
+
+func TestValueExpirationTime(t *testing.T) {
+	expiration := uint32(time.Now().Unix() + 60)
+	rec := &Record{RecordHeader: RecordHeader{ExpirationTime: expiration}}
+	rec.Payload.SetWithClearValue([]byte("value"))
+	var value bytes.Buffer
+	if err := rec.EncodeToBuffer(&value); err != nil {
+		t.Fatal(err)
+	}
+	if exp, ok := valueExpirationTime(value.Bytes()); !ok || exp != expiration {
+		t.Errorf("expiration time %d, expected %d", exp, expiration)
+	}
+	if _, ok := valueExpirationTime(value.Bytes()[:kOffExpirationTime]); ok {
+		t.Error("truncated value should not have an expiration time")
+	}
+}
+
+func TestExpiryReap(t *testing.T) {
+	db := newMemKVStore()
+	x := &expiryIndexT{db: newMemKVStore()}
+	k1, k2, k3, k4, k5 := testRecordKey("k1"), testRecordKey("k2"), testRecordKey("k3"), testRecordKey("k4"), testRecordKey("k5")
+	putExpiring(t, x, db, k1, 900)
+	putExpiring(t, x, db, k2, 950)
+	putExpiring(t, x, db, k3, 980)
+	putExpiring(t, x, db, k4, 2000)
+	// put again with a later expiration, the entry of 950 is left
+	putExpiring(t, x, db, k2, 3000)
+
+	reaped := atomic.LoadUint64(&expiryReapedKeys)
+	// k1 reaped, k2 skipped, the limit reached at k3
+	n, oldest := x.reap(db, 1000, 2)
+	if n != 2 || oldest != 980 {
+		t.Errorf("reaped %d, oldest %d, expected 2 and 980", n, oldest)
+	}
+	if db.has(k1) || !db.has(k2) || !db.has(k3) {
+		t.Errorf("k1 %v k2 %v k3 %v, only k1 should be deleted", db.has(k1), db.has(k2), db.has(k3))
+	}
+	if x.db.(*memKVStoreT).has(expiryIndexKey(950, k2)) {
+		t.Error("the entry of the previous expiration of k2 should be deleted")
+	}
+	if binary.BigEndian.Uint32(x.start) != 950 {
+		t.Errorf("resume from %d, expected 950", binary.BigEndian.Uint32(x.start))
+	}
+
+	// resumes from 950, k4 and k2 not expired yet
+	if n, oldest = x.reap(db, 1000, 10); n != 1 || oldest != 0 || db.has(k3) {
+		t.Errorf("reaped %d, oldest %d, k3 %v", n, oldest, db.has(k3))
+	}
+	if d := atomic.LoadUint64(&expiryReapedKeys) - reaped; d != 2 {
+		t.Errorf("%d keys reaped, expected 2", d)
+	}
+
+	// put already expired, before the resume point, found by the rescan only
+	putExpiring(t, x, db, k5, 100)
+	x.numPasses = kExpiryRescanPasses - 1
+	if x.reap(db, 1000, 10); !db.has(k5) {
+		t.Error("k5 should not be reached before the rescan")
+	}
+	if x.reap(db, 1000, 10); db.has(k5) {
+		t.Error("k5 should be reaped by the rescan")
+	}
+	if !db.has(k2) || !db.has(k4) {
+		t.Error("records not expired should be kept")
+	}
+}
+
+func TestExpiryReapLag(t *testing.T) {
+	var dbs []kvStoreI
+	var indexes []*expiryIndexT
+	for _, exp := range []uint32{900, 800} {
+		db := newMemKVStore()
+		x := &expiryIndexT{db: newMemKVStore()}
+		putExpiring(t, x, db, testRecordKey("a"), exp)
+		putExpiring(t, x, db, testRecordKey("b"), exp)
+		dbs = append(dbs, db)
+		indexes = append(indexes, x)
+	}
+	// no db for the last index
+	indexes = append(indexes, &expiryIndexT{db: newMemKVStore()})
+	dbs = append(dbs, nil)
+
+	// the oldest expired record left is b of 800
+	if lag := reapIndexes(indexes, dbs, 1000, 1); lag != 200 {
+		t.Errorf("lag %d, expected 200", lag)
+	}
+	if lag := reapIndexes(indexes, dbs, 1000, 1); lag != 0 {
+		t.Errorf("lag %d, expected 0", lag)
+	}
+}
+
+func TestExpiryReapPutLock(t *testing.T) {
+	db := newMemKVStore()
+	x := &expiryIndexT{db: newMemKVStore()}
+	key := testRecordKey("k")
+	putExpiring(t, x, db, key, 900)
+
+	inGet := make(chan struct{})
+	proceed := make(chan struct{})
+	var once sync.Once
+	db.onGet = func([]byte) {
+		once.Do(func() {
+			close(inGet)
+			<-proceed
+		})
+	}
+	reaped := make(chan struct{})
+	go func() {
+		x.reapRecord(db, key, 1000)
+		close(reaped)
+	}()
+	<-inGet
+
+	// the record is put again while the reaper has it expired
+	put := make(chan struct{})
+	go func() {
+		putExpiring(t, x, db, key, 3000)
+		close(put)
+	}()
+	select {
+	case <-put:
+		t.Fatal("Put should wait for the reaper")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(proceed)
+	<-reaped
+	<-put
+
+	value, _ := db.get(key)
+	if exp, ok := valueExpirationTime(value); !ok || exp != 3000 {
+		t.Errorf("the record put should be kept, got expiration %d", exp)
+	}
+}
+
+func TestExpiryPutIndexKey(t *testing.T) {
+	x := &expiryIndexT{db: newMemKVStore()}
+	key := testRecordKey("k")
+	for _, exp := range []uint32{0, 900} {
+		var ikey []byte
+		err := x.put(key, expiringValue(t, exp), func(k []byte) error {
+			ikey = k
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp == 0 && ikey != nil {
+			t.Errorf("record without expiration indexed as %X", ikey)
+		} else if exp != 0 && !bytes.Equal(ikey, expiryIndexKey(exp, key)) {
+			t.Errorf("index key %X, expected %X", ikey, expiryIndexKey(exp, key))
+		}
+	}
+}
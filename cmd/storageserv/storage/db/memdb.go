@@ -0,0 +1,714 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/cmd/storageserv/redist"
+	"juno/pkg/shard"
+	redistst "juno/pkg/stats/redist"
+	"juno/pkg/util"
+)
+
+var _ IDatabase = (*MemDB)(nil)
+
+const (
+	kMemDBSweepInterval = time.Minute
+
+	// the log of a shard is rewritten once larger than twice the size of its
+	// records plus kMinWalRewriteSize
+	kMinWalRewriteSize = 64 << 20
+
+	// number of expired records deleted by a sweep at a time
+	kMemDBSweepBatchSize = 1000
+)
+
+type (
+	memShardT struct {
+		sync.RWMutex
+		list *skipListT
+		wal  *walT
+	}
+
+	// MemDB is the pure Go storage engine. The records of a shard are kept in
+	// memory, in key order, with the keys of ShardingByPrefix, and persisted
+	// with a write ahead log of the shard, replayed at start up.
+	//
+	// Expired and purged records are dropped every kMemDBSweepInterval, as by
+	// the compaction filter of RocksDB.
+	MemDB struct {
+		ShardingBase
+		zoneId              int
+		nodeId              int
+		numMicroShards      int
+		numMicroShardGroups int
+		dir                 string
+
+		mtx          sync.RWMutex
+		shards       shard.Map // from current shard map
+		redistShards shard.Map // from redistribution, temporary/not commited
+		dbs          map[shard.ID]*memShardT
+
+		chDone chan struct{}
+	}
+
+	memCursorT struct {
+		node   *skipListNodeT
+		prefix []byte
+		offset int
+	}
+)
+
+// errMemShardClosed is returned by the requests racing with the removal of
+// their shard, or the shutdown.
+var errMemShardClosed = errors.New("shard closed")
+
+// memDBDir returns the directory of the logs, WalDir if set, or the first
+// of DbPaths.
+func memDBDir() string {
+	if len(DBConfig.WalDir) != 0 {
+		return DBConfig.WalDir
+	}
+	if len(DBConfig.DbPaths) == 0 {
+		return ""
+	}
+	return DBConfig.DbPaths[0].Path
+}
+
+func memWalName(zoneId int, nodeId int, shardId shard.ID) string {
+	return fmt.Sprintf("%d-%d-%d.wal", zoneId, nodeId, shardId)
+}
+
+func newMemDB(numMicroShards int, numMicroShardGroups int, zoneId int, nodeId int, shardMap shard.Map) *MemDB {
+	db := &MemDB{
+		zoneId:              zoneId,
+		nodeId:              nodeId,
+		numMicroShards:      numMicroShards,
+		numMicroShardGroups: numMicroShardGroups,
+		shards:              shardMap,
+		dbs:                 make(map[shard.ID]*memShardT),
+		chDone:              make(chan struct{}),
+	}
+	db.Setup()
+	return db
+}
+
+// initial set up
+func (m *MemDB) Setup() {
+	if m.dir = memDBDir(); len(m.dir) == 0 {
+		glog.Exit("Error: DbPaths is not set in config.")
+	}
+	if _, err := os.Stat(m.dir); errors.Is(err, fs.ErrNotExist) {
+		if err = os.MkdirAll(m.dir, 0777); err != nil {
+			glog.Exit("Error : ", err.Error())
+		}
+	}
+
+	m.mtx.Lock()
+	m.openShards(m.shards)
+	m.mtx.Unlock()
+
+	go m.sweepLoop()
+}
+
+// Caller must hold m.mtx.
+func (m *MemDB) openShards(shards shard.Map) {
+	for id := range shards {
+		if _, ok := m.dbs[id]; ok {
+			continue
+		}
+		s := &memShardT{list: newSkipList()}
+		path := filepath.Join(m.dir, memWalName(m.zoneId, m.nodeId, id))
+		start := time.Now()
+		var err error
+		s.wal, err = openWal(path, DBConfig.WriteSync, func(op byte, key []byte, value []byte) {
+			if op == kWalOpPut {
+				s.list.put(key, value)
+			} else {
+				s.list.delete(key)
+			}
+		})
+		if err != nil {
+			glog.Exitf("failed to open %s err: %s", path, err)
+		}
+		glog.Infof("%s opened with %d records in %s", path, s.list.count, time.Since(start))
+		m.dbs[id] = s
+	}
+}
+
+func (m *MemDB) getShard(id RecordID) (s *memShardT, err error) {
+	shardId := id.GetShardID()
+	m.mtx.RLock()
+	s = m.dbs[shardId]
+	m.mtx.RUnlock()
+	if s == nil {
+		glog.Errorf("no db for shard %d", shardId)
+		err = fmt.Errorf("no db for shard %d", shardId)
+	}
+	return
+}
+
+func (m *MemDB) Put(id RecordID, value []byte) error {
+	s, err := m.getShard(id)
+	if err != nil {
+		return err
+	}
+	key := append([]byte(nil), id...)
+	value = append([]byte(nil), value...)
+
+	s.Lock()
+	defer s.Unlock()
+	if s.wal == nil {
+		return NewDBError(errMemShardClosed)
+	}
+	if err = s.wal.append(kWalOpPut, key, value); err != nil {
+		glog.Errorf("MemDB error while Put: %s", err.Error())
+		return NewDBError(err)
+	}
+	s.list.put(key, value)
+	return nil
+}
+
+// get returns the value of the record, which is never modified in place.
+func (m *MemDB) get(id RecordID) (value []byte, err error) {
+	var s *memShardT
+	if s, err = m.getShard(id); err != nil {
+		return
+	}
+	s.RLock()
+	value, _ = s.list.get(id)
+	s.RUnlock()
+	return
+}
+
+// Caller's responsibility to
+// 1) zero'd rec before calling, and
+// 2) free rec.holder if not nil afterwards
+func (m *MemDB) GetRecord(id RecordID, rec *Record) (exist bool, err error) {
+	var value []byte
+	if value, err = m.get(id); err != nil || value == nil {
+		return
+	}
+	if err = rec.Decode(value); err != nil {
+		glog.Error(err)
+		err = NewDBError(err)
+		return
+	}
+	exist = !rec.isPurged(id)
+	return
+}
+
+func (m *MemDB) Get(id RecordID, fetchExpired bool) (*Record, error) {
+	value, err := m.get(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	rec := new(Record)
+	if err = rec.Decode(value); err != nil {
+		return rec, NewDBError(err)
+	}
+
+	// purged namespace is not visible
+	if rec.isPurged(id) {
+		return nil, nil
+	}
+
+	// Let caller handle key expiration
+	return rec, nil
+}
+
+func (m *MemDB) IsRecordPresent(id RecordID, rec *Record) (existAndNotExpired bool, err error) {
+	var exist bool
+	if exist, err = m.GetRecord(id, rec); err != nil {
+		return
+	}
+	existAndNotExpired = exist && !rec.IsExpired()
+	return
+}
+
+func (m *MemDB) IsPresent(id RecordID) (bool, error, *Record) {
+	rec, err := m.Get(id, false)
+	if err != nil {
+		return false, err, nil
+	}
+
+	// nokey or expired
+	if rec == nil || rec.IsExpired() {
+		return false, nil, nil
+	}
+	return true, nil, rec
+}
+
+func (m *MemDB) Delete(id RecordID) error {
+	s, err := m.getShard(id)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.wal == nil {
+		return NewDBError(errMemShardClosed)
+	}
+	if _, found := s.list.get(id); !found {
+		return nil
+	}
+	if err = s.wal.append(kWalOpDelete, id, nil); err != nil {
+		glog.Errorf("MemDB error while delete: %s", err.Error())
+		return NewDBError(err)
+	}
+	s.list.delete(id)
+	return nil
+}
+
+func (c *memCursorT) Valid() bool {
+	return c.node != nil && bytes.HasPrefix(c.node.key, c.prefix)
+}
+
+func (c *memCursorT) Next() {
+	c.node = c.node.nextNode()
+}
+
+func (c *memCursorT) StorageKey() []byte {
+	return c.node.key[c.offset:]
+}
+
+func (c *memCursorT) Value() []byte {
+	return c.node.value
+}
+
+func (m *MemDB) Scan(shardId shard.ID, ns []byte, prefix []byte, startAfter []byte, limit int) ([]ScanRecord, error) {
+	m.mtx.RLock()
+	s := m.dbs[shardId]
+	m.mtx.RUnlock()
+	if s == nil {
+		return nil, NewDBError(fmt.Errorf("no db for shard %d", shardId))
+	}
+
+	scanPrefix, seekKey := scanStorageKeys(ns, prefix, startAfter)
+	numCursors := 1
+	if enableMircoShardId {
+		numCursors = m.numMicroShards
+	}
+	offset := storageKeyOffset()
+
+	s.RLock()
+	defer s.RUnlock()
+	cursors := make([]scanCursorI, 0, numCursors)
+	for i := 0; i < numCursors; i++ {
+		keyPrefix := make([]byte, offset, offset+len(seekKey))
+		keyPrefix[0], keyPrefix[1] = byte(shardId>>8), byte(shardId)
+		if enableMircoShardId {
+			keyPrefix[2] = uint8(i)
+		}
+		cursors = append(cursors, &memCursorT{
+			node:   s.list.seek(append(keyPrefix, seekKey...)),
+			prefix: append(keyPrefix[:offset:offset], scanPrefix...),
+			offset: offset,
+		})
+	}
+	return mergeScan(cursors, startAfter, limit), nil
+}
+
+// snapshot returns the records of the shard. As records are never modified
+// in place, they are not copied.
+func (s *memShardT) snapshot() []memEntryT {
+	s.RLock()
+	defer s.RUnlock()
+	return s.list.entries()
+}
+
+// Run in a seperate go routine
+// - can only have one go routine running per instance at a time
+// - be able to abort
+func (m *MemDB) ReplicateSnapshot(shardId shard.ID, rb *redist.Replicator, mshardid int32) bool {
+	if !redist.IsEnabled() {
+		glog.Infof("Redistribute is not enabled, ignore replicating snapshot for shard %d", shardId)
+		return false
+	}
+	m.mtx.RLock()
+	s := m.dbs[shardId]
+	m.mtx.RUnlock()
+	if s == nil {
+		glog.Errorf("no db for shard %d", shardId)
+		return false
+	}
+
+	entries := s.snapshot()
+	start := time.Now()
+
+	numMShardsPerGroup := m.numMicroShards
+	if m.numMicroShardGroups > 0 {
+		numMShardsPerGroup = m.numMicroShards / m.numMicroShardGroups
+	}
+	groupnum := 0
+	var msgroup MicroShardGroupStats
+	msgroup.reset(m.numMicroShards, numMShardsPerGroup, groupnum)
+	if m.numMicroShards == 0 {
+		rb.GetSnapshotStats().SetStatus(redistst.StatsFinish)
+	}
+
+	rlconfig := redist.RedistConfig.SnapshotRateLimit
+	if rb.GetRateLimit() > 0 {
+		rlconfig = int64(rb.GetRateLimit())
+	}
+	ratelimit := redist.NewRateLimiter(rlconfig*1000, 200)
+	filter := &compactionFilter{withShardId: true}
+
+	for _, e := range entries {
+		if enableMircoShardId {
+			cur_mshardid := int(e.key[2])
+			if cur_mshardid < int(mshardid) {
+				continue
+			}
+			for cur_mshardid > int(msgroup.end_id) {
+				//end of last micro shard group, waiting for this group to finish
+				m.waitForFinish(rb)
+				if abort := msgroup.logStats(shardId, rb); abort {
+					return false
+				}
+				groupnum++
+				msgroup.reset(m.numMicroShards, numMShardsPerGroup, groupnum)
+			}
+		}
+
+		ns, key, err := DecodeRecordKey(e.key)
+		if err != nil {
+			msgroup.cnt_err++
+			continue
+		}
+		// skip, if expired or purged
+		if expired, _ := filter.Filter(0, e.key, e.value); expired {
+			glog.Verbosef("snapshot record expired, skip. ns=%s, key=%s", ns, util.ToPrintableAndHexString(key))
+			msgroup.cnt_exp++
+			continue
+		}
+		rec := new(Record)
+		if err = rec.Decode(e.value); err != nil {
+			msgroup.cnt_err++
+			continue
+		}
+
+		// throttle
+		ratelimit.GetToken(int64(len(e.key) + len(e.value)))
+
+		if err = sendRedistRep(shardId, ns, key, rec, rb); err != nil {
+			rb.LogStats(start, true, true)
+			glog.Infof("target node is not available, abort the shard %d redistribution", shardId)
+			return false
+		}
+		msgroup.cnt_keys++
+
+		if !redist.IsEnabled() {
+			// aborted, exit now
+			glog.Infof("replicating snapshot for shard %d is aborted", shardId)
+			return false
+		}
+	}
+
+	// log last group stats
+	if !m.waitForFinish(rb) && m.numMicroShards == 0 {
+		return false
+	}
+	if abort := msgroup.logStats(shardId, rb); abort {
+		return false
+	}
+	for groupnum < m.numMicroShardGroups-1 {
+		// for non-primary shards, we still log stats
+		groupnum++
+		msgroup.reset(m.numMicroShards, numMShardsPerGroup, groupnum)
+		msgroup.logStats(shardId, rb)
+	}
+	return true
+}
+
+func (m *MemDB) ShardSupported(shardId shard.ID) bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	if _, ok := m.shards[shardId]; ok {
+		return true
+	}
+	_, ok := m.redistShards[shardId]
+	return ok
+}
+
+func (m *MemDB) UpdateShards(shards shard.Map) {
+	m.mtx.Lock()
+	m.openShards(shards)
+	var rmshards []shard.ID
+	for id := range m.shards {
+		if _, ok := shards[id]; !ok {
+			rmshards = append(rmshards, id)
+		}
+	}
+	m.shards = shards
+	m.redistShards = nil
+	m.mtx.Unlock()
+
+	if len(rmshards) > 0 {
+		// close the shards no longer needed, and remove their logs, not to
+		// replay stale records if assigned back later.
+		time.Sleep(1 * time.Second)
+		glog.Infof("shards to be removed: %v", rmshards)
+		m.mtx.Lock()
+		for _, id := range rmshards {
+			if s := m.dbs[id]; s != nil {
+				s.Lock()
+				if s.wal != nil {
+					s.wal.close()
+					if err := os.Remove(s.wal.path); err != nil {
+						glog.Errorf("failed to remove %s: %s", s.wal.path, err)
+					}
+					s.wal = nil
+				}
+				s.Unlock()
+				delete(m.dbs, id)
+			}
+		}
+		m.mtx.Unlock()
+	}
+}
+
+// called by redist watcher to update the shards
+func (m *MemDB) UpdateRedistShards(shards shard.Map) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if len(m.redistShards) > 0 && len(shards) > 0 {
+		// two redistribution in a row,
+		glog.Warningf("can't do two redistribution in a row")
+		return
+	}
+	if len(shards) == 0 {
+		glog.Debugf("no action needed")
+		m.redistShards = nil
+		return
+	}
+	m.openShards(shards)
+	m.redistShards = shards
+}
+
+func (m *MemDB) TruncateExpired() {
+	m.CompactAll(nil)
+}
+
+// CompactAll drops the expired and purged records of all the shards.
+func (m *MemDB) CompactAll(onDone func(i int, total int)) error {
+	m.mtx.RLock()
+	shards := make([]*memShardT, 0, len(m.dbs))
+	for _, s := range m.dbs {
+		shards = append(shards, s)
+	}
+	m.mtx.RUnlock()
+
+	for i, s := range shards {
+		if err := s.sweep(); err != nil {
+			return err
+		}
+		if onDone != nil {
+			onDone(i, len(shards))
+		}
+	}
+	return nil
+}
+
+func (m *MemDB) compactable() ICompactable {
+	return m
+}
+
+func (m *MemDB) NumDbs() int {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return len(m.dbs)
+}
+
+func (m *MemDB) sweepLoop() {
+	ticker := time.NewTicker(kMemDBSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.chDone:
+			return
+		case <-ticker.C:
+			if err := m.CompactAll(nil); err != nil {
+				glog.Errorf("MemDB sweep: %s", err)
+			}
+		}
+	}
+}
+
+// sweep drops the expired and purged records, and rewrites the log if it has
+// grown too large. The records to drop are looked up with the shard read
+// locked, and deleted kMemDBSweepBatchSize at a time, not to block the requests
+// of the shard for long.
+func (s *memShardT) sweep() (err error) {
+	filter := &compactionFilter{withShardId: true}
+	var expired [][]byte
+	s.RLock()
+	if s.wal == nil {
+		s.RUnlock()
+		return nil
+	}
+	for x := s.list.first(); x != nil; x = x.nextNode() {
+		if remove, _ := filter.Filter(0, x.key, x.value); remove {
+			expired = append(expired, x.key)
+		}
+	}
+	s.RUnlock()
+
+	for len(expired) > 0 {
+		n := len(expired)
+		if n > kMemDBSweepBatchSize {
+			n = kMemDBSweepBatchSize
+		}
+		if err = s.deleteExpired(filter, expired[:n]); err != nil {
+			return
+		}
+		expired = expired[n:]
+	}
+	return s.rewriteWal()
+}
+
+// deleteExpired deletes the records of the keys still dropped by the filter,
+// as they may have been updated since looked up.
+func (s *memShardT) deleteExpired(filter *compactionFilter, keys [][]byte) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.wal == nil {
+		return nil
+	}
+	for _, key := range keys {
+		value, found := s.list.get(key)
+		if !found {
+			continue
+		}
+		if remove, _ := filter.Filter(0, key, value); !remove {
+			continue
+		}
+		if err := s.wal.append(kWalOpDelete, key, nil); err != nil {
+			return NewDBError(err)
+		}
+		s.list.delete(key)
+	}
+	return nil
+}
+
+// rewriteWal rewrites the log, if it has grown too large, from a snapshot of
+// the records. The shard is only locked to append the entries logged since the
+// snapshot, and swap the logs.
+func (s *memShardT) rewriteWal() (err error) {
+	s.RLock()
+	w := s.wal
+	if w == nil || w.size <= int64(2*s.list.bytes+kMinWalRewriteSize) {
+		s.RUnlock()
+		return nil
+	}
+	offset := w.size
+	entries := s.list.entries()
+	s.RUnlock()
+
+	start := time.Now()
+	var tmp *walT
+	if tmp, err = newTmpWal(w.path, entries); err != nil {
+		glog.Errorf("failed to rewrite %s: %s", w.path, err)
+		return NewDBError(err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.wal != w {
+		// closed meanwhile
+		tmp.discard()
+		return nil
+	}
+	if err = tmp.appendFrom(w, offset); err == nil {
+		err = tmp.install()
+	}
+	if err != nil {
+		tmp.discard()
+		glog.Errorf("failed to rewrite %s: %s", w.path, err)
+		return NewDBError(err)
+	}
+	w.close()
+	tmp.sync = w.sync
+	s.wal = tmp
+	glog.Infof("%s rewritten in %s", w.path, time.Since(start))
+	return nil
+}
+
+// TODO: need lock?
+func (m *MemDB) Shutdown() {
+	start := time.Now()
+	glog.Debug("DB shutting down ...")
+
+	close(m.chDone)
+	m.mtx.Lock()
+	for id, s := range m.dbs {
+		s.Lock()
+		if err := s.wal.close(); err != nil {
+			glog.Errorf("failed to close %s: %s", s.wal.path, err)
+		}
+		s.wal = nil
+		s.Unlock()
+		delete(m.dbs, id)
+	}
+	m.mtx.Unlock()
+
+	glog.Infof("DB shutdown completed in %s", time.Since(start))
+}
+
+func (m *MemDB) WriteProperty(propKey string, w io.Writer) {
+	fmt.Fprintln(w, "memdb."+propKey)
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	for id, s := range m.dbs {
+		s.RLock()
+		fmt.Fprintf(w, "\nshard %d: keys=%d bytes=%d wal_bytes=%d\n", id, s.list.count, s.list.bytes, s.wal.size)
+		s.RUnlock()
+	}
+}
+
+// Get total count
+func (m *MemDB) GetIntProperty(propKey string) (n uint64) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	for _, s := range m.dbs {
+		s.RLock()
+		switch propKey {
+		case "estimate-num-keys":
+			n += uint64(s.list.count)
+		case "estimate-live-data-size":
+			n += uint64(s.list.bytes)
+		}
+		s.RUnlock()
+	}
+	return
+}
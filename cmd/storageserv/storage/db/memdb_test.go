@@ -0,0 +1,213 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"juno/pkg/shard"
+)
+
+func TestSkipList(t *testing.T) {
+	l := newSkipList()
+	for _, i := range []int{5, 3, 9, 1, 7, 3} {
+		l.put([]byte(fmt.Sprintf("k%d", i)), []byte(fmt.Sprintf("v%d", i)))
+	}
+	if l.count != 5 {
+		t.Fatalf("count=%d, expected 5", l.count)
+	}
+	if v, ok := l.get([]byte("k7")); !ok || string(v) != "v7" {
+		t.Errorf("get k7: %q %v", v, ok)
+	}
+	if !l.delete([]byte("k5")) || l.delete([]byte("k5")) {
+		t.Error("delete k5")
+	}
+	var keys []string
+	for x := l.first(); x != nil; x = x.nextNode() {
+		keys = append(keys, string(x.key))
+	}
+	if fmt.Sprint(keys) != "[k1 k3 k7 k9]" {
+		t.Errorf("keys=%v", keys)
+	}
+	if x := l.seek([]byte("k4")); x == nil || string(x.key) != "k7" {
+		t.Error("seek k4 should return k7")
+	}
+	if x := l.seek([]byte("kz")); x != nil {
+		t.Error("seek kz should return nil")
+	}
+}
+
+func TestWal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	replay := func() *skipListT {
+		l := newSkipList()
+		w, err := openWal(path, false, func(op byte, key []byte, value []byte) {
+			if op == kWalOpPut {
+				l.put(key, value)
+			} else {
+				l.delete(key)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = w.close(); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	noop := func(byte, []byte, []byte) {}
+	w, err := openWal(path, true, noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.append(kWalOpPut, []byte("a"), []byte("1"))
+	w.append(kWalOpPut, []byte("b"), []byte("2"))
+	w.append(kWalOpDelete, []byte("a"), nil)
+	size := w.size
+	w.close()
+
+	// partially written entry
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.Write([]byte{1, 2, 3, 4, 5, 6})
+	f.Close()
+
+	l := replay()
+	if v, ok := l.get([]byte("b")); l.count != 1 || !ok || string(v) != "2" {
+		t.Errorf("unexpected records after replay: count=%d", l.count)
+	}
+	if st, _ := os.Stat(path); st.Size() != size {
+		t.Errorf("corrupt tail not truncated: size=%d, expected %d", st.Size(), size)
+	}
+
+	// rewrite from a snapshot, with the entries appended meanwhile
+	w, _ = openWal(path, false, noop)
+	offset := w.size
+	tmp, err := newTmpWal(path, l.entries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.append(kWalOpPut, []byte("c"), []byte("3"))
+	w.append(kWalOpDelete, []byte("b"), nil)
+	if err = tmp.appendFrom(w, offset); err != nil {
+		t.Fatal(err)
+	}
+	if err = tmp.install(); err != nil {
+		t.Fatal(err)
+	}
+	w.close()
+	tmp.append(kWalOpPut, []byte("d"), []byte("4"))
+	tmp.close()
+	l = replay()
+	if _, ok := l.get([]byte("b")); l.count != 2 || ok {
+		t.Errorf("count=%d after rewrite, expected 2 without b", l.count)
+	}
+	if st, _ := os.Stat(path); st.Size() != tmp.size {
+		t.Errorf("size=%d after rewrite, expected %d", st.Size(), tmp.size)
+	}
+}
+
+func TestMemDB(t *testing.T) {
+	saved := DBConfig
+	defer func() { DBConfig = saved }()
+	DBConfig.WalDir = t.TempDir()
+	DBConfig.DbPaths = nil
+
+	shards := shard.Map{1: struct{}{}}
+	m := newMemDB(0, 0, 0, 0, shards)
+
+	var buf bytes.Buffer
+	put := func(key string, value string, ttl int64) {
+		id := NewRecordIDWithBuffer(&buf, 1, 0, []byte("ns"), []byte(key))
+		rec := &Record{RecordHeader: RecordHeader{ExpirationTime: uint32(time.Now().Unix() + ttl)}}
+		rec.Payload.SetWithClearValue([]byte(value))
+		var v bytes.Buffer
+		if err := rec.EncodeToBuffer(&v); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Put(id, v.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(key string) *Record {
+		rec, err := m.Get(NewRecordIDWithBuffer(&buf, 1, 0, []byte("ns"), []byte(key)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	put("a:1", "1", 3600)
+	put("a:2", "2", 3600)
+	put("a:3", "3", -1)
+	put("b:1", "4", 3600)
+	if err := m.Delete(NewRecordIDWithBuffer(&buf, 1, 0, []byte("ns"), []byte("a:2"))); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get("a:1"); rec == nil || string(rec.Payload.GetData()) != "1" {
+		t.Error("a:1 not found")
+	}
+	if rec := get("a:2"); rec != nil {
+		t.Error("a:2 not deleted")
+	}
+	if _, err := m.Get(NewRecordIDWithBuffer(&buf, 2, 0, []byte("ns"), []byte("a:1")), false); err == nil {
+		t.Error("shard 2 should not be supported")
+	}
+
+	recs, err := m.Scan(1, []byte("ns"), []byte("a:"), nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || string(recs[0].Key) != "a:1" {
+		t.Errorf("unexpected scan result %v", recs)
+	}
+
+	m.TruncateExpired()
+	if n := m.GetIntProperty("estimate-num-keys"); n != 2 {
+		t.Errorf("estimate-num-keys=%d after sweep, expected 2", n)
+	}
+	m.Shutdown()
+
+	// reopen
+	m = newMemDB(0, 0, 0, 0, shards)
+	defer m.Shutdown()
+	if n := m.GetIntProperty("estimate-num-keys"); n != 2 {
+		t.Errorf("estimate-num-keys=%d after reopen, expected 2", n)
+	}
+	if rec := get("b:1"); rec == nil || string(rec.Payload.GetData()) != "4" {
+		t.Error("b:1 not found after reopen")
+	}
+
+	// the records of a removed shard are not back if assigned back
+	m.UpdateShards(shard.Map{2: struct{}{}})
+	if _, err := os.Stat(filepath.Join(DBConfig.WalDir, memWalName(0, 0, 1))); err == nil {
+		t.Error("wal of removed shard 1 not removed")
+	}
+	m.UpdateShards(shard.Map{1: struct{}{}, 2: struct{}{}})
+	if rec := get("b:1"); rec != nil {
+		t.Error("b:1 of removed shard 1 found")
+	}
+}
@@ -0,0 +1,147 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync/atomic"
+
+	"juno/third_party/forked/golang/glog"
+)
+
+// Namespace purge.
+//
+// A purge marks namespaces, or key prefixes within them, as deleted as of
+// a point in time. Records last modified before that time are treated as
+// not found by the read path and dropped by the compaction filter, so the
+// purged data disappears right away and its space is reclaimed as
+// compaction proceeds. Records written after the purge time are kept.
+
+type PurgeEntry struct {
+	Namespace string
+	Prefix    []string
+}
+
+type purgeSetT struct {
+	purgeTime uint64 // unix nano
+	nsMap     map[string][][]byte
+}
+
+var (
+	thePurgeSet atomic.Value // *purgeSetT
+	purgeCount  uint64
+)
+
+// Replaces the active purge. The purge count is reset when purgeTime changes.
+func SetPurge(purgeTime int64, entries []PurgeEntry) {
+	s := &purgeSetT{
+		purgeTime: uint64(purgeTime),
+		nsMap:     make(map[string][][]byte, len(entries)),
+	}
+	for _, e := range entries {
+		list := s.nsMap[e.Namespace]
+		for _, prefix := range e.Prefix {
+			list = append(list, []byte(prefix))
+		}
+		s.nsMap[e.Namespace] = list
+	}
+
+	if cur := getPurgeSet(); cur == nil || cur.purgeTime != s.purgeTime {
+		atomic.StoreUint64(&purgeCount, 0)
+	}
+	thePurgeSet.Store(s)
+	glog.Infof("purge set: time=%d entries=%v", purgeTime, entries)
+}
+
+func ClearPurge() {
+	if getPurgeSet() != nil {
+		thePurgeSet.Store((*purgeSetT)(nil))
+		glog.Infof("purge cleared")
+	}
+}
+
+// Number of purged records dropped by compaction.
+func GetPurgeCount() uint64 {
+	return atomic.LoadUint64(&purgeCount)
+}
+
+func getPurgeSet() *purgeSetT {
+	s, _ := thePurgeSet.Load().(*purgeSetT)
+	return s
+}
+
+// storageKey starts with the namespace length, i.e. without shard id.
+func isPurged(storageKey []byte, lastModificationTime uint64) bool {
+	s := getPurgeSet()
+	if s == nil || lastModificationTime >= s.purgeTime || len(storageKey) < 2 {
+		return false
+	}
+
+	stop := 1 + int(storageKey[0])
+	if len(storageKey) < stop {
+		return false
+	}
+	prefixList, found := s.nsMap[string(storageKey[1:stop])]
+	if !found {
+		return false
+	}
+	if len(prefixList) == 0 {
+		return true
+	}
+
+	key := storageKey[stop:]
+	for _, prefix := range prefixList {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Used by the compaction filter on raw records.
+func isPurgedValue(storageKey []byte, value []byte) bool {
+	if len(value) < kOffLastModificationTime+kSzLastModificationTime {
+		return false
+	}
+	lastModificationTime := binary.BigEndian.Uint64(
+		value[kOffLastModificationTime : kOffLastModificationTime+kSzLastModificationTime])
+	if !isPurged(storageKey, lastModificationTime) {
+		return false
+	}
+
+	n := atomic.AddUint64(&purgeCount, 1)
+	if n%100000 == 0 {
+		glog.Infof("purged_keys=%d", n)
+	}
+	return true
+}
+
+func (rec *Record) isPurged(id RecordID) bool {
+	return isPurged(id.GetKeyWithoutShardID(), rec.LastModificationTime)
+}
+
+// Offset of the namespace length in a key with shard id.
+func storageKeyOffset() int {
+	if enableMircoShardId {
+		return 3
+	}
+	return 2
+}
@@ -0,0 +1,82 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"juno/pkg/shard"
+)
+
+func TestPurge(t *testing.T) {
+	defer ClearPurge()
+
+	now := time.Now().UnixNano()
+	SetPurge(now, []PurgeEntry{
+		{Namespace: "ns1"},
+		{Namespace: "ns2", Prefix: []string{"a:", "b:"}},
+	})
+
+	var buf bytes.Buffer
+	tests := []struct {
+		ns     string
+		key    string
+		mtime  int64
+		purged bool
+	}{
+		{"ns1", "key", now - 1, true},
+		{"ns1", "key", now, false},
+		{"ns2", "a:key", now - 1, true},
+		{"ns2", "b:", now - 1, true},
+		{"ns2", "c:key", now - 1, false},
+		{"ns3", "a:key", now - 1, false},
+		{"ns", "1key", now - 1, false},
+	}
+
+	for _, tc := range tests {
+		id := NewRecordIDWithBuffer(&buf, shard.ID(3), 1, []byte(tc.ns), []byte(tc.key))
+		rec := &Record{RecordHeader: RecordHeader{
+			LastModificationTime: uint64(tc.mtime),
+			ExpirationTime:       uint32(time.Now().Unix() + 3600),
+		}}
+		if rec.isPurged(id) != tc.purged {
+			t.Errorf("ns=%s key=%s mtime=%d: expected purged=%v", tc.ns, tc.key, tc.mtime, tc.purged)
+		}
+
+		// compaction filter sees the encoded record
+		var value bytes.Buffer
+		if err := rec.EncodeToBuffer(&value); err != nil {
+			t.Fatal(err)
+		}
+		filter := &compactionFilter{withShardId: true}
+		if remove, _ := filter.Filter(0, id, value.Bytes()); remove != tc.purged {
+			t.Errorf("ns=%s key=%s: filter expected remove=%v", tc.ns, tc.key, tc.purged)
+		}
+	}
+
+	ClearPurge()
+	id := NewRecordIDWithBuffer(&buf, shard.ID(3), 1, []byte("ns1"), []byte("key"))
+	rec := &Record{RecordHeader: RecordHeader{LastModificationTime: uint64(now - 1)}}
+	if rec.isPurged(id) {
+		t.Error("purge not cleared")
+	}
+}
@@ -18,7 +18,7 @@
 //
 
 /*
-Package db implements Juno storage interfaces with gorocksdb.
+Package db implements Juno storage interfaces with gorocksdb, or in memory.
 
 Record Encoding Format
 
@@ -64,6 +64,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"juno/third_party/forked/golang/glog"
@@ -106,6 +107,7 @@ const (
 type (
 	compactionFilter struct {
 		shardFilter *ShardFilter
+		withShardId bool // keys are prefixed with shard id
 	}
 	recordFlagT byte
 
@@ -132,6 +134,27 @@ type (
 	}
 )
 
+type ShardFilter struct {
+	shardNum int32
+}
+
+func (s *ShardFilter) matchShardNum(key []byte) bool {
+	expected := atomic.LoadInt32(&s.shardNum)
+	if expected < 0 {
+		return false
+	}
+	actual := int32(binary.BigEndian.Uint16(key[0:]))
+	return expected == actual
+}
+
+func (s *ShardFilter) SetShardNum(shardNum int32) {
+	atomic.StoreInt32(&s.shardNum, shardNum)
+}
+
+func (s *ShardFilter) Disable() {
+	atomic.StoreInt32(&s.shardNum, -1)
+}
+
 func (m *compactionFilter) Name() string {
 	return "JunoCompactionFilter\x00"
 }
@@ -156,6 +179,17 @@ func (m *compactionFilter) Filter(level int, key, value []byte) (expired bool, n
 		return true, nil
 	}
 
+	storageKey := key
+	if m.withShardId {
+		if len(key) < storageKeyOffset() {
+			return false, nil
+		}
+		storageKey = key[storageKeyOffset():]
+	}
+	if isPurgedValue(storageKey, value) {
+		return true, nil
+	}
+
 	return false, nil
 }
 
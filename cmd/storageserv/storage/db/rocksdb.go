@@ -17,6 +17,9 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 package db
 
 import (
@@ -34,7 +37,6 @@ import (
 	"juno/cmd/storageserv/redist"
 	"juno/pkg/logging"
 	"juno/pkg/logging/cal"
-	"juno/pkg/proto"
 	"juno/pkg/shard"
 )
 
@@ -45,24 +47,6 @@ var (
 
 var _ IDatabase = (*RocksDB)(nil)
 
-type DBError struct {
-	err error
-}
-
-func (e *DBError) Error() string {
-	if e.err != nil {
-		return "DBError: " + e.err.Error()
-	}
-	return "DBErr: "
-}
-
-func NewDBError(e error) *DBError {
-	return &DBError{err: e}
-}
-
-var rocksdbIndex int32 = 0
-var rocksdb [2]IDatabase
-
 type RocksDB struct {
 	zoneId       int
 	nodeId       int
@@ -72,11 +56,6 @@ type RocksDB struct {
 	sharding     IDBSharding
 }
 
-func GetDB() IDatabase {
-	var index int32 = atomic.LoadInt32(&rocksdbIndex)
-	return rocksdb[index]
-}
-
 func GetPrefixDB() *ShardingByPrefix {
 	g := GetDB()
 	if g == nil {
@@ -92,6 +71,13 @@ func GetPrefixDB() *ShardingByPrefix {
 	return nil
 }
 
+func (r *RocksDB) compactable() ICompactable {
+	if d, ok := r.sharding.(*ShardingByPrefix); ok {
+		return d
+	}
+	return nil
+}
+
 ///TODO xuli dbDir...
 func newDBSharding(numShards int, numMicroShards int, numMicroShardGroups int, numPrefixDbs int, dbnamePrefix string) (sharding IDBSharding) {
 	if numPrefixDbs > 0 { // Use prefix key
@@ -128,28 +114,6 @@ func newRocksDB(numShards int, numMicroShards int, numMicroShardGroups int, numP
 	return db
 }
 
-// only called once during start up
-func Initialize(
-	numShards int, numMicroShards int, numMicroShardGroups int,
-	numPrefixDbs int, zoneId int, nodeId int, shardMap shard.Map, lruCacheSizeInMB int) {
-	if numMicroShards > 0 {
-		SetEnableMircoShardId(true)
-		glog.Infof("Enable micro shards, NumMicroShards=%d, numMshardGroups=%d", numMicroShards, numMicroShardGroups)
-	}
-
-	if DBConfig.NewLRUCacheSizeInMB == 0 && lruCacheSizeInMB > 0 { // Use computed value
-		DBConfig.NewLRUCacheSizeInMB = lruCacheSizeInMB
-	}
-	db := newRocksDB(numShards, numMicroShards, numMicroShardGroups, numPrefixDbs, zoneId, nodeId, shardMap)
-	rocksdb[rocksdbIndex] = db
-	// safe guard?
-	rocksdb[(rocksdbIndex+1)%2] = db
-}
-
-func Finalize() {
-	GetDB().Shutdown()
-}
-
 func fastDbFlush(db *gorocksdb.DB) {
 
 	key := "disable_auto_compactions"
@@ -171,13 +135,27 @@ func (r *RocksDB) Put(id RecordID, value []byte) error {
 	}
 
 	var err error
+	index := r.sharding.getExpiryIndex(id)
+	write := func(ikey []byte) error {
+		put := func() error {
+			if index != nil {
+				return index.putWithIndex(db, key, value, ikey)
+			}
+			return db.Put(writeOptions, key, value)
+		}
+		if cal.LogDebug() {
+			start := time.Now()
+			err := put()
+			r.LogCalTransaction(start, logging.CalMsgNameDbPut, err)
+			return err
+		}
+		return put()
+	}
 
-	if cal.LogDebug() {
-		start := time.Now()
-		err = db.Put(writeOptions, key, value)
-		defer r.LogCalTransaction(start, logging.CalMsgNameDbPut, err)
+	if index != nil {
+		err = index.put(key, value, write)
 	} else {
-		err = db.Put(writeOptions, key, value)
+		err = write(nil)
 	}
 	if err != nil {
 		glog.Errorf("RocksDB error while Put: %s", err.Error())
@@ -214,6 +192,8 @@ func (r *RocksDB) GetRecord(recId RecordID, rec *Record) (exist bool, err error)
 			if gerr != nil {
 				glog.Error(gerr)
 				err = NewDBError(err)
+			} else if rec.isPurged(recId) {
+				exist = false
 			}
 		}
 	} else {
@@ -275,6 +255,11 @@ func (r *RocksDB) Get(recId RecordID, fetchExpired bool) (*Record, error) {
 		return rec, NewDBError(err)
 	}
 
+	// purged namespace is not visible
+	if rec.isPurged(recId) {
+		return nil, nil
+	}
+
 	// Let caller handle key expiration
 	return rec, nil
 }
@@ -477,23 +462,18 @@ func (r *RocksDB) ReplicateSnapshot(shardId shard.ID, rb *redist.Replicator, msh
 	return r.sharding.replicateSnapshot(shardId, rb, mshardid)
 }
 
-func sendRedistRep(shardId shard.ID, ns []byte, key []byte, rec *Record, rb *redist.Replicator) (err error) {
-
-	var rowMsg proto.RawMessage
-	rec.EncodeRedistMsg(shardId, ns, key, &rowMsg)
-
-	maxtry := redist.RedistConfig.MaxWaitTime * 1000 / 20
-
-	for i := 0; i < maxtry; i++ {
-		err := rb.SendRequest(&rowMsg, false, false)
-		if err == nil {
-			return nil
-		}
-		time.Sleep(20 * time.Millisecond)
+// Scan returns up to limit live records of namespace ns in the shard, in key
+// order, with the key prefix and key after startAfter.
+func (r *RocksDB) Scan(shardId shard.ID, ns []byte, prefix []byte, startAfter []byte, limit int) (recs []ScanRecord, err error) {
+	if cal.LogDebug() {
+		start := time.Now()
+		defer func() { r.LogCalTransaction(start, logging.CalMsgNameDbScan, err) }()
 	}
-
-	// one last try
-	return rb.SendRequest(&rowMsg, false, true)
+	if recs, err = r.sharding.scan(shardId, ns, prefix, startAfter, limit); err != nil {
+		glog.Errorf("scan: %s", err)
+		err = NewDBError(err)
+	}
+	return
 }
 
 func (r *RocksDB) ShardSupported(shardId shard.ID) bool {
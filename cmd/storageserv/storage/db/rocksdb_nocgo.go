@@ -0,0 +1,49 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !cgo
+// +build !cgo
+
+package db
+
+import (
+	"juno/third_party/forked/golang/glog"
+
+	"juno/pkg/shard"
+)
+
+// Without cgo, the storage server is built with the memory engine only.
+
+type (
+	CompressionType uint
+	InfoLogLevel    int
+)
+
+const (
+	defaultCompression  CompressionType = 0
+	defaultInfoLogLevel InfoLogLevel    = 1
+)
+
+func newRocksDB(numShards int, numMicroShards int, numMicroShardGroups int, numPrefixDbs int, zoneId int, nodeId int, shardMap shard.Map) IDatabase {
+	glog.Exitf("DB.Engine %q requires a build with cgo, set DB.Engine to %q", EngineRocksDB, EngineMemory)
+	return nil
+}
+
+func setWriteOptions(cfg *Config) {
+}
@@ -0,0 +1,121 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"container/heap"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/pkg/util"
+)
+
+// ScanRecord is a record returned by a namespace scan.
+type ScanRecord struct {
+	Key []byte
+	Record
+}
+
+type (
+	// scanCursorI iterates the records of a namespace in key order.
+	scanCursorI interface {
+		Valid() bool
+		Next()
+		// StorageKey returns the key starting with the namespace length.
+		StorageKey() []byte
+		Value() []byte
+	}
+
+	cursorHeapT []scanCursorI
+)
+
+func (h cursorHeapT) Len() int { return len(h) }
+func (h cursorHeapT) Less(i, j int) bool {
+	return bytes.Compare(h[i].StorageKey(), h[j].StorageKey()) < 0
+}
+func (h cursorHeapT) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *cursorHeapT) Push(x interface{}) { *h = append(*h, x.(scanCursorI)) }
+func (h *cursorHeapT) Pop() interface{} {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	*h = old[:n-1]
+	return c
+}
+
+// scanStorageKeys returns the storage key prefix of the records of ns with the
+// key prefix, and the storage key to seek to for the first record after
+// startAfter. Both start with the namespace length.
+func scanStorageKeys(ns []byte, prefix []byte, startAfter []byte) (scanPrefix []byte, seekKey []byte) {
+	scanPrefix = make([]byte, 0, 1+len(ns)+len(prefix))
+	scanPrefix = append(scanPrefix, uint8(len(ns)))
+	scanPrefix = append(scanPrefix, ns...)
+	scanPrefix = append(scanPrefix, prefix...)
+
+	seekKey = scanPrefix
+	if bytes.Compare(startAfter, prefix) > 0 {
+		seekKey = append(scanPrefix[:1+len(ns):1+len(ns)], startAfter...)
+	}
+	return
+}
+
+// mergeScan merges the records of the cursors in key order, and returns up to
+// limit records with key after startAfter. Expired and purged records are
+// skipped. Marked deleted records are returned, for the proxy to merge them
+// with the records of the other replicas.
+func mergeScan(cursors []scanCursorI, startAfter []byte, limit int) (recs []ScanRecord) {
+	h := make(cursorHeapT, 0, len(cursors))
+	for _, c := range cursors {
+		if c.Valid() {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+
+	for h.Len() > 0 && len(recs) < limit {
+		c := h[0]
+		storageKey := c.StorageKey()
+		key := storageKey[1+int(storageKey[0]):]
+
+		if bytes.Compare(key, startAfter) > 0 {
+			value := make([]byte, len(c.Value()))
+			copy(value, c.Value())
+
+			var rec Record
+			if err := rec.Decode(value); err != nil {
+				glog.Warningf("scan: %s, key=%s", err, util.ToPrintableAndHexString(key))
+			} else if !rec.IsExpired() && !isPurged(storageKey, rec.LastModificationTime) {
+				recs = append(recs, ScanRecord{
+					Key:    append([]byte(nil), key...),
+					Record: rec,
+				})
+			}
+		}
+
+		c.Next()
+		if c.Valid() {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return
+}
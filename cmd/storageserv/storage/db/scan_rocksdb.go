@@ -0,0 +1,55 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build cgo
+// +build cgo
+
+package db
+
+import (
+	"juno/third_party/forked/tecbot/gorocksdb"
+)
+
+// iterCursorT is the scanCursorI of a rocksdb iterator.
+type iterCursorT struct {
+	iter   *gorocksdb.Iterator
+	prefix []byte // storage key prefix of the scan
+	offset int    // offset of the namespace length in the key
+}
+
+func newIterCursor(iter *gorocksdb.Iterator, prefix []byte, offset int, seekKey []byte) *iterCursorT {
+	iter.Seek(seekKey)
+	return &iterCursorT{iter: iter, prefix: prefix, offset: offset}
+}
+
+func (c *iterCursorT) Valid() bool {
+	return c.iter.ValidForPrefix(c.prefix)
+}
+
+func (c *iterCursorT) Next() {
+	c.iter.Next()
+}
+
+func (c *iterCursorT) StorageKey() []byte {
+	return c.iter.Key().Data()[c.offset:]
+}
+
+func (c *iterCursorT) Value() []byte {
+	return c.iter.Value().Data()
+}
@@ -0,0 +1,121 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+)
+
+type sliceCursorT struct {
+	keys   [][]byte
+	values [][]byte
+	pos    int
+}
+
+func (c *sliceCursorT) Valid() bool        { return c.pos < len(c.keys) }
+func (c *sliceCursorT) Next()              { c.pos++ }
+func (c *sliceCursorT) StorageKey() []byte { return c.keys[c.pos] }
+func (c *sliceCursorT) Value() []byte      { return c.values[c.pos] }
+
+// newSliceCursor returns a cursor positioned like a rocksdb iterator seeking
+// to seekKey and bounded to scanPrefix.
+func newSliceCursor(t *testing.T, ns string, recs map[string]*Record, scanPrefix []byte, seekKey []byte) *sliceCursorT {
+	c := &sliceCursorT{}
+	var keys []string
+	for k := range recs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		storageKey := append([]byte{uint8(len(ns))}, ns...)
+		storageKey = append(storageKey, k...)
+		if !bytes.HasPrefix(storageKey, scanPrefix) || bytes.Compare(storageKey, seekKey) < 0 {
+			continue
+		}
+		var value bytes.Buffer
+		if err := recs[k].EncodeToBuffer(&value); err != nil {
+			t.Fatal(err)
+		}
+		c.keys = append(c.keys, storageKey)
+		c.values = append(c.values, value.Bytes())
+	}
+	return c
+}
+
+func TestMergeScan(t *testing.T) {
+	expiration := uint32(time.Now().Unix() + 3600)
+	live := func(v string) *Record {
+		rec := &Record{RecordHeader: RecordHeader{ExpirationTime: expiration}}
+		rec.Payload.SetWithClearValue([]byte(v))
+		return rec
+	}
+	deleted := live("")
+	deleted.MarkDelete()
+	expired := &Record{RecordHeader: RecordHeader{ExpirationTime: uint32(time.Now().Unix() - 1)}}
+
+	// records of two micro shards
+	shards := []map[string]*Record{
+		{"a:1": live("1"), "a:3": live("3"), "a:5": deleted, "b:1": live("1")},
+		{"a:2": live("2"), "a:4": expired, "a:6": live("6")},
+	}
+
+	tests := []struct {
+		prefix     string
+		startAfter string
+		limit      int
+		keys       []string
+	}{
+		{"a:", "", 10, []string{"a:1", "a:2", "a:3", "a:5", "a:6"}},
+		{"a:", "", 2, []string{"a:1", "a:2"}},
+		{"a:", "a:2", 2, []string{"a:3", "a:5"}},
+		{"a:", "a:6", 2, nil},
+		{"", "a:3", 10, []string{"a:5", "a:6", "b:1"}},
+		{"b:", "a:6", 10, []string{"b:1"}},
+	}
+	for _, tc := range tests {
+		scanPrefix, seekKey := scanStorageKeys([]byte("ns"), []byte(tc.prefix), []byte(tc.startAfter))
+		var cursors []scanCursorI
+		for _, recs := range shards {
+			cursors = append(cursors, newSliceCursor(t, "ns", recs, scanPrefix, seekKey))
+		}
+		var keys []string
+		for _, rec := range mergeScan(cursors, []byte(tc.startAfter), tc.limit) {
+			keys = append(keys, string(rec.Key))
+			if rec.IsMarkedDelete() != (string(rec.Key) == "a:5") {
+				t.Errorf("key=%s: unexpected marked delete %v", rec.Key, rec.IsMarkedDelete())
+			} else if !rec.IsMarkedDelete() && string(rec.Payload.GetData()) != string(rec.Key[2:]) {
+				t.Errorf("key=%s: unexpected value %q", rec.Key, rec.Payload.GetData())
+			}
+		}
+		if len(keys) != len(tc.keys) {
+			t.Errorf("prefix=%q after=%q limit=%d: got %v, expected %v", tc.prefix, tc.startAfter, tc.limit, keys, tc.keys)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != tc.keys[i] {
+				t.Errorf("prefix=%q after=%q limit=%d: got %v, expected %v", tc.prefix, tc.startAfter, tc.limit, keys, tc.keys)
+				break
+			}
+		}
+	}
+}
@@ -17,11 +17,13 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 package db
 
 import (
 	"io"
-	"time"
 
 	"juno/third_party/forked/tecbot/gorocksdb"
 
@@ -44,34 +46,10 @@ type IDBSharding interface {
 	duplicate() IDBSharding
 
 	replicateSnapshot(shardId shard.ID, rb *redist.Replicator, mshardid int32) bool
-}
-
-type ShardingBase struct {
-}
-
-func (s *ShardingBase) waitForFinish(rb *redist.Replicator) bool {
-	if rb.IsSnapShotDone() {
-		return true
-	}
-
-	maxwait := redist.RedistConfig.MaxWaitTime * 1000 / 10
 
-	// wait till the requests are all processed or max wait time reached
-	ticker := time.NewTicker(10 * time.Millisecond)
-	defer ticker.Stop()
+	scan(shardId shard.ID, ns []byte, prefix []byte, startAfter []byte, limit int) ([]ScanRecord, error)
 
-	ts_passed := 0
-	for {
-		select {
-		case <-ticker.C:
-			if rb.IsSnapShotDone() {
-				return true
-			}
+	backupDbs(shards shard.Map) []backupDbT
 
-			ts_passed++
-			if ts_passed > maxwait {
-				return false
-			}
-		}
-	}
+	getExpiryIndex(id RecordID) *expiryIndexT
 }
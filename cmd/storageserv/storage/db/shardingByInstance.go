@@ -17,6 +17,9 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 package db
 
 import (
@@ -279,6 +282,46 @@ LOOP:
 	return s.waitForFinish(rb)
 }
 
+// expiry index not supported
+func (s *ShardingByInstance) getExpiryIndex(id RecordID) *expiryIndexT {
+	return nil
+}
+
+func (s *ShardingByInstance) backupDbs(shards shard.Map) (dbs []backupDbT) {
+	for i, dbInst := range s.dbs {
+		if dbInst == nil {
+			continue
+		}
+		dbs = append(dbs, backupDbT{
+			name:    fmt.Sprintf("%s-%d.db", s.dbnamePrefix, i),
+			walName: fmt.Sprintf("wal-%s-%d", s.dbnamePrefix, i),
+			db:      dbInst,
+			shards:  []shard.ID{shard.ID(i)},
+		})
+	}
+	return
+}
+
+func (s *ShardingByInstance) scan(shardId shard.ID, ns []byte, prefix []byte, startAfter []byte, limit int) ([]ScanRecord, error) {
+	dbInst := s.dbs[shardId]
+	if dbInst == nil {
+		return nil, fmt.Errorf("no db for shard %d", shardId)
+	}
+
+	opts := gorocksdb.NewDefaultReadOptions()
+	defer opts.Destroy()
+	snapshot := dbInst.NewSnapshot()
+	defer dbInst.ReleaseSnapshot(snapshot)
+	opts.SetSnapshot(snapshot)
+
+	iter := dbInst.NewIterator(opts)
+	defer iter.Close()
+
+	scanPrefix, seekKey := scanStorageKeys(ns, prefix, startAfter)
+	cursor := newIterCursor(iter, scanPrefix, 0, seekKey)
+	return mergeScan([]scanCursorI{cursor}, startAfter, limit), nil
+}
+
 func (s *ShardingByInstance) decodeStorageKey(sskey []byte) ([]byte, []byte, error) {
 	return DecodeRecordKeyNoShardID(sskey)
 }
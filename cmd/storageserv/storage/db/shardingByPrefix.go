@@ -17,6 +17,9 @@
 //  limitations under the License.
 //
 
+//go:build cgo
+// +build cgo
+
 package db
 
 import (
@@ -24,8 +27,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"juno/third_party/forked/golang/glog"
@@ -33,37 +36,15 @@ import (
 
 	"juno/cmd/storageserv/redist"
 	"juno/pkg/shard"
-	redistst "juno/pkg/stats/redist"
 	"juno/pkg/util"
 )
 
-type ShardFilter struct {
-	shardNum int32
-}
-
 func (s *ShardFilter) SetCompactionFilter(opts *gorocksdb.Options, enable bool) {
 	if enable {
-		opts.SetCompactionFilter(&compactionFilter{shardFilter: s})
+		opts.SetCompactionFilter(&compactionFilter{shardFilter: s, withShardId: true})
 	} else {
-		opts.SetCompactionFilter(&compactionFilter{})
-	}
-}
-
-func (s *ShardFilter) matchShardNum(key []byte) bool {
-	expected := atomic.LoadInt32(&s.shardNum)
-	if expected < 0 {
-		return false
+		opts.SetCompactionFilter(&compactionFilter{withShardId: true})
 	}
-	actual := int32(binary.BigEndian.Uint16(key[0:]))
-	return expected == actual
-}
-
-func (s *ShardFilter) SetShardNum(shardNum int32) {
-	atomic.StoreInt32(&s.shardNum, shardNum)
-}
-
-func (s *ShardFilter) Disable() {
-	atomic.StoreInt32(&s.shardNum, -1)
 }
 
 type ShardingByPrefix struct {
@@ -73,6 +54,7 @@ type ShardingByPrefix struct {
 
 	dbnamePrefix        string
 	dbs                 []*gorocksdb.DB
+	expiryIndexes       []*expiryIndexT
 	shardFilters        []*ShardFilter // For ComactRangeByShard
 	numMicroShards      int
 	numMicroShardGroups int
@@ -116,7 +98,9 @@ func (s *ShardingByPrefix) setupShards(dbnamePrefix string, shardMap shard.Map)
 	var paths = make([]string, len(DBConfig.DbPaths))
 	var target_sizes = make([]uint64, len(DBConfig.DbPaths))
 
-	s.DbNames = make([]string, numDbs)
+	if len(s.DbNames) != numDbs {
+		s.DbNames = make([]string, numDbs)
+	}
 	options := make([]*gorocksdb.Options, numDbs)
 
 	for i := 0; i < numDbs; i++ {
@@ -137,7 +121,7 @@ func (s *ShardingByPrefix) setupShards(dbnamePrefix string, shardMap shard.Map)
 		}
 		options[i].SetPrefixExtractor(gorocksdb.NewFixedPrefixTransform(s.PrefixBytes))
 
-		options[i].SetCompactionFilter(&compactionFilter{shardFilter: s.shardFilters[i]})
+		options[i].SetCompactionFilter(&compactionFilter{shardFilter: s.shardFilters[i], withShardId: true})
 
 		fileName := fmt.Sprintf("%s-%d.db", dbnamePrefix, i)
 		for k, dbpath := range DBConfig.DbPaths {
@@ -153,6 +137,10 @@ func (s *ShardingByPrefix) setupShards(dbnamePrefix string, shardMap shard.Map)
 		}
 	}
 
+	if DBConfig.ExpiryIndexEnabled && len(s.expiryIndexes) != numDbs {
+		s.expiryIndexes = make([]*expiryIndexT, numDbs)
+	}
+
 	wg := new(sync.WaitGroup)
 	wg.Add(numDbs)
 	for i := 0; i < numDbs; i++ {
@@ -163,7 +151,12 @@ func (s *ShardingByPrefix) setupShards(dbnamePrefix string, shardMap shard.Map)
 		go func(ix int, option *gorocksdb.Options, dbname string) {
 			defer wg.Done()
 			var err error
-			if s.dbs[ix], err = gorocksdb.OpenDb(option, dbname); err != nil {
+			if DBConfig.ExpiryIndexEnabled {
+				s.dbs[ix], s.expiryIndexes[ix], err = openDbWithExpiryIndex(option, dbname)
+			} else {
+				s.dbs[ix], err = OpenDb(option, dbname)
+			}
+			if err != nil {
 				glog.Exitf("failed to open %s err: %s", dbname, err)
 			}
 			glog.Debugf("%s opened", dbname)
@@ -175,9 +168,20 @@ func (s *ShardingByPrefix) setupShards(dbnamePrefix string, shardMap shard.Map)
 	wg.Wait()
 	glog.Infof("%v opened", s.DbNames)
 
+	if DBConfig.ExpiryIndexEnabled {
+		startExpiryReaper()
+	}
+
 	//LOG Alert in CAL?
 }
 
+func (s *ShardingByPrefix) getExpiryIndex(id RecordID) *expiryIndexT {
+	if len(s.expiryIndexes) == 0 {
+		return nil
+	}
+	return s.expiryIndexes[int(id.GetShardID())%len(s.expiryIndexes)]
+}
+
 func (s *ShardingByPrefix) shutdownShards(shards []shard.ID) {
 
 	ok := 0
@@ -207,6 +211,7 @@ func (s *ShardingByPrefix) shutdown() {
 	if len(s.dbs) == 0 {
 		return
 	}
+	stopExpiryReaper()
 
 	wg := new(sync.WaitGroup)
 	wg.Add(len(s.dbs))
@@ -221,6 +226,10 @@ func (s *ShardingByPrefix) shutdown() {
 
 			glog.Debugf("Closing DB. db index: %d", ix)
 			fastDbFlush(s.dbs[ix])
+			if ix < len(s.expiryIndexes) && s.expiryIndexes[ix] != nil {
+				s.expiryIndexes[ix].close()
+				s.expiryIndexes[ix] = nil
+			}
 			s.dbs[ix].Close()
 			s.dbs[ix] = nil
 			glog.Debugf("DB closed. db index: %d", ix)
@@ -254,56 +263,6 @@ func (s *ShardingByPrefix) getIntProperty(propKey string) uint64 {
 	return valInt
 }
 
-type MicroShardGroupStats struct {
-	start_id   uint8
-	end_id     uint8
-	cnt_keys   uint32
-	cnt_exp    uint32
-	cnt_err    uint32
-	start_time time.Time
-	mshards    string
-	lastgrp    bool
-}
-
-func (m *MicroShardGroupStats) reset(numMicroShards int, numMShardsPerGroup int, curGroupNum int) {
-
-	m.lastgrp = true
-	if numMicroShards > 0 {
-		m.start_id = uint8(curGroupNum * numMShardsPerGroup)
-		m.end_id = uint8(int(m.start_id) + numMShardsPerGroup - 1)
-
-		if numMicroShards-1-int(m.end_id) < numMShardsPerGroup { // last group, may have extras
-			m.end_id = uint8(numMicroShards - 1)
-			m.lastgrp = true
-		} else {
-			m.lastgrp = false
-		}
-
-		m.mshards = fmt.Sprintf(", micro shards (%d-%d) ", m.start_id, m.end_id)
-	}
-	m.cnt_keys = 0
-	m.cnt_exp = 0
-	m.cnt_err = 0
-	m.start_time = time.Now()
-	//glog.Infof("reset group: %d, %d, %d, %d, %d\n", m.start_id, m.end_id, curGroupNum, numMicroShards, numMShardsPerGroup)
-}
-
-func (m *MicroShardGroupStats) logStats(shardId shard.ID, rb *redist.Replicator) bool {
-	elapsed := time.Since(m.start_time)
-	glog.Infof("total %d records forwarded from shard %d%s in %s, excluding expired_cnt=%d, decode_err_cnt=%d",
-		m.cnt_keys, shardId, m.mshards, elapsed, m.cnt_exp, m.cnt_err)
-
-	rediststat := rb.GetSnapshotStats()
-	rediststat.SetMShardId(int32(m.end_id))
-	if m.lastgrp {
-		rediststat.SetStatus(redistst.StatsFinish)
-	} else {
-		rediststat.SetStatus(redistst.StatsInProgress)
-	}
-	return rb.LogStats(m.start_time, true, false)
-
-}
-
 func (s *ShardingByPrefix) replicateSnapshot(shardId shard.ID, rb *redist.Replicator, mshardid int32) bool {
 
 	numDbs := len(s.dbs)
@@ -382,8 +341,8 @@ LOOP:
 			continue LOOP
 		}
 
-		// skip, if expired
-		if rec.IsExpired() {
+		// skip, if expired or purged
+		if rec.IsExpired() || rec.isPurged(iter.Key().Data()) {
 			glog.Verbosef("snapshot record expired, skip. ns=%s, key=%s", ns, util.ToPrintableAndHexString(key))
 			msgroup.cnt_exp++
 			continue LOOP
@@ -426,11 +385,52 @@ LOOP:
 	return true
 }
 
+// scan merges the records of all the micro shards of the shard.
+func (s *ShardingByPrefix) scan(shardId shard.ID, ns []byte, prefix []byte, startAfter []byte, limit int) ([]ScanRecord, error) {
+	dbInst := s.dbs[int(shardId)%len(s.dbs)]
+	if dbInst == nil {
+		return nil, fmt.Errorf("no db for shard %d", shardId)
+	}
+
+	opts := gorocksdb.NewDefaultReadOptions()
+	defer opts.Destroy()
+	snapshot := dbInst.NewSnapshot()
+	defer dbInst.ReleaseSnapshot(snapshot)
+	opts.SetSnapshot(snapshot)
+
+	scanPrefix, seekKey := scanStorageKeys(ns, prefix, startAfter)
+	numCursors := 1
+	if enableMircoShardId {
+		numCursors = s.numMicroShards
+	}
+	offset := storageKeyOffset()
+
+	cursors := make([]scanCursorI, 0, numCursors)
+	for i := 0; i < numCursors; i++ {
+		keyPrefix := s.getPrefixKey(shardId)
+		if enableMircoShardId {
+			keyPrefix = append(keyPrefix, uint8(i))
+		}
+		iterPrefix := append(keyPrefix[:offset:offset], scanPrefix...)
+		iterSeekKey := append(keyPrefix[:offset:offset], seekKey...)
+
+		iter := dbInst.NewIterator(opts)
+		defer iter.Close()
+		cursors = append(cursors, newIterCursor(iter, iterPrefix, offset, iterSeekKey))
+	}
+	return mergeScan(cursors, startAfter, limit), nil
+}
+
 func (s *ShardingByPrefix) duplicate() IDBSharding {
 	dup := &ShardingByPrefix{}
 	dup.dbnamePrefix = s.dbnamePrefix
+	dup.DbNames = make([]string, len(s.DbNames))
+	copy(dup.DbNames, s.DbNames)
+	dup.PrefixBytes = s.PrefixBytes
 	dup.dbs = make([]*gorocksdb.DB, len(s.dbs), len(s.dbs))
 	copy(dup.dbs, s.dbs)
+	dup.expiryIndexes = make([]*expiryIndexT, len(s.expiryIndexes))
+	copy(dup.expiryIndexes, s.expiryIndexes)
 
 	dup.shardFilters = make([]*ShardFilter, len(s.shardFilters), len(s.shardFilters))
 	copy(dup.shardFilters, s.shardFilters)
@@ -522,6 +522,55 @@ func (s *ShardingByPrefix) CompactRangeByShard(shardId shard.ID) error {
 	return err
 }
 
+// Compacts every db online so that the compaction filter drops expired and
+// purged records. onDone is called after each db is compacted.
+func (s *ShardingByPrefix) backupDbs(shards shard.Map) (dbs []backupDbT) {
+	numDbs := len(s.dbs)
+	for i, dbInst := range s.dbs {
+		if dbInst == nil {
+			continue
+		}
+		b := backupDbT{
+			name:    filepath.Base(s.DbNames[i]),
+			walName: fmt.Sprintf("wal%s-%d", s.dbnamePrefix, i),
+			db:      dbInst,
+		}
+		for _, id := range shards.Keys() {
+			if int(id)%numDbs == i {
+				b.shards = append(b.shards, id)
+			}
+		}
+		dbs = append(dbs, b)
+	}
+	return
+}
+
+func (s *ShardingByPrefix) NumDbs() int {
+	return len(s.DbNames)
+}
+
+func (s *ShardingByPrefix) CompactAll(onDone func(i int, total int)) error {
+
+	compactOpts := gorocksdb.NewDefaultCompactOptions()
+	// Allow scheduling of auto compactions.
+	compactOpts.SetExclusiveManual(false)
+
+	for i, dbInst := range s.dbs {
+		if dbInst == nil {
+			continue
+		}
+		glog.Infof("CompactAll started for db %d", i)
+		if err := dbInst.CompactRangeOptions(compactOpts, gorocksdb.Range{}); err != nil {
+			glog.Errorf("CompactAll failed for db %d error=%s", i, err.Error())
+			return err
+		}
+		if onDone != nil {
+			onDone(i, len(s.dbs))
+		}
+	}
+	return nil
+}
+
 func (s *ShardingByPrefix) decodeStorageKey(sskey []byte) ([]byte, []byte, error) {
 	return DecodeRecordKey(sskey)
 }
@@ -0,0 +1,153 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bytes"
+	"math/rand"
+)
+
+const kMaxSkipListLevel = 24
+
+type (
+	skipListNodeT struct {
+		key   []byte
+		value []byte
+		next  []*skipListNodeT
+	}
+
+	// skipListT is the ordered index of the memory engine. It is not safe
+	// for concurrent use.
+	skipListT struct {
+		head  skipListNodeT
+		level int
+		count int
+		bytes int // total size of keys and values
+		rnd   *rand.Rand
+	}
+
+	memEntryT struct {
+		key   []byte
+		value []byte
+	}
+)
+
+func newSkipList() *skipListT {
+	return &skipListT{
+		head:  skipListNodeT{next: make([]*skipListNodeT, kMaxSkipListLevel)},
+		level: 1,
+		rnd:   rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+func (l *skipListT) randomLevel() int {
+	level := 1
+	for level < kMaxSkipListLevel && l.rnd.Intn(4) == 0 {
+		level++
+	}
+	return level
+}
+
+// findGreaterOrEqual returns the first node with key >= key. If prev is not
+// nil, it is set with the last node before key at each level.
+func (l *skipListT) findGreaterOrEqual(key []byte, prev []*skipListNodeT) *skipListNodeT {
+	x := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && bytes.Compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		if prev != nil {
+			prev[i] = x
+		}
+	}
+	return x.next[0]
+}
+
+func (l *skipListT) get(key []byte) (value []byte, found bool) {
+	if x := l.findGreaterOrEqual(key, nil); x != nil && bytes.Equal(x.key, key) {
+		return x.value, true
+	}
+	return nil, false
+}
+
+// put takes the ownership of key and value.
+func (l *skipListT) put(key []byte, value []byte) {
+	var prev [kMaxSkipListLevel]*skipListNodeT
+	x := l.findGreaterOrEqual(key, prev[:])
+	if x != nil && bytes.Equal(x.key, key) {
+		l.bytes += len(value) - len(x.value)
+		x.value = value
+		return
+	}
+
+	level := l.randomLevel()
+	for i := l.level; i < level; i++ {
+		prev[i] = &l.head
+	}
+	if level > l.level {
+		l.level = level
+	}
+	x = &skipListNodeT{key: key, value: value, next: make([]*skipListNodeT, level)}
+	for i := 0; i < level; i++ {
+		x.next[i] = prev[i].next[i]
+		prev[i].next[i] = x
+	}
+	l.count++
+	l.bytes += len(key) + len(value)
+}
+
+func (l *skipListT) delete(key []byte) bool {
+	var prev [kMaxSkipListLevel]*skipListNodeT
+	x := l.findGreaterOrEqual(key, prev[:])
+	if x == nil || !bytes.Equal(x.key, key) {
+		return false
+	}
+	for i := range x.next {
+		prev[i].next[i] = x.next[i]
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.count--
+	l.bytes -= len(x.key) + len(x.value)
+	return true
+}
+
+// seek returns the first node with key >= key.
+func (l *skipListT) seek(key []byte) *skipListNodeT {
+	return l.findGreaterOrEqual(key, nil)
+}
+
+func (l *skipListT) first() *skipListNodeT {
+	return l.head.next[0]
+}
+
+func (x *skipListNodeT) nextNode() *skipListNodeT {
+	return x.next[0]
+}
+
+// entries returns the records in key order, without copying them.
+func (l *skipListT) entries() []memEntryT {
+	entries := make([]memEntryT, 0, l.count)
+	for x := l.first(); x != nil; x = x.nextNode() {
+		entries = append(entries, memEntryT{key: x.key, value: x.value})
+	}
+	return entries
+}
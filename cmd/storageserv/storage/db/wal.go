@@ -0,0 +1,213 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package db
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"juno/third_party/forked/golang/glog"
+)
+
+// Write ahead log of the memory engine
+//
+//	Offset | Field                     | Size
+//	-------+---------------------------+---------
+//	     0 | crc32 of the rest         | 4 bytes
+//	     4 | op                        | 1 byte
+//	     5 | key length                | 4 bytes
+//	     9 | value length              | 4 bytes
+//	    13 | key                       | ...
+//	       | value                     | ...
+const (
+	kWalOpPut    byte = 1
+	kWalOpDelete byte = 2
+
+	kSzWalHeader = 13
+
+	kMaxWalKeySize   = 1 << 16
+	kMaxWalValueSize = 1 << 28
+)
+
+var errWalCorrupted = errors.New("corrupted wal entry")
+
+type walT struct {
+	path string
+	file *os.File
+	size int64
+	sync bool
+	buf  []byte
+}
+
+// openWal replays the log of path, if any, calling fn for each entry, and
+// opens it for appending. A corrupted or truncated tail, e.g. after a crash,
+// is discarded.
+func openWal(path string, sync bool, fn func(op byte, key []byte, value []byte)) (w *walT, err error) {
+	var file *os.File
+	if file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return
+	}
+	var size int64
+	size, err = replayWal(file, fn)
+	if err != nil {
+		glog.Warningf("wal %s: %s at offset %d, truncated", path, err, size)
+		if err = file.Truncate(size); err != nil {
+			file.Close()
+			return
+		}
+	}
+	if _, err = file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return
+	}
+	w = &walT{path: path, file: file, size: size, sync: sync}
+	return
+}
+
+// replayWal returns the size of the valid entries, and the error stopping the
+// replay before the end of the file, if any.
+func replayWal(file *os.File, fn func(op byte, key []byte, value []byte)) (size int64, err error) {
+	r := bufio.NewReaderSize(file, 1<<20)
+	var header [kSzWalHeader]byte
+	for {
+		if _, err = io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		szKey := binary.BigEndian.Uint32(header[5:9])
+		szValue := binary.BigEndian.Uint32(header[9:13])
+		if szKey > kMaxWalKeySize || szValue > kMaxWalValueSize {
+			err = errWalCorrupted
+			return
+		}
+		data := make([]byte, int(szKey)+int(szValue))
+		if _, err = io.ReadFull(r, data); err != nil {
+			return
+		}
+		crc := crc32.NewIEEE()
+		crc.Write(header[4:])
+		crc.Write(data)
+		if crc.Sum32() != binary.BigEndian.Uint32(header[0:4]) {
+			err = errWalCorrupted
+			return
+		}
+		fn(header[4], data[:szKey:szKey], data[szKey:])
+		size += int64(kSzWalHeader + len(data))
+	}
+}
+
+func (w *walT) append(op byte, key []byte, value []byte) error {
+	if err := w.write(w.file, op, key, value); err != nil {
+		return err
+	}
+	if w.sync {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+func (w *walT) write(out io.Writer, op byte, key []byte, value []byte) error {
+	sz := kSzWalHeader + len(key) + len(value)
+	if cap(w.buf) < sz {
+		w.buf = make([]byte, sz)
+	}
+	buf := w.buf[:sz]
+	buf[4] = op
+	binary.BigEndian.PutUint32(buf[5:9], uint32(len(key)))
+	binary.BigEndian.PutUint32(buf[9:13], uint32(len(value)))
+	copy(buf[kSzWalHeader:], key)
+	copy(buf[kSzWalHeader+len(key):], value)
+	binary.BigEndian.PutUint32(buf[0:4], crc32.ChecksumIEEE(buf[4:]))
+
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+	w.size += int64(sz)
+	return nil
+}
+
+// createWal writes the put entries of the records to a new log at path,
+// replacing the existing one, if any.
+func createWal(path string, entries []memEntryT, sync bool) (w *walT, err error) {
+	if w, err = newTmpWal(path, entries); err != nil {
+		return
+	}
+	if err = w.install(); err != nil {
+		w.discard()
+		return nil, err
+	}
+	w.sync = sync
+	return
+}
+
+// newTmpWal writes the put entries of the records to a temporary log, for
+// install to rename it to path.
+func newTmpWal(path string, entries []memEntryT) (tmp *walT, err error) {
+	tmp = &walT{path: path}
+	if tmp.file, err = os.Create(path + ".tmp"); err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriterSize(tmp.file, 1<<20)
+	for _, e := range entries {
+		if err = tmp.write(bw, kWalOpPut, e.key, e.value); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		tmp.discard()
+		return nil, err
+	}
+	return
+}
+
+// appendFrom appends the entries of w from offset, the ones appended after
+// the snapshot the temporary log was written from.
+func (w *walT) appendFrom(from *walT, offset int64) error {
+	n, err := io.Copy(w.file, io.NewSectionReader(from.file, offset, from.size-offset))
+	w.size += n
+	return err
+}
+
+// install renames the temporary log to its path.
+func (w *walT) install() error {
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(w.path+".tmp", w.path)
+}
+
+// discard removes the temporary log.
+func (w *walT) discard() {
+	w.file.Close()
+	os.Remove(w.path + ".tmp")
+}
+
+func (w *walT) close() error {
+	return w.file.Close()
+}
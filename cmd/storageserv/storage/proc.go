@@ -26,6 +26,8 @@ import (
 	"runtime"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"juno/third_party/forked/golang/glog"
 
 	"juno/cmd/storageserv/config"
@@ -66,6 +68,7 @@ type (
 		timer      *util.TimerWrapper
 		chReq      chan *reqProcCtxT
 		encodeBuf  bytes.Buffer
+		span       trace.Span
 	}
 	ReqProcCtxPool util.ChanPool
 )
@@ -122,6 +125,10 @@ func (p *reqProcCtxT) OnComplete() {
 	}
 
 	otel.RecordOperation(opcode.String(), p.response.GetOpStatus(), int64(rhtus))
+	if p.span != nil {
+		otel.EndSpan(p.span, p.response.GetOpStatus())
+		p.span = nil
+	}
 
 	if p.cacheable {
 		if p.prepareCtx != nil {
@@ -183,6 +190,7 @@ func (p *reqProcCtxT) resetProcContext() {
 	p.timer.Stop()
 	p.chReq = nil
 	p.prepareCtx = nil
+	p.span = nil
 }
 
 func (p *reqProcCtxT) attach(ctx io.IRequestContext) bool {
@@ -228,9 +236,9 @@ func (p *reqProcCtxT) Process(reqCtx io.IRequestContext) {
 		uint32(config.ServerConfig().ClusterInfo.NumShards), config.ServerConfig().NumMicroShards)
 	p.microShardId = microShardId // used in prefix only if enabled
 
-	// shard id validation
+	// shard id validation. The key of Scan is a key prefix.
 	if computedShardId != uint16(p.shardId) && config.ServerConfig().ShardIdValidation &&
-		req.GetOpCode() != proto.OpCodeClone {
+		req.GetOpCode() != proto.OpCodeClone && req.GetOpCode() != proto.OpCodeScan {
 
 		glog.Errorf("Bad Param: shard id does not match key: %d, %d", uint16(p.shardId), computedShardId)
 		if cal.IsEnabled() {
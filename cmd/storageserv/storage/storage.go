@@ -27,6 +27,8 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"juno/third_party/forked/golang/glog"
 
 	"juno/cmd/dbscanserv/patch"
@@ -39,6 +41,7 @@ import (
 	"juno/pkg/etcd"
 	"juno/pkg/logging"
 	"juno/pkg/logging/cal"
+	"juno/pkg/logging/otel"
 	"juno/pkg/proto"
 	"juno/pkg/shard"
 	"juno/pkg/util"
@@ -131,7 +134,8 @@ func validate(r *proto.OperationalMessage) bool {
 		return false
 	}
 
-	if r.GetKey() == nil || len(r.GetKey()) <= 0 {
+	// key of Scan is an optional key prefix
+	if (r.GetKey() == nil || len(r.GetKey()) <= 0) && opcode != proto.OpCodeScan {
 		glog.Error("Bad Param: Key is empty")
 		if cal.IsEnabled() {
 			cal.Event(kCalMsgTypeReqProc, "BadParam_no_key", cal.StatusSuccess, nil)
@@ -195,6 +199,11 @@ func process(p *reqProcCtxT) {
 	}
 
 	opcode := req.GetOpCode()
+	if otel.IsTraceEnabled() {
+		p.span = otel.StartSpan("ss."+opcode.String(), req.GetTraceContext(), trace.SpanKindServer,
+			otel.AttrOpCode.String(opcode.String()),
+			otel.AttrRequestID.String(req.GetRequestIDString()))
+	}
 	if !opcode.IsForStorage() {
 		p.replyWithErrorOpStatus(proto.OpStatusServiceDenied)
 		return
@@ -222,6 +231,8 @@ func process(p *reqProcCtxT) {
 		clone(p)
 	case proto.OpCodeMarkDelete:
 		markDelete(p)
+	case proto.OpCodeScan:
+		scan(p)
 	default:
 		// should never come here, but handle it anyway
 		glog.Errorf("bad opcode: %s rid=%s", opcode.String(), req.GetRequestIDString())
@@ -284,6 +295,52 @@ func read(p *reqProcCtxT) {
 	return
 }
 
+// Scan: one phase operation, returning a page of the records of the namespace
+// in the shard
+func scan(p *reqProcCtxT) {
+	request := &p.request
+	scanReq, err := request.GetScanRequest()
+	if err != nil {
+		glog.Errorf("Bad Param: %s rid=%s", err, request.GetRequestIDString())
+		p.replyWithErrorOpStatus(proto.OpStatusBadParam)
+		return
+	}
+
+	limit := scanReq.GetLimit()
+	recs, err := db.GetDB().Scan(p.shardId, request.GetNamespace(), request.GetKey(), scanReq.Token, limit)
+	if err != nil {
+		p.replyWithErrorOpStatus(proto.OpStatusSSError)
+		return
+	}
+
+	page := proto.ScanPage{Entries: make([]proto.ScanEntry, 0, len(recs))}
+	for i := range recs {
+		rec := &recs[i]
+		e := proto.ScanEntry{
+			Key:          rec.Key,
+			Version:      rec.Version,
+			CreationTime: rec.CreationTime,
+			TimeToLive:   util.GetTimeToLive(rec.ExpirationTime),
+			MarkedDelete: rec.IsMarkedDelete(),
+		}
+		if !e.MarkedDelete {
+			e.Payload = rec.Payload
+		}
+		page.Entries = append(page.Entries, e)
+	}
+	if len(recs) == limit {
+		page.Token = recs[limit-1].Key
+	}
+
+	p.initResponseWithStatus(proto.OpStatusNoError)
+	if err = p.response.SetScanPage(&page); err != nil {
+		glog.Errorf("Scan: %s rid=%s", err, request.GetRequestIDString())
+		p.replyWithErrorOpStatus(proto.OpStatusSSError)
+		return
+	}
+	p.reply()
+}
+
 // Repair: one phase operation
 func repair(p *reqProcCtxT) {
 	request := &p.request
@@ -635,6 +692,9 @@ func prepareSet(p *reqProcCtxT) (st proto.OpStatus) {
 			st = proto.OpStatusAlreadyFulfilled
 			p.initResponse(st, ///TODO to change
 				rec.Version, rec.ExpirationTime, rec.CreationTime)
+			if p.request.IsUDFNameSet() {
+				p.response.SetPayload(&rec.Payload)
+			}
 			p.reply()
 			return
 		}
@@ -651,6 +711,11 @@ func prepareSet(p *reqProcCtxT) (st proto.OpStatus) {
 			p.initResponse(proto.OpStatusNoError, rec.Version, rec.ExpirationTime, rec.CreationTime)
 			p.response.SetOriginatorRequestID(rec.OriginatorRequestId)
 			p.response.SetLastModificationTime(rec.LastModificationTime)
+			if p.request.IsUDFNameSet() {
+				// UDFSet. The proxy applies the UDF to the current value while
+				// the record is locked, and sends the result with the commit.
+				p.response.SetPayload(&rec.Payload)
+			}
 			p.reply()
 			return
 		}
@@ -1058,7 +1123,12 @@ func doCommitAndReleaselock(p *reqProcCtxT, prepare *reqProcCtxT) {
 	rec.CreationTime = req.GetCreationTime()
 	rec.Version = req.GetVersion()
 	rec.LastModificationTime = req.GetLastModificationTime()
-	rec.Payload.Set(prepare.request.GetPayload())
+	if prepare.request.IsUDFNameSet() {
+		// UDFSet. The value is computed by the proxy and sent with the commit.
+		rec.Payload.Set(req.GetPayload())
+	} else {
+		rec.Payload.Set(prepare.request.GetPayload())
+	}
 	//rec.ExpirationTime = d.request.GetExpirationTime()
 	//rec.RequestId = d.request.GetRequestID()
 	if req.IsOriginatorSet() {
@@ -36,6 +36,7 @@ type IResponseContext interface {
 type RequestContext struct {
 	request    *proto.OperationalMessage
 	chResponse chan IResponseContext
+	onDone     func(err error)
 }
 
 type ResponseContext struct {
@@ -112,6 +113,9 @@ func (r *RequestContext) Reply(response *proto.OperationalMessage) {
 		glog.Fatal("nil response")
 	}
 	response.SetOpaque(r.request.GetOpaque())
+	if r.onDone != nil {
+		r.onDone(nil)
+	}
 	r.chResponse <- &ResponseContext{response}
 }
 
@@ -120,5 +124,8 @@ func (r *RequestContext) ReplyError(err error) {
 	if r.request == nil {
 		glog.Fatal("nil request") ///TODO
 	}
+	if r.onDone != nil {
+		r.onDone(err)
+	}
 	r.chResponse <- &ErrResponseContext{r.request.GetOpaque(), err}
 }
@@ -20,6 +20,7 @@
 package cli
 
 import (
+	"fmt"
 	"io"
 	"net"
 	"os"
@@ -39,6 +40,8 @@ type (
 		conn             net.Conn
 		chReaderResponse <-chan *ReaderResponse
 		beingRecycle     bool
+		// negotiated with VerHandshake
+		features proto.Feature
 	}
 )
 
@@ -143,17 +146,53 @@ func StartRequestProcessor(
 	chDone <-chan bool,
 	chRequest <-chan *RequestContext) (chProcessorDone <-chan bool) {
 
+	return startRequestProcessor(server, sourceName, connectTimeout, requestTimeout, connRecycleTimeout, 0, chDone, chRequest)
+}
+
+func startRequestProcessor(
+	server junoio.ServiceEndpoint,
+	sourceName string,
+	connectTimeout time.Duration,
+	requestTimeout time.Duration,
+	connRecycleTimeout time.Duration,
+	features proto.Feature,
+	chDone <-chan bool,
+	chRequest <-chan *RequestContext) (chProcessorDone <-chan bool) {
+
 	ch := make(chan bool)
-	go doRequestProcess(server, sourceName, connectTimeout, requestTimeout, connRecycleTimeout, chDone, ch, chRequest)
+	go doRequestProcess(server, sourceName, connectTimeout, requestTimeout, connRecycleTimeout, features, chDone, ch, chRequest)
 	return ch
 }
 
+// handshake negotiates the protocol features to use on conn. Servers not
+// supporting protocol version 2 reply without any feature.
+func handshake(conn net.Conn, features proto.Feature, timeout time.Duration) (negotiated proto.Feature, err error) {
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if err = proto.NewEncoder(conn).Encode(proto.NewVerHandshakeRequest(features)); err != nil {
+		return
+	}
+	var resp proto.OperationalMessage
+	if err = proto.NewDecoder(conn).Decode(&resp); err != nil {
+		return
+	}
+	if resp.GetOpCode() != proto.OpCodeVerHandshake || !resp.IsResponse() {
+		err = fmt.Errorf("unexpected handshake response %s", resp.GetOpCode())
+		return
+	}
+	negotiated = resp.GetHandshakeFeatures() & features
+	glog.Debugf("negotiated features with %s: %s", conn.RemoteAddr(), negotiated)
+	return
+}
+
 func doRequestProcess(
 	server junoio.ServiceEndpoint,
 	sourceName string,
 	connectTimeout time.Duration,
 	requestTimeout time.Duration,
 	connRecycleTimeout time.Duration,
+	features proto.Feature,
 	chDone <-chan bool,
 	chDoneNotify chan<- bool,
 	chRequest <-chan *RequestContext) {
@@ -174,6 +213,12 @@ func doRequestProcess(
 		if err != nil {
 			return
 		}
+		if features != 0 {
+			if active.features, err = handshake(conn, features, connectTimeout); err != nil {
+				conn.Close()
+				return
+			}
+		}
 		active.conn = conn
 		active.tracker = newPendingTracker(requestTimeout)
 		active.chReaderResponse = startResponseReader(conn)
@@ -253,6 +298,7 @@ loop:
 					return
 				}
 				req.SetSource(saddr.IP, uint16(saddr.Port), []byte(sourceName))
+				req.EnableFeatures(active.features)
 				sequence++
 				var raw proto.RawMessage
 				if err = req.Encode(&raw); err != nil {
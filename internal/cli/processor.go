@@ -20,9 +20,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"juno/third_party/forked/golang/glog"
@@ -57,11 +59,31 @@ type Processor struct {
 	connectTimeout     time.Duration
 	requestTimeout     time.Duration
 	connRecycleTimeout time.Duration
+	features           proto.Feature
 
-	chDone     chan bool
-	chProcDone <-chan bool
-	chRequest  chan *RequestContext
-	startOnce  sync.Once
+	chDone    chan bool
+	conns     []*connProcT
+	next      uint32
+	startOnce sync.Once
+}
+
+// connProcT is the request processor of one connection. Each has its own
+// request channel, and its own tracker mapping the opaque of the responses to
+// the pending requests.
+type connProcT struct {
+	chRequest   chan *RequestContext
+	chProcDone  <-chan bool
+	outstanding int32
+	numRequests uint64
+	numErrors   uint64
+}
+
+// ConnStats is the statistics of one connection of a Processor.
+type ConnStats struct {
+	// Number of requests sent, or waiting to be sent, without response yet
+	Outstanding int32
+	NumRequests uint64
+	NumErrors   uint64
 }
 
 func NewProcessor(
@@ -71,6 +93,23 @@ func NewProcessor(
 	requestTimeout time.Duration,
 	connRecycleTimeout time.Duration) *Processor {
 
+	return NewProcessorWithConnections(server, sourceName, connectTimeout, requestTimeout, connRecycleTimeout, 1)
+}
+
+// NewProcessorWithConnections returns a Processor spreading the requests across
+// numConnections connections to server. Each request goes to the connection
+// with the least outstanding requests.
+func NewProcessorWithConnections(
+	server io.ServiceEndpoint,
+	sourceName string,
+	connectTimeout time.Duration,
+	requestTimeout time.Duration,
+	connRecycleTimeout time.Duration,
+	numConnections int) *Processor {
+
+	if numConnections < 1 {
+		numConnections = 1
+	}
 	c := &Processor{
 		server:             server,
 		sourceName:         sourceName,
@@ -78,31 +117,105 @@ func NewProcessor(
 		requestTimeout:     requestTimeout,
 		connRecycleTimeout: connRecycleTimeout,
 		chDone:             make(chan bool),
-		chRequest:          make(chan *RequestContext, kMaxRequestChanBufferSize),
+		conns:              make([]*connProcT, numConnections),
+	}
+	for i := range c.conns {
+		c.conns[i] = &connProcT{
+			chRequest: make(chan *RequestContext, kMaxRequestChanBufferSize),
+		}
 	}
 	return c
 }
 
+// SetFeatures sets the protocol features to negotiate on each connection. It
+// must be called before Start.
+func (c *Processor) SetFeatures(features proto.Feature) {
+	c.features = features
+}
+
 func (c *Processor) Start() {
 	c.startOnce.Do(func() {
-		c.chProcDone = StartRequestProcessor(
-			c.server, c.sourceName, c.connectTimeout, c.requestTimeout, c.connRecycleTimeout, c.chDone, c.chRequest)
+		for _, conn := range c.conns {
+			conn.chProcDone = startRequestProcessor(
+				c.server, c.sourceName, c.connectTimeout, c.requestTimeout, c.connRecycleTimeout, c.features, c.chDone, conn.chRequest)
+		}
 	})
 }
 
 ///TODO revisit
 func (c *Processor) Close() {
 	close(c.chDone)
-	<-c.chProcDone
+	for _, conn := range c.conns {
+		<-conn.chProcDone
+	}
+}
+
+// GetConnStats returns the statistics of each connection.
+func (c *Processor) GetConnStats() []ConnStats {
+	stats := make([]ConnStats, len(c.conns))
+	for i, conn := range c.conns {
+		stats[i] = ConnStats{
+			Outstanding: atomic.LoadInt32(&conn.outstanding),
+			NumRequests: atomic.LoadUint64(&conn.numRequests),
+			NumErrors:   atomic.LoadUint64(&conn.numErrors),
+		}
+	}
+	return stats
+}
+
+// pick returns the connection with the least outstanding requests. Ties are
+// broken in round-robin.
+func (c *Processor) pick() *connProcT {
+	num := len(c.conns)
+	if num == 1 {
+		return c.conns[0]
+	}
+	start := int(atomic.AddUint32(&c.next, 1) % uint32(num))
+	picked := c.conns[start]
+	for i := 1; i < num && atomic.LoadInt32(&picked.outstanding) != 0; i++ {
+		conn := c.conns[(start+i)%num]
+		if atomic.LoadInt32(&conn.outstanding) < atomic.LoadInt32(&picked.outstanding) {
+			picked = conn
+		}
+	}
+	return picked
+}
+
+func (p *connProcT) newRequestContext(m *proto.OperationalMessage, chResponse chan IResponseContext) *RequestContext {
+	r := NewRequestContext(m, chResponse)
+	r.onDone = func(err error) {
+		atomic.AddInt32(&p.outstanding, -1)
+		if err != nil {
+			atomic.AddUint64(&p.numErrors, 1)
+		}
+	}
+	return r
+}
+
+// reserve counts a request to be sent as outstanding. It has to be done before
+// the request is sent, as the response may be received before the send returns.
+func (p *connProcT) reserve() {
+	atomic.AddInt32(&p.outstanding, 1)
+}
+
+func (p *connProcT) onSendDone(sent bool) {
+	if sent {
+		atomic.AddUint64(&p.numRequests, 1)
+	} else {
+		atomic.AddInt32(&p.outstanding, -1)
+	}
 }
 
 func (c *Processor) sendWithResponseChannel(chResponse chan IResponseContext, m *proto.OperationalMessage) (ok bool) {
+	conn := c.pick()
+	conn.reserve()
 	select {
-	case c.chRequest <- NewRequestContext(m, chResponse):
+	case conn.chRequest <- conn.newRequestContext(m, chResponse):
 		ok = true
 	default:
 		ok = false
 	}
+	conn.onSendDone(ok)
 	if glog.LOG_VERBOSE {
 		opcode := m.GetOpCode()
 		buf := logging.NewKVBufferForLog()
@@ -119,31 +232,95 @@ func (c *Processor) sendWithResponseChannel(chResponse chan IResponseContext, m
 	return
 }
 
-func (c *Processor) send(request *proto.OperationalMessage) (chResponse <-chan IResponseContext, ok bool) {
-	ch := make(chan IResponseContext)
-	chResponse = ch
-	ok = c.sendWithResponseChannel(ch, request)
+// sendWithContext sends the request to the request processor. If ctx can be
+// cancelled, it waits for the request channel to be available until ctx is
+// done. Otherwise, it fails right away if the request channel is full.
+func (c *Processor) sendWithContext(ctx context.Context, chResponse chan IResponseContext, m *proto.OperationalMessage) (err error) {
+	if ctx.Done() == nil {
+		if !c.sendWithResponseChannel(chResponse, m) {
+			err = &IOError{fmt.Errorf("fail to send request")}
+		}
+		return
+	}
+	conn := c.pick()
+	conn.reserve()
+	select {
+	case conn.chRequest <- conn.newRequestContext(m, chResponse):
+		glog.Verbosef("proc <- %s rid=%s", m.GetOpCode().String(), m.GetRequestIDString())
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	conn.onSendDone(err == nil)
 	return
 }
 
 func (c *Processor) ProcessRequest(request *proto.OperationalMessage) (resp *proto.OperationalMessage, err error) {
+	return c.ProcessRequestWithContext(context.Background(), request)
+}
+
+// ProcessRequestWithContext returns ctx.Err() as soon as ctx is done, whether the
+// request is waiting to be sent or waiting for its response. A response received
+// after that is dropped.
+func (c *Processor) ProcessRequestWithContext(ctx context.Context, request *proto.OperationalMessage) (resp *proto.OperationalMessage, err error) {
 	timeStart := time.Now()
 
 	glog.Verbosef("process request rid=%s", request.GetRequestIDString())
-	if ch, sent := c.send(request); sent {
-		if r, ok := <-ch; ok {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	// buffered, so that the request processor won't be blocked if the caller
+	// has given up on the response
+	ch := make(chan IResponseContext, 1)
+	if err = c.sendWithContext(ctx, ch, request); err == nil {
+		resp, err = receiveResponse(ctx, ch)
+	}
+	c.logRequest(request, resp, err, timeStart)
+	return
+}
+
+// ProcessRequestAsync sends the request to the request processor without waiting
+// for the response. onResponse is called exactly once, either in the calling
+// goroutine if the request cannot be sent, or in a separate goroutine once the
+// response or an error has been received.
+func (c *Processor) ProcessRequestAsync(request *proto.OperationalMessage, onResponse func(resp *proto.OperationalMessage, err error)) {
+	timeStart := time.Now()
+
+	glog.Verbosef("process request async rid=%s", request.GetRequestIDString())
+	// buffered, so that the request processor won't be blocked by a slow callback
+	ch := make(chan IResponseContext, 1)
+	if !c.sendWithResponseChannel(ch, request) {
+		err := &IOError{fmt.Errorf("fail to send request")}
+		c.logRequest(request, nil, err, timeStart)
+		onResponse(nil, err)
+		return
+	}
+	go func() {
+		resp, err := receiveResponse(context.Background(), ch)
+		c.logRequest(request, resp, err, timeStart)
+		onResponse(resp, err)
+	}()
+}
+
+func receiveResponse(ctx context.Context, ch <-chan IResponseContext) (resp *proto.OperationalMessage, err error) {
+	select {
+	case r, ok := <-ch:
+		if ok {
 			resp = r.GetResponse()
 			err = r.GetError()
 		} else {
-			resp = nil
 			err = fmt.Errorf("response channel closed by request processor")
 		}
-	} else {
-		err = fmt.Errorf("fail to send request")
-	}
-	if err != nil {
-		err = &IOError{err}
+		if err != nil {
+			resp = nil
+			err = &IOError{err}
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
 	}
+	return
+}
+
+func (c *Processor) logRequest(request *proto.OperationalMessage, resp *proto.OperationalMessage, err error, timeStart time.Time) {
 	if cal.IsEnabled() {
 		var txnType string
 		if c.server.SSLEnabled {
@@ -165,46 +342,83 @@ func (c *Processor) ProcessRequest(request *proto.OperationalMessage) (resp *pro
 			cal.AtomicTransaction(txnType, request.GetOpCode().String(), cal.StatusError, rht, []byte(err.Error())) ///TODO to change: data to cal
 		}
 	}
+}
 
+func (c *Processor) ProcessBatchRequests(requests []*proto.OperationalMessage) (responses []*proto.OperationalMessage, err error) {
+	responses, _, err = c.ProcessBatch(requests)
 	return
 }
 
-func (c *Processor) ProcessBatchRequests(requests []*proto.OperationalMessage) (responses []*proto.OperationalMessage, err error) {
+// ProcessBatch pipelines the requests to the request processor and waits for all
+// of them to complete. For the i-th request, either responses[i] or errs[i] is
+// set. err is only returned if the batch could not be processed at all.
+func (c *Processor) ProcessBatch(requests []*proto.OperationalMessage) (responses []*proto.OperationalMessage, errs []error, err error) {
+	return c.ProcessBatchWithContext(context.Background(), requests)
+}
+
+// ProcessBatchWithContext is ProcessBatch that stops waiting once ctx is done.
+// The requests without response by then get ctx.Err() as their error.
+func (c *Processor) ProcessBatchWithContext(ctx context.Context, requests []*proto.OperationalMessage) (responses []*proto.OperationalMessage, errs []error, err error) {
 	numRequests := len(requests)
 	if numRequests == 0 {
 		err = fmt.Errorf("zero requests passed in")
 		return
 	}
-	chResponse := make(chan IResponseContext)
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	// buffered, so that the request processor won't be blocked if ctx is done
+	// before all the responses are received
+	chResponse := make(chan IResponseContext, numRequests)
 
 	responses = make([]*proto.OperationalMessage, numRequests, numRequests)
+	errs = make([]error, numRequests, numRequests)
 	for i := 0; i < numRequests; i++ {
 		requests[i].SetOpaque(uint32(i))
 	}
 	numSent := 0
 	numReceived := 0
-	chWrite := c.chRequest
 
-	ctx := NewRequestContext(requests[numSent], chResponse)
+	// each request goes to the least loaded connection at the time it is sent
+	conn := c.pick()
+	conn.reserve()
+	chWrite := conn.chRequest
+	reqCtx := conn.newRequestContext(requests[numSent], chResponse)
 	chTicker := time.Tick(20 * time.Second)
 	for numSent < numRequests || numSent != numReceived {
 		select {
-		case chWrite <- ctx:
+		case chWrite <- reqCtx:
+			conn.onSendDone(true)
 			numSent++
 			if numSent >= numRequests {
 				chWrite = nil
-				ctx = nil
+				reqCtx = nil
 			} else {
-				ctx = NewRequestContext(requests[numSent], chResponse)
+				conn = c.pick()
+				conn.reserve()
+				chWrite = conn.chRequest
+				reqCtx = conn.newRequestContext(requests[numSent], chResponse)
 			}
 		case r := <-chResponse:
 			if r.GetError() == nil {
 				responses[r.GetOpaque()] = r.GetResponse()
 			} else {
 				glog.Errorln(r.GetError())
+				errs[r.GetOpaque()] = &IOError{r.GetError()}
 			}
 			numReceived++
 
+		case <-ctx.Done():
+			if reqCtx != nil {
+				conn.onSendDone(false)
+			}
+			for i := 0; i < numRequests; i++ {
+				if responses[i] == nil && errs[i] == nil {
+					errs[i] = ctx.Err()
+				}
+			}
+			return
+
 		///TODO timeout .. double guarantee
 		case <-chTicker:
 			glog.Debugf("numSent = %d		numReceived = %d\n", numSent, numReceived)
@@ -71,6 +71,12 @@ func (r *RecordInfo) SetFromOpMsg(m *proto.OperationalMessage) {
 	}
 }
 
+func (r *RecordInfo) SetFromScanEntry(e *proto.ScanEntry) {
+	r.version = e.Version
+	r.creationTime = e.CreationTime
+	r.timeToLive = e.TimeToLive
+}
+
 func (r *RecordInfo) IsSameOriginator(ctx *RecordInfo) bool {
 	if ctx != nil {
 		return r.originatorId.Equal(ctx.originatorId)
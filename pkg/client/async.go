@@ -0,0 +1,262 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/internal/cli"
+	"juno/pkg/proto"
+)
+
+// ResultCallback is invoked once the result of an asynchronous operation is
+// available. value is only set for Get and UDFGet, and context is nil for
+// Destroy.
+type ResultCallback func(value []byte, context IContext, err error)
+
+// IResult is the handle of an asynchronous operation.
+type IResult interface {
+	// Get blocks until the operation completes.
+	Get() (value []byte, context IContext, err error)
+	// GetWithTimeout blocks until the operation completes or the timeout
+	// elapses, in which case ErrResultTimeout is returned. The operation itself
+	// is not cancelled, and the result can still be retrieved later.
+	GetWithTimeout(timeout time.Duration) (value []byte, context IContext, err error)
+	// Poll returns true if the operation has completed.
+	Poll() bool
+	// OnComplete registers a callback. It is invoked in the calling goroutine if
+	// the operation has already completed.
+	OnComplete(callback ResultCallback)
+}
+
+type IAsyncClient interface {
+	Create(key []byte, value []byte, opts ...IOption) IResult
+	Get(key []byte, opts ...IOption) IResult
+	Update(key []byte, value []byte, opts ...IOption) IResult
+	Set(key []byte, value []byte, opts ...IOption) IResult
+	Destroy(key []byte, opts ...IOption) IResult
+	UDFGet(key []byte, fname []byte, params []byte, opts ...IOption) IResult
+	UDFSet(key []byte, fname []byte, params []byte, opts ...IOption) IResult
+}
+
+type asyncClientImplT struct {
+	client *clientImplT
+}
+
+type resultT struct {
+	mtx       sync.Mutex
+	chDone    chan struct{}
+	value     []byte
+	context   IContext
+	err       error
+	callbacks []ResultCallback
+}
+
+func NewAsync(conf Config) (IAsyncClient, error) {
+	c, err := New(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &asyncClientImplT{client: c.(*clientImplT)}, nil
+}
+
+func NewAsyncClient(server string, ns string, app string) (IAsyncClient, error) {
+	c, err := NewClient(server, ns, app)
+	if err != nil {
+		return nil, err
+	}
+	return &asyncClientImplT{client: c.(*clientImplT)}, nil
+}
+
+func newResult() *resultT {
+	return &resultT{chDone: make(chan struct{})}
+}
+
+// failedResult returns a result completed with err, for requests failing
+// before being sent.
+func failedResult(err error) *resultT {
+	r := newResult()
+	r.complete(nil, nil, err)
+	return r
+}
+
+func (r *resultT) complete(value []byte, context IContext, err error) {
+	r.mtx.Lock()
+	r.value = value
+	r.context = context
+	r.err = err
+	callbacks := r.callbacks
+	r.callbacks = nil
+	close(r.chDone)
+	r.mtx.Unlock()
+
+	for _, cb := range callbacks {
+		cb(value, context, err)
+	}
+}
+
+func (r *resultT) Get() (value []byte, context IContext, err error) {
+	<-r.chDone
+	return r.value, r.context, r.err
+}
+
+func (r *resultT) GetWithTimeout(timeout time.Duration) (value []byte, context IContext, err error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-r.chDone:
+		return r.value, r.context, r.err
+	case <-timer.C:
+		err = ErrResultTimeout
+	}
+	return
+}
+
+func (r *resultT) Poll() bool {
+	select {
+	case <-r.chDone:
+		return true
+	default:
+	}
+	return false
+}
+
+func (r *resultT) OnComplete(callback ResultCallback) {
+	if callback == nil {
+		return
+	}
+	r.mtx.Lock()
+	if !r.Poll() {
+		r.callbacks = append(r.callbacks, callback)
+		r.mtx.Unlock()
+		return
+	}
+	r.mtx.Unlock()
+	callback(r.value, r.context, r.err)
+}
+
+// process sends the request and completes the returned result once the
+// response is received. withValue tells whether the response payload should be
+// returned as the value, and withContext whether record info is returned.
+func (c *asyncClientImplT) process(request *proto.OperationalMessage, options *optionData, withValue bool, withContext bool) IResult {
+	result := newResult()
+	retrier := newRetrier(c.client.getRetryPolicy(options), request)
+
+	var onResponse func(resp *proto.OperationalMessage, err error)
+	onResponse = func(resp *proto.OperationalMessage, err error) {
+		var value []byte
+		var context IContext
+		var recInfo *cli.RecordInfo
+		if withContext {
+			recInfo = &cli.RecordInfo{}
+			context = recInfo
+		}
+		if err == nil {
+			err = checkResponse(request, resp, recInfo)
+		}
+		if err != nil {
+			glog.Debug(err)
+			if backoff, ok := retrier.backoff(err); ok {
+				request.SetNewRequestID()
+				glog.Debugf("retry %s rid=%s attempt=%d", request.GetOpCode(), request.GetRequestIDString(), retrier.attempts)
+				time.AfterFunc(backoff, func() {
+					c.client.processor.ProcessRequestAsync(request, onResponse)
+				})
+				return
+			}
+		} else if withValue {
+			value, err = c.client.getValueFromResponse(resp)
+		}
+		result.complete(value, context, err)
+	}
+	c.client.processor.ProcessRequestAsync(request, onResponse)
+	return result
+}
+
+func (c *asyncClientImplT) Create(key []byte, value []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewRequest(proto.OpCodeCreate, key, value, options.ttl)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	return c.process(request, options, false, true)
+}
+
+func (c *asyncClientImplT) Get(key []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewRequest(proto.OpCodeGet, key, nil, options.ttl)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	return c.process(request, options, true, true)
+}
+
+func (c *asyncClientImplT) Update(key []byte, value []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewRequest(proto.OpCodeUpdate, key, value, options.ttl)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	if inCtx := options.context; inCtx != nil {
+		if r, ok := inCtx.(*cli.RecordInfo); ok {
+			r.SetRequestWithUpdateCond(request)
+		}
+	}
+	return c.process(request, options, false, true)
+}
+
+func (c *asyncClientImplT) Set(key []byte, value []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewRequest(proto.OpCodeSet, key, value, options.ttl)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	return c.process(request, options, false, true)
+}
+
+func (c *asyncClientImplT) Destroy(key []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewRequest(proto.OpCodeDestroy, key, nil, 0)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	return c.process(request, options, false, false)
+}
+
+func (c *asyncClientImplT) UDFGet(key []byte, fname []byte, params []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewUDFRequest(proto.OpCodeUDFGet, key, fname, params, options.ttl)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	return c.process(request, options, true, true)
+}
+
+func (c *asyncClientImplT) UDFSet(key []byte, fname []byte, params []byte, opts ...IOption) IResult {
+	options := c.client.newOptionData(opts...)
+	request := c.client.NewUDFRequest(proto.OpCodeUDFSet, key, fname, params, options.ttl)
+	if err := options.applyToRequest(request); err != nil {
+		return failedResult(err)
+	}
+	return c.process(request, options, false, true)
+}
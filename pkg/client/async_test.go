@@ -0,0 +1,58 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResult(t *testing.T) {
+	r := newResult()
+	if r.Poll() {
+		t.Fatal("result should not be ready")
+	}
+	if _, _, err := r.GetWithTimeout(time.Millisecond); err != ErrResultTimeout {
+		t.Fatalf("expected ErrResultTimeout, got %v", err)
+	}
+	chCalled := make(chan error, 2)
+	r.OnComplete(func(value []byte, ctx IContext, err error) {
+		chCalled <- err
+	})
+	go r.complete([]byte("value"), nil, ErrNoKey)
+
+	if value, _, err := r.Get(); err != ErrNoKey || string(value) != "value" {
+		t.Errorf("unexpected result: %s, %v", value, err)
+	}
+	if err := <-chCalled; err != ErrNoKey {
+		t.Errorf("unexpected callback error: %v", err)
+	}
+	if !r.Poll() {
+		t.Error("result should be ready")
+	}
+	r.OnComplete(func(value []byte, ctx IContext, err error) {
+		chCalled <- err
+	})
+	select {
+	case <-chCalled:
+	default:
+		t.Error("callback registered after completion not invoked")
+	}
+}
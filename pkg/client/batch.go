@@ -0,0 +1,124 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/internal/cli"
+	"juno/pkg/proto"
+)
+
+// BatchResult is the result of one key of a batch operation. Err is mapped the
+// same way as the error returned by the corresponding single-key operation.
+type BatchResult struct {
+	Key     []byte
+	Value   []byte
+	Context IContext
+	Err     error
+}
+
+func (c *clientImplT) BatchGet(keys [][]byte, opts ...IOption) ([]BatchResult, error) {
+	return c.BatchGetCtx(context.Background(), keys, opts...)
+}
+
+func (c *clientImplT) BatchSet(keys [][]byte, values [][]byte, opts ...IOption) ([]BatchResult, error) {
+	return c.BatchSetCtx(context.Background(), keys, values, opts...)
+}
+
+func (c *clientImplT) BatchDestroy(keys [][]byte, opts ...IOption) ([]BatchResult, error) {
+	return c.BatchDestroyCtx(context.Background(), keys, opts...)
+}
+
+func (c *clientImplT) BatchGetCtx(ctx context.Context, keys [][]byte, opts ...IOption) ([]BatchResult, error) {
+	return c.batch(ctx, proto.OpCodeGet, keys, nil, opts...)
+}
+
+func (c *clientImplT) BatchSetCtx(ctx context.Context, keys [][]byte, values [][]byte, opts ...IOption) ([]BatchResult, error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("number of keys (%d) and values (%d) mismatch", len(keys), len(values))
+	}
+	return c.batch(ctx, proto.OpCodeSet, keys, values, opts...)
+}
+
+func (c *clientImplT) BatchDestroyCtx(ctx context.Context, keys [][]byte, opts ...IOption) ([]BatchResult, error) {
+	return c.batch(ctx, proto.OpCodeDestroy, keys, nil, opts...)
+}
+
+// batch pipelines one request per key. The returned error is only set if the
+// batch as a whole cannot be processed. Failures of individual keys are
+// reported in their BatchResult.
+func (c *clientImplT) batch(ctx context.Context, op proto.OpCode, keys [][]byte, values [][]byte, opts ...IOption) (results []BatchResult, err error) {
+	if len(keys) == 0 {
+		err = fmt.Errorf("no key specified")
+		return
+	}
+	options := c.newOptionData(opts...)
+	ttl := options.ttl
+	if op == proto.OpCodeDestroy {
+		ttl = 0
+	}
+	requests := make([]*proto.OperationalMessage, len(keys))
+	for i, key := range keys {
+		var value []byte
+		if values != nil {
+			value = values[i]
+		}
+		requests[i] = c.NewRequest(op, key, value, ttl)
+		if err = options.applyToRequest(requests[i]); err != nil {
+			return
+		}
+	}
+
+	var responses []*proto.OperationalMessage
+	var errs []error
+	if responses, errs, err = c.processor.ProcessBatch(ctx, requests); err != nil {
+		return
+	}
+	results = make([]BatchResult, len(keys))
+	for i := range requests {
+		r := &results[i]
+		r.Key = keys[i]
+		if errs[i] != nil {
+			r.Err = errs[i]
+			continue
+		}
+		if responses[i] == nil {
+			r.Err = &cli.IOError{fmt.Errorf("no response")}
+			continue
+		}
+		var recInfo *cli.RecordInfo
+		if op != proto.OpCodeDestroy {
+			recInfo = &cli.RecordInfo{}
+			r.Context = recInfo
+		}
+		if r.Err = checkResponse(requests[i], responses[i], recInfo); r.Err == nil {
+			if op == proto.OpCodeGet {
+				r.Value, r.Err = c.getValueFromResponse(responses[i])
+			}
+		} else {
+			glog.Debug(r.Err)
+		}
+	}
+	return
+}
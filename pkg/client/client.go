@@ -74,10 +74,29 @@ possible returned error if client successfully received the response from Proxy
   * ErrRecordLocked
   * ErrWriteFailure
 
+BatchGet, BatchSet and BatchDestroy return one BatchResult per key, whose Err is
+one of the errors listed above for Get, Set and Destroy respectively, or an
+IOError if the request of the key could not be processed.
+
+Mutate does a read-modify-write: Get, then Update with WithCond, or Create if
+the record is missing and WithCreateIfMissing is set, starting over on
+ErrConditionViolation or ErrUniqueKeyViolation. It returns the error of the
+last attempt.
+
+Scan iterates the records of a namespace with a key prefix, in key order across
+all the shards, fetching a page of WithPageSize records at a time. The error of
+the iterator, if any, is one of ErrBadMsg, ErrBadParam, ErrInternal, ErrBusy
+or ErrNoStorage, or an IOError.
+
+The methods with the Ctx suffix take a context.Context. If ctx is done before
+the response is received, they return ctx.Err() right away, and the response
+received later, if any, is dropped. Retries stop once ctx is done.
+
 */
 package client
 
 import (
+	"context"
 	"io"
 )
 
@@ -98,21 +117,22 @@ type IClient interface {
 	Destroy(key []byte, opts ...IOption) (err error)
 	UDFGet(key []byte, fname []byte, params []byte, opts ...IOption) ([]byte, IContext, error)
 	UDFSet(key []byte, fname []byte, params []byte, opts ...IOption) (IContext, error)
-}
+	BatchGet(keys [][]byte, opts ...IOption) ([]BatchResult, error)
+	BatchSet(keys [][]byte, values [][]byte, opts ...IOption) ([]BatchResult, error)
+	BatchDestroy(keys [][]byte, opts ...IOption) ([]BatchResult, error)
+	Mutate(key []byte, fn MutateFunc, opts ...IOption) (IContext, error)
+	Scan(ns string, prefix []byte, opts ...IOption) IScanIterator
 
-//type IResult interface {
-//	Get()
-//	GetWithTimeout()
-//	Poll()
-//}
-//type IValueResult interface {
-//	IResult
-//}
-//
-//type AsyncClient interface {
-//	Create(key []byte, value []byte, opts ...IOption) IResult
-//	Update(key []byte, value []byte, opts ...IOption) IResult
-//	Set(key []byte, value []byte, opts ...IOption) IResult
-//	Get(key []byte, value []byte, opts ...IOption) IResult
-//	Destroy(key []byte) IResult
-//}
+	CreateCtx(ctx context.Context, key []byte, value []byte, opts ...IOption) (IContext, error)
+	GetCtx(ctx context.Context, key []byte, opts ...IOption) ([]byte, IContext, error)
+	UpdateCtx(ctx context.Context, key []byte, value []byte, opts ...IOption) (IContext, error)
+	SetCtx(ctx context.Context, key []byte, value []byte, opts ...IOption) (IContext, error)
+	DestroyCtx(ctx context.Context, key []byte, opts ...IOption) (err error)
+	UDFGetCtx(ctx context.Context, key []byte, fname []byte, params []byte, opts ...IOption) ([]byte, IContext, error)
+	UDFSetCtx(ctx context.Context, key []byte, fname []byte, params []byte, opts ...IOption) (IContext, error)
+	BatchGetCtx(ctx context.Context, keys [][]byte, opts ...IOption) ([]BatchResult, error)
+	BatchSetCtx(ctx context.Context, keys [][]byte, values [][]byte, opts ...IOption) ([]BatchResult, error)
+	BatchDestroyCtx(ctx context.Context, keys [][]byte, opts ...IOption) ([]BatchResult, error)
+	MutateCtx(ctx context.Context, key []byte, fn MutateFunc, opts ...IOption) (IContext, error)
+	ScanCtx(ctx context.Context, ns string, prefix []byte, opts ...IOption) IScanIterator
+}
@@ -20,6 +20,7 @@
 package client
 
 import (
+	gocontext "context"
 	"fmt"
 	"runtime"
 
@@ -35,20 +36,14 @@ type clientImplT struct {
 	config    Config
 	appName   string
 	namespace string
-	processor *cli.Processor
+	processor *processorPoolT
 }
 
-func newProcessorWithConfig(conf *Config) *cli.Processor {
+func newProcessorWithConfig(conf *Config) *processorPoolT {
 	if conf == nil {
 		return nil
 	}
-	c := cli.NewProcessor(
-		conf.Server,
-		conf.Appname,
-		conf.ConnectTimeout.Duration,
-		conf.RequestTimeout.Duration,
-		conf.ConnRecycleTimeout.Duration)
-	return c
+	return newProcessorPool(conf)
 }
 
 func New(conf Config) (IClient, error) {
@@ -62,7 +57,7 @@ func New(conf Config) (IClient, error) {
 		namespace: conf.Namespace,
 	}
 	client.processor.Start()
-	runtime.SetFinalizer(client.processor, func(p *cli.Processor) {
+	runtime.SetFinalizer(client.processor, func(p *processorPoolT) {
 		p.Close()
 	})
 	return client, nil
@@ -71,15 +66,21 @@ func New(conf Config) (IClient, error) {
 func NewClient(server string, ns string, app string) (IClient, error) {
 	c := &clientImplT{
 		config: Config{
-			Server:            io.ServiceEndpoint{Addr: server, SSLEnabled: false},
-			Namespace:         ns,
-			Appname:           app,
-			RetryCount:        defaultConfig.RetryCount,
-			DefaultTimeToLive: defaultConfig.DefaultTimeToLive,
-			ConnectTimeout:    defaultConfig.ConnectTimeout,
-			ReadTimeout:       defaultConfig.ReadTimeout,
-			WriteTimeout:      defaultConfig.WriteTimeout,
-			RequestTimeout:    defaultConfig.RequestTimeout,
+			Server:               io.ServiceEndpoint{Addr: server, SSLEnabled: false},
+			Namespace:            ns,
+			Appname:              app,
+			RetryCount:           defaultConfig.RetryCount,
+			DefaultTimeToLive:    defaultConfig.DefaultTimeToLive,
+			ConnectTimeout:       defaultConfig.ConnectTimeout,
+			ReadTimeout:          defaultConfig.ReadTimeout,
+			WriteTimeout:         defaultConfig.WriteTimeout,
+			RequestTimeout:       defaultConfig.RequestTimeout,
+			Retry:                defaultConfig.Retry,
+			EjectBackoffBase:     defaultConfig.EjectBackoffBase,
+			EjectBackoffMax:      defaultConfig.EjectBackoffMax,
+			NumConnections:       defaultConfig.NumConnections,
+			Compression:          defaultConfig.Compression,
+			CompressionThreshold: defaultConfig.CompressionThreshold,
 		},
 		appName:   app,
 		namespace: ns,
@@ -92,7 +93,7 @@ func NewClient(server string, ns string, app string) (IClient, error) {
 		glog.Error(errstr)
 		return nil, fmt.Errorf(errstr)
 	}
-	runtime.SetFinalizer(c.processor, func(p *cli.Processor) {
+	runtime.SetFinalizer(c.processor, func(p *processorPoolT) {
 		p.Close()
 	})
 	return c, nil
@@ -121,140 +122,152 @@ func newContext(resp *proto.OperationalMessage) IContext {
 }
 
 func (c *clientImplT) Create(key []byte, value []byte, opts ...IOption) (context IContext, err error) {
+	return c.CreateCtx(gocontext.Background(), key, value, opts...)
+}
+
+func (c *clientImplT) CreateCtx(ctx gocontext.Context, key []byte, value []byte, opts ...IOption) (context IContext, err error) {
 	glog.Verbosef("Create ")
-	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	options := c.newOptionData(opts...)
 	recInfo := &cli.RecordInfo{}
 	context = recInfo
 	request := c.NewRequest(proto.OpCodeCreate, key, value, options.ttl)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
-	}
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, recInfo); err != nil {
-			glog.Debug(err)
-		}
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
+	_, err = c.processRequest(ctx, request, recInfo, options)
 	return
 }
 
 func (c *clientImplT) Get(key []byte, opts ...IOption) (value []byte, context IContext, err error) {
+	return c.GetCtx(gocontext.Background(), key, opts...)
+}
+
+func (c *clientImplT) GetCtx(ctx gocontext.Context, key []byte, opts ...IOption) (value []byte, context IContext, err error) {
 	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	options := c.newOptionData(opts...)
 	recInfo := &cli.RecordInfo{}
 	context = recInfo
 	request := c.NewRequest(proto.OpCodeGet, key, nil, options.ttl)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, recInfo); err == nil {
-			payload := resp.GetPayload()
-			sz := payload.GetLength()
-			if sz != 0 {
-				value, err = payload.GetClearValue()
-			}
-		} else {
-			glog.Debug(err)
-		}
+	if resp, err = c.processRequest(ctx, request, recInfo, options); err == nil {
+		value, err = c.getValueFromResponse(resp)
 	}
 	return
 }
 
 func (c *clientImplT) Update(key []byte, value []byte, opts ...IOption) (context IContext, err error) {
-	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	return c.UpdateCtx(gocontext.Background(), key, value, opts...)
+}
+
+func (c *clientImplT) UpdateCtx(ctx gocontext.Context, key []byte, value []byte, opts ...IOption) (context IContext, err error) {
+	options := c.newOptionData(opts...)
 	recInfo := &cli.RecordInfo{}
 	context = recInfo
 	request := c.NewRequest(proto.OpCodeUpdate, key, value, options.ttl)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
 	if inCtx := options.context; inCtx != nil {
 		if r, ok := inCtx.(*cli.RecordInfo); ok {
 			r.SetRequestWithUpdateCond(request)
 		}
 	}
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, recInfo); err != nil {
-			glog.Debug(err)
-		}
-	}
+	_, err = c.processRequest(ctx, request, recInfo, options)
 	return
 }
 
 func (c *clientImplT) Set(key []byte, value []byte, opts ...IOption) (context IContext, err error) {
-	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	return c.SetCtx(gocontext.Background(), key, value, opts...)
+}
+
+func (c *clientImplT) SetCtx(ctx gocontext.Context, key []byte, value []byte, opts ...IOption) (context IContext, err error) {
+	options := c.newOptionData(opts...)
 	recInfo := &cli.RecordInfo{}
 	context = recInfo
 	request := c.NewRequest(proto.OpCodeSet, key, value, options.ttl)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
-	}
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, recInfo); err != nil {
-			glog.Debug(err)
-		}
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
+	_, err = c.processRequest(ctx, request, recInfo, options)
 	return
 }
 
 func (c *clientImplT) Destroy(key []byte, opts ...IOption) (err error) {
-	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	return c.DestroyCtx(gocontext.Background(), key, opts...)
+}
+
+func (c *clientImplT) DestroyCtx(ctx gocontext.Context, key []byte, opts ...IOption) (err error) {
+	options := c.newOptionData(opts...)
 	request := c.NewRequest(proto.OpCodeDestroy, key, nil, 0)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
-	}
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, nil); err != nil {
-			glog.Debug(err)
-		}
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
+	_, err = c.processRequest(ctx, request, nil, options)
 	return
 }
 
 func (c *clientImplT) UDFGet(key []byte, fname []byte, params []byte, opts ...IOption) (value []byte, context IContext, err error) {
+	return c.UDFGetCtx(gocontext.Background(), key, fname, params, opts...)
+}
+
+func (c *clientImplT) UDFGetCtx(ctx gocontext.Context, key []byte, fname []byte, params []byte, opts ...IOption) (value []byte, context IContext, err error) {
 	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	options := c.newOptionData(opts...)
 	recInfo := &cli.RecordInfo{}
 	context = recInfo
 	request := c.NewUDFRequest(proto.OpCodeUDFGet, key, fname, params, options.ttl)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
-
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, recInfo); err == nil {
-			payload := resp.GetPayload()
-			sz := payload.GetLength()
-			if sz != 0 {
-				value, err = payload.GetClearValue()
-			}
-		} else {
-			glog.Debug(err)
-		}
+	if resp, err = c.processRequest(ctx, request, recInfo, options); err == nil {
+		value, err = c.getValueFromResponse(resp)
 	}
 	return
 }
 
 func (c *clientImplT) UDFSet(key []byte, fname []byte, params []byte, opts ...IOption) (context IContext, err error) {
-	var resp *proto.OperationalMessage
-	options := newOptionData(opts...)
+	return c.UDFSetCtx(gocontext.Background(), key, fname, params, opts...)
+}
+
+func (c *clientImplT) UDFSetCtx(ctx gocontext.Context, key []byte, fname []byte, params []byte, opts ...IOption) (context IContext, err error) {
+	options := c.newOptionData(opts...)
 	recInfo := &cli.RecordInfo{}
 	context = recInfo
 	request := c.NewUDFRequest(proto.OpCodeUDFSet, key, fname, params, options.ttl)
-	if len(options.correlationId) > 0 {
-		request.SetCorrelationID([]byte(options.correlationId))
+	if err = options.applyToRequest(request); err != nil {
+		return
 	}
+	_, err = c.processRequest(ctx, request, recInfo, options)
+	return
+}
 
-	if resp, err = c.processor.ProcessRequest(request); err == nil {
-		if err = checkResponse(request, resp, recInfo); err != nil {
-			glog.Debug(err)
+// processRequest sends the request, maps the response status to an error and
+// retries the request according to the retry policy.
+func (c *clientImplT) processRequest(ctx gocontext.Context, request *proto.OperationalMessage, recInfo *cli.RecordInfo, options *optionData) (resp *proto.OperationalMessage, err error) {
+	retrier := newRetrier(c.getRetryPolicy(options), request)
+	for {
+		if resp, err = c.processor.ProcessRequest(ctx, request); err == nil {
+			err = checkResponse(request, resp, recInfo)
+		}
+		if err == nil {
+			return
+		}
+		glog.Debug(err)
+		if !retrier.shouldRetry(ctx, err) {
+			return
 		}
+		request.SetNewRequestID()
+		glog.Debugf("retry %s rid=%s attempt=%d", request.GetOpCode(), request.GetRequestIDString(), retrier.attempts)
 	}
-	return
+}
+
+func (c *clientImplT) getRetryPolicy(options *optionData) *RetryPolicy {
+	if options.retryPolicy != nil {
+		return options.retryPolicy
+	}
+	return &c.config.Retry
 }
 
 ///TODO temporary
@@ -269,6 +282,9 @@ func (c *clientImplT) NewRequest(op proto.OpCode, key []byte, value []byte, ttl
 	payload.SetWithClearValue(value)
 	request.SetRequest(op, key, []byte(c.namespace), &payload, ttl)
 	request.SetNewRequestID()
+	if c.config.Tenant != "" {
+		request.SetTenant([]byte(c.config.Tenant))
+	}
 	return
 }
 
@@ -279,11 +295,28 @@ func (c *clientImplT) NewUDFRequest(op proto.OpCode, key []byte, fname []byte, p
 	payload.SetWithClearValue(params)
 	request.SetRequest(op, key, []byte(c.namespace), &payload, ttl)
 	request.SetNewRequestID()
+	if c.config.Tenant != "" {
+		request.SetTenant([]byte(c.config.Tenant))
+	}
 	request.SetUDFName(fname)
 
 	return
 }
 
+// getValueFromResponse returns the clear value of the response payload. Values
+// encrypted by the client are decrypted with Config.EncryptionKeyStore.
+func (c *clientImplT) getValueFromResponse(response *proto.OperationalMessage) (value []byte, err error) {
+	payload := response.GetPayload()
+	if payload.GetLength() != 0 {
+		var ks proto.IEncryptionKeyStore
+		if payload.GetPayloadType() == proto.PayloadTypeEncryptedByClient {
+			ks = c.config.EncryptionKeyStore
+		}
+		value, err = payload.GetClearValueWithKeyStore(ks)
+	}
+	return
+}
+
 func checkResponse(request *proto.OperationalMessage, response *proto.OperationalMessage, recInfo *cli.RecordInfo) (err error) {
 	opCode := request.GetOpCode()
 	if opCode != response.GetOpCode() {
@@ -301,6 +334,10 @@ func checkResponse(request *proto.OperationalMessage, response *proto.Operationa
 		glog.Debugf("%s %s", opCode.String(), b.String())
 	}
 
+	if status == proto.OpStatusUDFError {
+		err = &UDFError{Message: string(response.GetPayload().GetData())}
+		return
+	}
 	var ok bool
 	if err, ok = errorMapping[status]; !ok {
 		err = ErrInternal
@@ -0,0 +1,116 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	pb "google.golang.org/protobuf/proto"
+)
+
+// ICodec serializes the values of a Typed client. Version is written as the
+// first byte of every value, so that values written with an older codec can
+// still be read after migrating to a new one.
+type ICodec interface {
+	Version() uint8
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type (
+	jsonCodecT  struct{}
+	gobCodecT   struct{}
+	protoCodecT struct{}
+)
+
+const (
+	CodecVersionJSON  uint8 = 1
+	CodecVersionGob   uint8 = 2
+	CodecVersionProto uint8 = 3
+)
+
+var (
+	JSONCodec ICodec = jsonCodecT{}
+	GobCodec  ICodec = gobCodecT{}
+	// ProtoCodec serializes protobuf messages. The type parameter of Typed has
+	// to be a pointer to a generated message type, e.g. Typed[*pb.Account].
+	ProtoCodec ICodec = protoCodecT{}
+)
+
+func (jsonCodecT) Version() uint8 {
+	return CodecVersionJSON
+}
+
+func (jsonCodecT) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodecT) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (gobCodecT) Version() uint8 {
+	return CodecVersionGob
+}
+
+func (gobCodecT) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodecT) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (protoCodecT) Version() uint8 {
+	return CodecVersionProto
+}
+
+func (protoCodecT) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(pb.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a protobuf message", v)
+	}
+	return pb.Marshal(msg)
+}
+
+// Unmarshal takes a pointer to a message pointer, and allocates the message if
+// the latter is nil.
+func (protoCodecT) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("%T is not a pointer to a protobuf message pointer", v)
+	}
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+	}
+	msg, ok := rv.Elem().Interface().(pb.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a protobuf message", rv.Elem().Interface())
+	}
+	return pb.Unmarshal(data, msg)
+}
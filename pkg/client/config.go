@@ -24,13 +24,24 @@ import (
 	"time"
 
 	"juno/pkg/io"
+	"juno/pkg/proto"
+	"juno/pkg/sec"
 	"juno/pkg/util"
 )
 
 type Duration = util.Duration
 
 type Config struct {
-	Server             io.ServiceEndpoint
+	Server io.ServiceEndpoint
+	// Servers, if specified, overrides Server with a list of proxy endpoints.
+	// Requests are routed to healthy endpoints according to LoadBalance, either
+	// "RoundRobin" (default) or "LeastOutstanding". An endpoint is ejected after
+	// a connect or IO error, for EjectBackoffBase doubling on each consecutive
+	// failure up to EjectBackoffMax.
+	Servers            []io.ServiceEndpoint
+	LoadBalance        string
+	EjectBackoffBase   Duration
+	EjectBackoffMax    Duration
 	Appname            string
 	Namespace          string
 	RetryCount         int
@@ -40,22 +51,54 @@ type Config struct {
 	WriteTimeout       Duration
 	RequestTimeout     Duration
 	ConnRecycleTimeout Duration
+	Retry              RetryPolicy
+	// Compression is the name of the codec, registered with
+	// proto.RegisterCompressionCodec, used to compress values larger than
+	// CompressionThreshold bytes. Built-in codecs are "Snappy", "Zstd" and
+	// "Gzip". Compression is disabled if empty. The proxy only encrypts clear
+	// values, so compressed values are stored unencrypted even if the proxy
+	// has encryption enabled. Set EncryptionKeyStore, which takes precedence
+	// over Compression, for values to be encrypted at rest.
+	Compression          string
+	CompressionThreshold int
+	// EncryptionKeyStore, if set, is used to encrypt the values of Create,
+	// Update and Set, and to decrypt the values returned by Get and UDFGet. If
+	// not set, it is loaded from EncryptionKeyStoreFile, if specified, with
+	// sec.NewLocalFileStore. Encrypted values are not compressed.
+	EncryptionKeyStore     proto.IEncryptionKeyStore `toml:"-"`
+	EncryptionKeyStoreFile string
+	// NumConnections is the number of connections to each proxy endpoint.
+	// Requests are sent over the connection with the least outstanding requests.
+	NumConnections int
+	// Tenant, if set, is sent with each request to the proxies supporting it,
+	// as negotiated when connecting.
+	Tenant string
+	// TraceContext enables sending the trace context set with
+	// WithTraceContext to the proxies supporting it.
+	TraceContext bool
 }
 
 var defaultConfig = Config{
 	RetryCount:         1,
+	NumConnections:     1,
 	DefaultTimeToLive:  1800,
 	ConnectTimeout:     Duration{100 * time.Millisecond},
 	ReadTimeout:        Duration{500 * time.Millisecond},
 	WriteTimeout:       Duration{500 * time.Millisecond},
 	RequestTimeout:     Duration{1000 * time.Millisecond},
 	ConnRecycleTimeout: Duration{9 * time.Second},
+	EjectBackoffBase:   Duration{1 * time.Second},
+	EjectBackoffMax:    Duration{30 * time.Second},
 }
 
 func SetDefaultTimeToLive(ttl int) {
 	defaultConfig.DefaultTimeToLive = ttl
 }
 
+func SetDefaultRetryPolicy(policy RetryPolicy) {
+	defaultConfig.Retry = policy
+}
+
 func SetDefaultTimeout(connect, read, write, request, connRecycle time.Duration) {
 	defaultConfig.ConnectTimeout.Duration = connect
 	defaultConfig.ReadTimeout.Duration = read
@@ -69,8 +112,25 @@ func (c *Config) SetDefault() {
 }
 
 func (c *Config) validate() error {
-	if err := c.Server.Validate(); err != nil {
-		return err
+	if len(c.Servers) == 0 {
+		if err := c.Server.Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range c.Servers {
+		if err := c.Servers[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if len(c.Compression) != 0 {
+		if _, err := proto.GetCompressionCodec(c.Compression); err != nil {
+			return fmt.Errorf("Config.Compression %s: %s", c.Compression, err)
+		}
+	}
+	switch c.LoadBalance {
+	case "", LoadBalanceRoundRobin, LoadBalanceLeastOutstanding:
+	default:
+		return fmt.Errorf("Config.LoadBalance %s not supported.", c.LoadBalance)
 	}
 	if len(c.Appname) == 0 {
 		return fmt.Errorf("Config.AppName not specified.")
@@ -78,6 +138,19 @@ func (c *Config) validate() error {
 	if len(c.Namespace) == 0 {
 		return fmt.Errorf("Config.Namespace not specified.")
 	}
+	if c.NumConnections < 0 {
+		return fmt.Errorf("Config.NumConnections cannot be negative.")
+	}
+	if c.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("Config.Retry.MaxAttempts cannot be negative.")
+	}
+	if c.EncryptionKeyStore == nil && len(c.EncryptionKeyStoreFile) != 0 {
+		ks, err := sec.NewLocalFileStore(c.EncryptionKeyStoreFile)
+		if err != nil {
+			return fmt.Errorf("Config.EncryptionKeyStoreFile %s: %s", c.EncryptionKeyStoreFile, err)
+		}
+		c.EncryptionKeyStore = ks
+	}
 	/// TODO to validate others
 	return nil
 }
@@ -0,0 +1,66 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"juno/pkg/io"
+)
+
+func TestContextDeadline(t *testing.T) {
+	conf := defaultConfig
+	conf.Server = io.ServiceEndpoint{Addr: "127.0.0.1:8080"}
+	conf.Appname = "app"
+	conf.Namespace = "ns"
+	conf.Retry = RetryPolicy{MaxAttempts: 3}
+	// the processor is not started, so that no response will ever be received
+	c := &clientImplT{config: conf, processor: newProcessorWithConfig(&conf), namespace: conf.Namespace}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := c.GetCtx(ctx, []byte("key")); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call returned after %s", elapsed)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.SetCtx(ctx, []byte("key"), []byte("value")); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results, err := c.BatchGetCtx(ctx, [][]byte{[]byte("k1"), []byte("k2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != context.DeadlineExceeded {
+			t.Errorf("expected context.DeadlineExceeded, got %v", r.Err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"juno/pkg/proto"
+)
+
+type testKeyStoreT struct {
+	keys    [][]byte
+	version uint32
+}
+
+func (ks *testKeyStoreT) GetEncryptionKey() ([]byte, uint32, error) {
+	return ks.keys[ks.version], ks.version, nil
+}
+
+func (ks *testKeyStoreT) GetDecryptionKey(version uint32) ([]byte, error) {
+	if int(version) >= len(ks.keys) {
+		return nil, fmt.Errorf("no key of version %d", version)
+	}
+	return ks.keys[version], nil
+}
+
+func (ks *testKeyStoreT) NumKeys() int {
+	return len(ks.keys)
+}
+
+func TestClientEncryption(t *testing.T) {
+	ks := &testKeyStoreT{keys: [][]byte{bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)}}
+	c := &clientImplT{config: Config{
+		Namespace:            "ns",
+		EncryptionKeyStore:   ks,
+		Compression:          proto.SnappyCompression,
+		CompressionThreshold: 1,
+	}}
+	value := bytes.Repeat([]byte("value"), 100)
+
+	request := c.NewRequest(proto.OpCodeSet, []byte("key"), value, 0)
+	if err := c.newOptionData().applyToRequest(request); err != nil {
+		t.Fatal(err)
+	}
+	if pType := request.GetPayload().GetPayloadType(); pType != proto.PayloadTypeEncryptedByClient {
+		t.Fatalf("payload type %s, expected encrypted by client", pType)
+	}
+	if bytes.Contains(request.GetPayload().GetData(), []byte("value")) {
+		t.Error("value sent in clear text")
+	}
+
+	// rotate the key, the value encrypted with the old one can still be read
+	ks.version = 1
+	if v, err := c.getValueFromResponse(request); err != nil || !bytes.Equal(v, value) {
+		t.Errorf("decrypted value mismatch, err: %v", err)
+	}
+
+	get := c.NewRequest(proto.OpCodeGet, []byte("key"), nil, 0)
+	if err := c.newOptionData().applyToRequest(get); err != nil {
+		t.Fatal(err)
+	}
+	if get.GetPayload().GetLength() != 0 {
+		t.Error("Get request should have no payload")
+	}
+}
+
+func TestClientEncryptionFailure(t *testing.T) {
+	// an invalid AES key size makes the encryption fail
+	ks := &testKeyStoreT{keys: [][]byte{[]byte("short")}}
+	c := &clientImplT{config: Config{Namespace: "ns", EncryptionKeyStore: ks}}
+
+	request := c.NewRequest(proto.OpCodeCreate, []byte("key"), []byte("value"), 0)
+	if err := c.newOptionData().applyToRequest(request); err == nil {
+		t.Fatal("expected an encryption error")
+	}
+	if _, err := c.Create([]byte("key"), []byte("value")); err == nil {
+		t.Error("Create should fail when the value cannot be encrypted")
+	}
+	async := &asyncClientImplT{client: c}
+	if _, _, err := async.Set([]byte("key"), []byte("value")).Get(); err == nil {
+		t.Error("async Set should fail when the value cannot be encrypted")
+	}
+}
@@ -39,8 +39,28 @@ var (
 	ErrWriteFailure   error
 	ErrInternal       error
 	ErrOpNotSupported error
+
+	ErrResultTimeout error
+	ErrServiceDenied error
+
+	ErrNoUDF    error
+	ErrUDFError error
 )
 
+// UDFError is returned when the UDF fails on the proxy. Message is the error
+// reported by the UDF. errors.Is(err, ErrUDFError) is true for it.
+type UDFError struct {
+	Message string
+}
+
+func (e *UDFError) Error() string {
+	return "error: udf error: " + e.Message
+}
+
+func (e *UDFError) Retryable() bool { return false }
+
+func (e *UDFError) Is(target error) bool { return target == ErrUDFError }
+
 var errorMapping map[proto.OpStatus]error
 
 func init() {
@@ -59,6 +79,12 @@ func init() {
 	ErrInternal = &cli.Error{"internal error"}
 	ErrOpNotSupported = &cli.Error{"Op not supported"}
 
+	ErrResultTimeout = &cli.Error{"timeout waiting for result"}
+	ErrServiceDenied = &cli.Error{"service denied"}
+
+	ErrNoUDF = &cli.Error{"udf not found"}
+	ErrUDFError = &cli.Error{"udf error"}
+
 	errorMapping = map[proto.OpStatus]error{
 		proto.OpStatusNoError:            nil,
 		proto.OpStatusInconsistent:       nil,
@@ -73,5 +99,8 @@ func init() {
 		proto.OpStatusCommitFailure:      ErrWriteFailure,
 		proto.OpStatusBusy:               ErrBusy,
 		proto.OpStatusNotSupported:       ErrOpNotSupported,
+		proto.OpStatusServiceDenied:      ErrServiceDenied,
+		proto.OpStatusNoUDF:              ErrNoUDF,
+		proto.OpStatusUDFError:           ErrUDFError,
 	}
 }
@@ -21,6 +21,7 @@ package client_test
 
 import (
 	"fmt"
+	"time"
 
 	"juno/pkg/client"
 )
@@ -38,3 +39,22 @@ func Example_newClient() {
 		fmt.Println(err)
 	}
 }
+
+func Example_newAsyncClient() {
+	if cli, err := client.NewAsyncClient("127.0.0.1:8080", "exampleNS", "exampleApp"); err == nil {
+		results := make([]client.IResult, 0, 3)
+		for _, key := range []string{"key1", "key2", "key3"} {
+			results = append(results, cli.Get([]byte(key)))
+		}
+		results[0].OnComplete(func(value []byte, ctx client.IContext, err error) {
+			fmt.Println(len(value), err)
+		})
+		for _, r := range results {
+			if value, ctx, err := r.GetWithTimeout(time.Second); err == nil {
+				fmt.Println(len(value), ctx.GetVersion())
+			}
+		}
+	} else {
+		fmt.Println(err)
+	}
+}
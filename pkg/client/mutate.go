@@ -0,0 +1,113 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+)
+
+// MutateFunc returns the new value of the record given its current value and
+// context. old and context are nil if the record is to be created. Returning an
+// error aborts Mutate, which returns the error as is.
+type MutateFunc func(old []byte, context IContext) ([]byte, error)
+
+// defaultMutatePolicy is the policy of retrying Mutate on conflicts.
+var defaultMutatePolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BackoffBase: Duration{5 * time.Millisecond},
+	BackoffMax:  Duration{100 * time.Millisecond},
+}
+
+// WithMutatePolicy overrides the policy of retrying Mutate on conflicts.
+// RetryNonIdempotent is ignored.
+func WithMutatePolicy(policy RetryPolicy) IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			data.mutatePolicy = &policy
+		}
+	}
+}
+
+// WithCreateIfMissing has Mutate create the record, with the value returned by
+// MutateFunc called with nil, if it does not exist.
+func WithCreateIfMissing() IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			data.createIfMissing = true
+		}
+	}
+}
+
+func (c *clientImplT) Mutate(key []byte, fn MutateFunc, opts ...IOption) (IContext, error) {
+	return c.MutateCtx(context.Background(), key, fn, opts...)
+}
+
+// MutateCtx reads the record, and updates it, on the condition that it has not
+// changed since, with the value returned by fn. On conflict, i.e.
+// ErrConditionViolation, or ErrUniqueKeyViolation if the record has been
+// created by someone else first, or ErrNoKey if it has been destroyed, it starts
+// over after a backoff. fn may therefore be called more than once.
+//
+// The TTL option only applies to Create and Update. The record TTL is not
+// extended by Get.
+func (c *clientImplT) MutateCtx(ctx context.Context, key []byte, fn MutateFunc, opts ...IOption) (recCtx IContext, err error) {
+	options := c.newOptionData(opts...)
+	policy := &defaultMutatePolicy
+	if options.mutatePolicy != nil {
+		policy = options.mutatePolicy
+	}
+	retrier := &retrierT{
+		policy:        policy,
+		idempotent:    true,
+		attempts:      1,
+		timeStart:     time.Now(),
+		nextBackoffUB: policy.BackoffBase.Duration,
+	}
+	getOpts := append(opts[:len(opts):len(opts)], WithTTL(0))
+
+	for {
+		var old, value []byte
+		var conflict bool
+		if old, recCtx, err = c.GetCtx(ctx, key, getOpts...); err == nil {
+			if value, err = fn(old, recCtx); err != nil {
+				return nil, err
+			}
+			recCtx, err = c.UpdateCtx(ctx, key, value, append(opts[:len(opts):len(opts)], WithCond(recCtx))...)
+			conflict = err == ErrConditionViolation || err == ErrNoKey
+		} else if err == ErrNoKey && options.createIfMissing {
+			if value, err = fn(nil, nil); err != nil {
+				return nil, err
+			}
+			recCtx, err = c.CreateCtx(ctx, key, value, opts...)
+			conflict = err == ErrUniqueKeyViolation
+		}
+		if !conflict {
+			return
+		}
+		backoff, ok := retrier.nextBackoff()
+		if !ok || !wait(ctx, backoff) {
+			return
+		}
+		glog.Debugf("mutate conflict: %s, attempt=%d", err, retrier.attempts)
+	}
+}
@@ -19,12 +19,32 @@
 
 package client
 
-import ()
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/internal/cli"
+	"juno/pkg/proto"
+)
 
 type optionData struct {
 	ttl           uint32
 	context       IContext
 	correlationId string
+	retryPolicy   *RetryPolicy
+	compression   string
+	compressAbove int
+	keyStore      proto.IEncryptionKeyStore
+
+	mutatePolicy    *RetryPolicy
+	createIfMissing bool
+	traceParent     string
+
+	pageSize  uint32
+	scanToken []byte
 }
 
 //type IOption interface {
@@ -70,6 +90,110 @@ func WithCorrelationId(id string) IOption {
 	}
 }
 
+// WithRetry overrides Config.Retry for the call.
+func WithRetry(policy RetryPolicy) IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			data.retryPolicy = &policy
+		}
+	}
+}
+
+// WithCompression overrides Config.Compression and Config.CompressionThreshold
+// for the call. An empty codec name disables compression.
+func WithCompression(codec string, threshold int) IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			data.compression = codec
+			data.compressAbove = threshold
+		}
+	}
+}
+
+// WithTraceContext sends the W3C trace context of the OpenTelemetry span in ctx
+// with the request, for the proxy to continue the trace. It requires
+// Config.TraceContext.
+func WithTraceContext(ctx context.Context) IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			carrier := propagation.MapCarrier{}
+			propagation.TraceContext{}.Inject(ctx, carrier)
+			data.traceParent = carrier.Get("traceparent")
+		}
+	}
+}
+
+func (d *optionData) applyToRequest(request *proto.OperationalMessage) error {
+	if len(d.correlationId) > 0 {
+		request.SetCorrelationID([]byte(d.correlationId))
+	}
+	if len(d.traceParent) > 0 {
+		request.SetTraceContext([]byte(d.traceParent))
+	}
+	if d.keyStore != nil {
+		return d.encryptPayload(request)
+	}
+	d.compressPayload(request)
+	return nil
+}
+
+// encryptPayload encrypts the value of Create, Update and Set requests with the
+// client key store. The value is never sent in clear text if that fails.
+func (d *optionData) encryptPayload(request *proto.OperationalMessage) error {
+	switch request.GetOpCode() {
+	case proto.OpCodeCreate, proto.OpCodeUpdate, proto.OpCodeSet:
+	default:
+		return nil
+	}
+	payload := request.GetPayload()
+	if payload.GetPayloadType() != proto.PayloadTypeClear {
+		return nil
+	}
+	if err := payload.EncryptWithKeyStore(proto.PayloadTypeEncryptedByClient, d.keyStore); err != nil {
+		glog.Warningf("fail to encrypt: %s", err)
+		return &cli.Error{"fail to encrypt: " + err.Error()}
+	}
+	return nil
+}
+
+// compressPayload compresses the value of Create, Update and Set requests if it
+// is larger than the threshold, and only if that makes it smaller.
+func (d *optionData) compressPayload(request *proto.OperationalMessage) {
+	if len(d.compression) == 0 {
+		return
+	}
+	switch request.GetOpCode() {
+	case proto.OpCodeCreate, proto.OpCodeUpdate, proto.OpCodeSet:
+	default:
+		return
+	}
+	payload := request.GetPayload()
+	if payload.GetPayloadType() != proto.PayloadTypeClear || int(payload.GetValueLength()) <= d.compressAbove {
+		return
+	}
+	var compressed proto.Payload
+	if err := compressed.SetWithCompressedValue(payload.GetData(), d.compression); err != nil {
+		glog.Warningf("fail to compress with %s: %s", d.compression, err)
+		return
+	}
+	if compressed.GetValueLength() < payload.GetValueLength() {
+		payload.Set(&compressed)
+	}
+}
+
+// newOptionData returns the option data initialized with the client defaults.
+func (c *clientImplT) newOptionData(opts ...IOption) *optionData {
+	data := &optionData{
+		compression:   c.config.Compression,
+		compressAbove: c.config.CompressionThreshold,
+		keyStore:      c.config.EncryptionKeyStore,
+	}
+	for _, op := range opts {
+		op(data)
+	}
+	return data
+}
+
 func newOptionData(opts ...IOption) *optionData {
 	data := &optionData{}
 	for _, op := range opts {
@@ -0,0 +1,257 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/internal/cli"
+	"juno/pkg/io"
+	"juno/pkg/proto"
+)
+
+const (
+	LoadBalanceRoundRobin       = "RoundRobin"
+	LoadBalanceLeastOutstanding = "LeastOutstanding"
+)
+
+type ConnStats = cli.ConnStats
+
+// EndpointStats is the statistics of the connections to one proxy endpoint.
+type EndpointStats struct {
+	Server  string
+	Ejected bool
+	Conns   []ConnStats
+}
+
+type endpointT struct {
+	server      io.ServiceEndpoint
+	processor   *cli.Processor
+	outstanding int32
+
+	mtx          sync.Mutex
+	numFailures  uint
+	ejectedUntil time.Time
+}
+
+// processorPoolT holds one request processor per proxy endpoint. Each request
+// is routed to a healthy endpoint. An endpoint is ejected, with exponential
+// backoff, after a connect or IO error, and is put back once the backoff has
+// elapsed. If all the endpoints are ejected, the one to come back first is used.
+type processorPoolT struct {
+	endpoints        []*endpointT
+	leastOutstanding bool
+	next             uint32
+	ejectBackoffBase time.Duration
+	ejectBackoffMax  time.Duration
+}
+
+func newProcessorPool(conf *Config) *processorPoolT {
+	servers := conf.Servers
+	if len(servers) == 0 {
+		servers = []io.ServiceEndpoint{conf.Server}
+	}
+	p := &processorPoolT{
+		endpoints:        make([]*endpointT, len(servers)),
+		leastOutstanding: conf.LoadBalance == LoadBalanceLeastOutstanding,
+		ejectBackoffBase: conf.EjectBackoffBase.Duration,
+		ejectBackoffMax:  conf.EjectBackoffMax.Duration,
+	}
+	if p.ejectBackoffBase == 0 {
+		p.ejectBackoffBase = defaultConfig.EjectBackoffBase.Duration
+	}
+	if p.ejectBackoffMax == 0 {
+		p.ejectBackoffMax = defaultConfig.EjectBackoffMax.Duration
+	}
+	var features proto.Feature
+	if conf.Tenant != "" {
+		features |= proto.FeatureTenant
+	}
+	if conf.TraceContext {
+		features |= proto.FeatureTraceContext
+	}
+	for i, server := range servers {
+		p.endpoints[i] = &endpointT{
+			server: server,
+			processor: cli.NewProcessorWithConnections(
+				server,
+				conf.Appname,
+				conf.ConnectTimeout.Duration,
+				conf.RequestTimeout.Duration,
+				conf.ConnRecycleTimeout.Duration,
+				conf.NumConnections),
+		}
+		p.endpoints[i].processor.SetFeatures(features)
+	}
+	return p
+}
+
+// GetStats returns the statistics of the connections of a client created by
+// New, NewClient, NewAsync or NewAsyncClient, and nil for any other client.
+func GetStats(client interface{}) []EndpointStats {
+	switch c := client.(type) {
+	case *clientImplT:
+		return c.processor.getStats()
+	case *asyncClientImplT:
+		return c.client.processor.getStats()
+	}
+	return nil
+}
+
+func (p *processorPoolT) Start() {
+	for _, ep := range p.endpoints {
+		ep.processor.Start()
+	}
+}
+
+func (p *processorPoolT) Close() {
+	for _, ep := range p.endpoints {
+		ep.processor.Close()
+	}
+}
+
+func (p *processorPoolT) getStats() []EndpointStats {
+	stats := make([]EndpointStats, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		stats[i] = EndpointStats{
+			Server:  ep.server.Addr,
+			Ejected: !ep.isHealthy(time.Now()),
+			Conns:   ep.processor.GetConnStats(),
+		}
+	}
+	return stats
+}
+
+func (e *endpointT) isHealthy(now time.Time) bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return !now.Before(e.ejectedUntil)
+}
+
+func (e *endpointT) getEjectedUntil() time.Time {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.ejectedUntil
+}
+
+func (p *processorPoolT) pick() *endpointT {
+	num := len(p.endpoints)
+	if num == 1 {
+		return p.endpoints[0]
+	}
+	now := time.Now()
+	start := int(atomic.AddUint32(&p.next, 1) % uint32(num))
+
+	var picked *endpointT
+	for i := 0; i < num; i++ {
+		ep := p.endpoints[(start+i)%num]
+		if !ep.isHealthy(now) {
+			continue
+		}
+		if !p.leastOutstanding {
+			return ep
+		}
+		if picked == nil || atomic.LoadInt32(&ep.outstanding) < atomic.LoadInt32(&picked.outstanding) {
+			picked = ep
+		}
+	}
+	if picked == nil {
+		picked = p.endpoints[start]
+		for _, ep := range p.endpoints {
+			if ep.getEjectedUntil().Before(picked.getEjectedUntil()) {
+				picked = ep
+			}
+		}
+	}
+	return picked
+}
+
+func (p *processorPoolT) onSent(ep *endpointT, num int) {
+	atomic.AddInt32(&ep.outstanding, int32(num))
+}
+
+func (p *processorPoolT) onDone(ep *endpointT, num int, err error) {
+	atomic.AddInt32(&ep.outstanding, -int32(num))
+
+	ep.mtx.Lock()
+	defer ep.mtx.Unlock()
+	if _, ok := err.(*cli.IOError); !ok {
+		// responses of requests sent before the ejection don't bring it back early
+		if !time.Now().Before(ep.ejectedUntil) {
+			ep.numFailures = 0
+		}
+		return
+	}
+	if len(p.endpoints) == 1 {
+		return
+	}
+	backoff := p.ejectBackoffBase << ep.numFailures
+	if backoff > p.ejectBackoffMax || backoff <= 0 {
+		backoff = p.ejectBackoffMax
+	} else {
+		ep.numFailures++
+	}
+	ep.ejectedUntil = time.Now().Add(backoff)
+	glog.Warningf("proxy endpoint %s ejected for %s: %s", ep.server.Addr, backoff, err)
+}
+
+func (p *processorPoolT) ProcessRequest(ctx context.Context, request *proto.OperationalMessage) (resp *proto.OperationalMessage, err error) {
+	ep := p.pick()
+	p.onSent(ep, 1)
+	resp, err = ep.processor.ProcessRequestWithContext(ctx, request)
+	p.onDone(ep, 1, err)
+	return
+}
+
+func (p *processorPoolT) ProcessRequestAsync(request *proto.OperationalMessage, onResponse func(resp *proto.OperationalMessage, err error)) {
+	ep := p.pick()
+	p.onSent(ep, 1)
+	ep.processor.ProcessRequestAsync(request, func(resp *proto.OperationalMessage, err error) {
+		p.onDone(ep, 1, err)
+		onResponse(resp, err)
+	})
+}
+
+func (p *processorPoolT) ProcessBatch(ctx context.Context, requests []*proto.OperationalMessage) (responses []*proto.OperationalMessage, errs []error, err error) {
+	ep := p.pick()
+	num := len(requests)
+	p.onSent(ep, num)
+	responses, errs, err = ep.processor.ProcessBatchWithContext(ctx, requests)
+
+	var ioErr error
+	for _, e := range errs {
+		if _, ok := e.(*cli.IOError); ok {
+			ioErr = e
+			break
+		}
+	}
+	p.onDone(ep, num, ioErr)
+	return
+}
+
+func (p *processorPoolT) ProcessBatchRequests(requests []*proto.OperationalMessage) (responses []*proto.OperationalMessage, err error) {
+	responses, _, err = p.ProcessBatch(context.Background(), requests)
+	return
+}
@@ -0,0 +1,113 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"juno/internal/cli"
+	"juno/pkg/io"
+	"juno/pkg/proto"
+)
+
+func newTestPool(lb string) *processorPoolT {
+	conf := defaultConfig
+	conf.Servers = []io.ServiceEndpoint{{Addr: "127.0.0.1:8080"}, {Addr: "127.0.0.1:8081"}, {Addr: "127.0.0.1:8082"}}
+	conf.LoadBalance = lb
+	conf.EjectBackoffBase = Duration{time.Minute}
+	return newProcessorPool(&conf)
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	p := newTestPool(LoadBalanceRoundRobin)
+	picked := make(map[*endpointT]int)
+	for i := 0; i < 30; i++ {
+		picked[p.pick()]++
+	}
+	for _, ep := range p.endpoints {
+		if picked[ep] != 10 {
+			t.Errorf("%s picked %d times", ep.server.Addr, picked[ep])
+		}
+	}
+}
+
+func TestPoolEjection(t *testing.T) {
+	p := newTestPool(LoadBalanceRoundRobin)
+	bad := p.endpoints[1]
+	p.onSent(bad, 1)
+	p.onDone(bad, 1, &cli.IOError{errors.New("connection refused")})
+	for i := 0; i < 10; i++ {
+		if p.pick() == bad {
+			t.Fatal("ejected endpoint picked")
+		}
+	}
+	// late success does not bring the endpoint back
+	p.onDone(bad, 0, nil)
+	if bad.isHealthy(time.Now()) {
+		t.Error("endpoint should stay ejected")
+	}
+	for _, ep := range p.endpoints {
+		ep.ejectedUntil = time.Now().Add(time.Duration(len(ep.server.Addr)) * time.Hour)
+	}
+	p.endpoints[2].ejectedUntil = time.Now().Add(time.Second)
+	if p.pick() != p.endpoints[2] {
+		t.Error("endpoint to come back first should be picked when all are ejected")
+	}
+}
+
+func TestPoolLeastOutstanding(t *testing.T) {
+	p := newTestPool(LoadBalanceLeastOutstanding)
+	p.onSent(p.endpoints[0], 5)
+	p.onSent(p.endpoints[2], 3)
+	for i := 0; i < 5; i++ {
+		if p.pick() != p.endpoints[1] {
+			t.Fatal("least outstanding endpoint not picked")
+		}
+	}
+}
+
+func TestPoolConnections(t *testing.T) {
+	conf := defaultConfig
+	conf.Server = io.ServiceEndpoint{Addr: "127.0.0.1:8080"}
+	conf.NumConnections = 3
+	// the processors are not started, so that the requests stay outstanding
+	p := newProcessorPool(&conf)
+	for i := 0; i < 7; i++ {
+		request := &proto.OperationalMessage{}
+		request.SetRequest(proto.OpCodeGet, []byte("key"), []byte("ns"), nil, 0)
+		p.ProcessRequestAsync(request, func(*proto.OperationalMessage, error) {})
+	}
+	stats := p.getStats()
+	if len(stats) != 1 || len(stats[0].Conns) != 3 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+	total := 0
+	for _, c := range stats[0].Conns {
+		if c.Outstanding < 2 || c.Outstanding > 3 {
+			t.Errorf("imbalanced connections %v", stats[0].Conns)
+		}
+		total += int(c.NumRequests)
+	}
+	if total != 7 {
+		t.Errorf("%d requests sent, expected 7", total)
+	}
+}
@@ -0,0 +1,144 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"juno/internal/cli"
+	"juno/pkg/proto"
+)
+
+// RetryPolicy controls how a failed request is retried. A request is only
+// retried if the error is cli.IRetryable and Retryable() returns true.
+//
+// Create, conditional Update and UDFSet are not idempotent: if the request
+// timed out, it may have been committed, and a retry could fail with
+// ErrUniqueKeyViolation or ErrConditionViolation, or apply the UDF twice. Unless
+// RetryNonIdempotent is set, they are only retried on ErrRecordLocked, which
+// the proxy returns before anything is written.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one. 0 or 1 disables retry.
+	MaxAttempts int
+	// Backoff before the n-th retry is a random duration in [0, min(BackoffMax, BackoffBase * 2^(n-1))].
+	BackoffBase Duration
+	BackoffMax  Duration
+	// Deadline is the total time budget of a call, including all retries and
+	// backoffs. No retry is attempted if the budget would be exceeded. 0 means
+	// no limit other than MaxAttempts.
+	Deadline           Duration
+	RetryNonIdempotent bool
+}
+
+type retrierT struct {
+	policy        *RetryPolicy
+	idempotent    bool
+	attempts      int
+	timeStart     time.Time
+	nextBackoffUB time.Duration
+}
+
+func newRetrier(policy *RetryPolicy, request *proto.OperationalMessage) *retrierT {
+	return &retrierT{
+		policy:        policy,
+		idempotent:    policy.RetryNonIdempotent || isIdempotent(request),
+		attempts:      1,
+		timeStart:     time.Now(),
+		nextBackoffUB: policy.BackoffBase.Duration,
+	}
+}
+
+func isIdempotent(request *proto.OperationalMessage) bool {
+	switch request.GetOpCode() {
+	case proto.OpCodeGet, proto.OpCodeSet, proto.OpCodeDestroy, proto.OpCodeUDFGet, proto.OpCodeScan:
+		return true
+	case proto.OpCodeUpdate:
+		return request.GetVersion() == 0 && request.GetCreationTime() == 0
+	}
+	return false
+}
+
+func isRetryable(err error) bool {
+	if r, ok := err.(cli.IRetryable); ok {
+		return r.Retryable()
+	}
+	return false
+}
+
+// backoff returns the duration to wait before the next attempt, and false if
+// err should not be retried.
+func (r *retrierT) backoff(err error) (backoff time.Duration, ok bool) {
+	if err == nil || !isRetryable(err) {
+		return
+	}
+	if !r.idempotent && err != ErrRecordLocked {
+		return
+	}
+	return r.nextBackoff()
+}
+
+// nextBackoff returns the duration to wait before the next attempt, and false if
+// MaxAttempts or Deadline would be exceeded.
+func (r *retrierT) nextBackoff() (backoff time.Duration, ok bool) {
+	if r.attempts >= r.policy.MaxAttempts {
+		return
+	}
+	if r.nextBackoffUB > 0 {
+		backoff = time.Duration(rand.Int63n(int64(r.nextBackoffUB) + 1))
+		r.nextBackoffUB *= 2
+		if max := r.policy.BackoffMax.Duration; max > 0 && r.nextBackoffUB > max {
+			r.nextBackoffUB = max
+		}
+	}
+	if deadline := r.policy.Deadline.Duration; deadline > 0 {
+		if time.Since(r.timeStart)+backoff >= deadline {
+			return
+		}
+	}
+	r.attempts++
+	ok = true
+	return
+}
+
+// shouldRetry waits for the backoff and returns true if err should be retried.
+func (r *retrierT) shouldRetry(ctx context.Context, err error) bool {
+	backoff, ok := r.backoff(err)
+	return ok && wait(ctx, backoff)
+}
+
+// wait returns false if ctx is done before, or would be done by, the end of the
+// backoff.
+func wait(ctx context.Context, backoff time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+		return false
+	}
+	if backoff > 0 {
+		timer := time.NewTimer(backoff)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
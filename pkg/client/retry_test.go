@@ -0,0 +1,77 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"juno/internal/cli"
+	"juno/pkg/proto"
+)
+
+func TestRetrier(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		BackoffBase: Duration{10 * time.Millisecond},
+		BackoffMax:  Duration{15 * time.Millisecond},
+	}
+	get := &proto.OperationalMessage{}
+	get.SetRequest(proto.OpCodeGet, []byte("key"), []byte("ns"), nil, 0)
+	create := &proto.OperationalMessage{}
+	create.SetRequest(proto.OpCodeCreate, []byte("key"), []byte("ns"), nil, 0)
+	ioErr := &cli.IOError{errors.New("request timeout")}
+
+	r := newRetrier(policy, get)
+	for i := 0; i < 2; i++ {
+		backoff, ok := r.backoff(ErrBusy)
+		if !ok {
+			t.Fatalf("attempt %d should be retried", i+2)
+		}
+		if backoff > policy.BackoffMax.Duration {
+			t.Errorf("backoff %s exceeds max", backoff)
+		}
+	}
+	if _, ok := r.backoff(ErrBusy); ok {
+		t.Error("should not retry after MaxAttempts")
+	}
+	if _, ok := newRetrier(policy, get).backoff(ErrNoKey); ok {
+		t.Error("non retryable error should not be retried")
+	}
+
+	if _, ok := newRetrier(policy, create).backoff(ioErr); ok {
+		t.Error("Create should not be retried on IO error")
+	}
+	if _, ok := newRetrier(policy, create).backoff(ErrRecordLocked); !ok {
+		t.Error("Create should be retried on record locked")
+	}
+	unsafe := *policy
+	unsafe.RetryNonIdempotent = true
+	if _, ok := newRetrier(&unsafe, create).backoff(ioErr); !ok {
+		t.Error("Create should be retried if RetryNonIdempotent is set")
+	}
+
+	deadline := *policy
+	deadline.Deadline = Duration{time.Nanosecond}
+	if _, ok := newRetrier(&deadline, get).backoff(ErrBusy); ok {
+		t.Error("should not retry beyond deadline")
+	}
+}
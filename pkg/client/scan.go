@@ -0,0 +1,188 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+
+	"juno/internal/cli"
+	"juno/pkg/proto"
+)
+
+// IScanIterator iterates the records returned by Scan in key order, fetching
+// them from the proxy one page at a time.
+//
+//	it := c.Scan("ns", []byte("user:"))
+//	for it.Next() {
+//		fmt.Println(it.Key(), it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type IScanIterator interface {
+	// Next advances to the next record. It returns false at the end of the
+	// scan or on error.
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Context() IContext
+	// Err returns the error having stopped the iteration, if any.
+	Err() error
+	// Token returns the token to resume the scan after the current record with
+	// WithScanToken.
+	Token() []byte
+}
+
+type scanIteratorT struct {
+	fetch    func(token []byte) (proto.ScanPage, error)
+	keyStore proto.IEncryptionKeyStore
+
+	entries []proto.ScanEntry
+	pos     int
+	token   []byte // token of the next page
+	done    bool   // no more page
+
+	key     []byte
+	value   []byte
+	recInfo *cli.RecordInfo
+	err     error
+}
+
+// WithPageSize sets the number of records Scan fetches per request. The proxy
+// caps it to proto.MaxScanLimit.
+func WithPageSize(size uint32) IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			data.pageSize = size
+		}
+	}
+}
+
+// WithScanToken resumes Scan after the record the token was returned for by
+// IScanIterator.Token.
+func WithScanToken(token []byte) IOption {
+	return func(i interface{}) {
+		if data, ok := i.(*optionData); ok {
+			data.scanToken = token
+		}
+	}
+}
+
+func (c *clientImplT) Scan(ns string, prefix []byte, opts ...IOption) IScanIterator {
+	return c.ScanCtx(context.Background(), ns, prefix, opts...)
+}
+
+// ScanCtx returns the iterator of the records of namespace ns, or of the
+// namespace of the client if ns is empty, with the key prefix. The records
+// are returned in key order, across all the shards.
+func (c *clientImplT) ScanCtx(ctx context.Context, ns string, prefix []byte, opts ...IOption) IScanIterator {
+	if ns == "" {
+		ns = c.namespace
+	}
+	options := c.newOptionData(opts...)
+	return &scanIteratorT{
+		fetch: func(token []byte) (proto.ScanPage, error) {
+			return c.scanPage(ctx, []byte(ns), prefix, token, options)
+		},
+		keyStore: c.config.EncryptionKeyStore,
+		token:    options.scanToken,
+	}
+}
+
+// scanPage returns the page of the records after token.
+func (c *clientImplT) scanPage(ctx context.Context, ns []byte, prefix []byte, token []byte, options *optionData) (page proto.ScanPage, err error) {
+	request := proto.NewScanRequest(ns, prefix, &proto.ScanRequest{
+		Limit: options.pageSize,
+		Token: token,
+	})
+	if c.config.Tenant != "" {
+		request.SetTenant([]byte(c.config.Tenant))
+	}
+	if err = options.applyToRequest(request); err != nil {
+		return
+	}
+
+	var resp *proto.OperationalMessage
+	if resp, err = c.processRequest(ctx, request, nil, options); err == nil {
+		page, err = resp.GetScanPage()
+	}
+	return
+}
+
+func (it *scanIteratorT) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.pos >= len(it.entries) {
+		if it.done {
+			it.key, it.value, it.recInfo = nil, nil, nil
+			return false
+		}
+		var page proto.ScanPage
+		if page, it.err = it.fetch(it.token); it.err != nil {
+			return false
+		}
+		it.entries, it.pos = page.Entries, 0
+		it.token = page.Token
+		it.done = len(page.Token) == 0
+	}
+
+	e := &it.entries[it.pos]
+	it.pos++
+	var ks proto.IEncryptionKeyStore
+	if e.Payload.GetPayloadType() == proto.PayloadTypeEncryptedByClient {
+		ks = it.keyStore
+	}
+	var value []byte
+	if e.Payload.GetLength() != 0 {
+		if value, it.err = e.Payload.GetClearValueWithKeyStore(ks); it.err != nil {
+			return false
+		}
+	}
+	it.key = e.Key
+	it.value = value
+	it.recInfo = &cli.RecordInfo{}
+	it.recInfo.SetFromScanEntry(e)
+	return true
+}
+
+func (it *scanIteratorT) Key() []byte {
+	return it.key
+}
+
+func (it *scanIteratorT) Value() []byte {
+	return it.value
+}
+
+func (it *scanIteratorT) Context() IContext {
+	if it.recInfo == nil {
+		return nil
+	}
+	return it.recInfo
+}
+
+func (it *scanIteratorT) Err() error {
+	return it.err
+}
+
+// the token is the key of the last record returned
+func (it *scanIteratorT) Token() []byte {
+	return it.key
+}
@@ -0,0 +1,88 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"juno/pkg/proto"
+)
+
+func TestScanIterator(t *testing.T) {
+	ks := &testKeyStoreT{keys: [][]byte{bytes.Repeat([]byte{1}, 32)}}
+	var encrypted proto.Payload
+	encrypted.SetWithClearValue([]byte("v3"))
+	if err := encrypted.EncryptWithKeyStore(proto.PayloadTypeEncryptedByClient, ks); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(key string) (e proto.ScanEntry) {
+		e.Key = []byte(key)
+		e.Payload.SetWithClearValue([]byte("v" + key[1:]))
+		e.Version = 1
+		return
+	}
+	pages := map[string]proto.ScanPage{
+		"":   {Entries: []proto.ScanEntry{entry("k1"), entry("k2")}, Token: []byte("k2")},
+		"k2": {Entries: []proto.ScanEntry{{Key: []byte("k3"), Payload: encrypted}}, Token: []byte("k3")},
+		"k3": {},
+	}
+	var tokens []string
+	it := &scanIteratorT{
+		fetch: func(token []byte) (proto.ScanPage, error) {
+			tokens = append(tokens, string(token))
+			return pages[string(token)], nil
+		},
+		keyStore: ks,
+	}
+
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		if string(it.Value()) != "v"+string(it.Key()[1:]) {
+			t.Errorf("key %s: unexpected value %q", it.Key(), it.Value())
+		}
+		if !bytes.Equal(it.Token(), it.Key()) {
+			t.Errorf("unexpected token %q", it.Token())
+		}
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if len(keys) != 3 || keys[2] != "k3" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if len(tokens) != 3 || tokens[1] != "k2" || tokens[2] != "k3" {
+		t.Errorf("unexpected page requests %v", tokens)
+	}
+	if it.Next() || it.Key() != nil {
+		t.Error("scan should be done")
+	}
+
+	errFetch := errors.New("fetch")
+	it = &scanIteratorT{fetch: func(token []byte) (proto.ScanPage, error) {
+		return proto.ScanPage{}, errFetch
+	}}
+	if it.Next() || it.Err() != errFetch {
+		t.Errorf("expected fetch error, got %v", it.Err())
+	}
+}
@@ -0,0 +1,141 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Typed wraps an IClient to store values of type T serialized by an ICodec.
+// Values are written with the codec passed to NewTyped, and can be read with it
+// or with any of the additional codecs, selected by the version byte of the
+// value. To migrate to a new format, switch to the new codec and pass the old
+// one as an additional codec until all the values have been rewritten.
+//
+// Compare-and-set works as with IClient, by passing the IContext returned by
+// Get to Update with WithCond.
+type Typed[T any] struct {
+	client IClient
+	codec  ICodec
+	codecs map[uint8]ICodec
+}
+
+func NewTyped[T any](client IClient, codec ICodec, readCodecs ...ICodec) *Typed[T] {
+	t := &Typed[T]{
+		client: client,
+		codec:  codec,
+		codecs: map[uint8]ICodec{codec.Version(): codec},
+	}
+	for _, c := range readCodecs {
+		if _, ok := t.codecs[c.Version()]; !ok {
+			t.codecs[c.Version()] = c
+		}
+	}
+	return t
+}
+
+// Client returns the underlying client.
+func (t *Typed[T]) Client() IClient {
+	return t.client
+}
+
+func (t *Typed[T]) encode(v T) ([]byte, error) {
+	data, err := t.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, 1+len(data))
+	value[0] = t.codec.Version()
+	copy(value[1:], data)
+	return value, nil
+}
+
+func (t *Typed[T]) decode(value []byte) (v T, err error) {
+	if len(value) == 0 {
+		err = fmt.Errorf("no codec version")
+		return
+	}
+	codec, ok := t.codecs[value[0]]
+	if !ok {
+		err = fmt.Errorf("codec version %d not supported", value[0])
+		return
+	}
+	err = codec.Unmarshal(value[1:], &v)
+	return
+}
+
+func (t *Typed[T]) Create(key []byte, v T, opts ...IOption) (IContext, error) {
+	return t.CreateCtx(context.Background(), key, v, opts...)
+}
+
+func (t *Typed[T]) Get(key []byte, opts ...IOption) (T, IContext, error) {
+	return t.GetCtx(context.Background(), key, opts...)
+}
+
+func (t *Typed[T]) Update(key []byte, v T, opts ...IOption) (IContext, error) {
+	return t.UpdateCtx(context.Background(), key, v, opts...)
+}
+
+func (t *Typed[T]) Set(key []byte, v T, opts ...IOption) (IContext, error) {
+	return t.SetCtx(context.Background(), key, v, opts...)
+}
+
+func (t *Typed[T]) Destroy(key []byte, opts ...IOption) error {
+	return t.client.Destroy(key, opts...)
+}
+
+func (t *Typed[T]) CreateCtx(ctx context.Context, key []byte, v T, opts ...IOption) (IContext, error) {
+	value, err := t.encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.CreateCtx(ctx, key, value, opts...)
+}
+
+// GetCtx returns the error from decoding the value, along with the record
+// context, if the value cannot be decoded.
+func (t *Typed[T]) GetCtx(ctx context.Context, key []byte, opts ...IOption) (v T, context IContext, err error) {
+	var value []byte
+	if value, context, err = t.client.GetCtx(ctx, key, opts...); err == nil {
+		v, err = t.decode(value)
+	}
+	return
+}
+
+func (t *Typed[T]) UpdateCtx(ctx context.Context, key []byte, v T, opts ...IOption) (IContext, error) {
+	value, err := t.encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.UpdateCtx(ctx, key, value, opts...)
+}
+
+func (t *Typed[T]) SetCtx(ctx context.Context, key []byte, v T, opts ...IOption) (IContext, error) {
+	value, err := t.encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.SetCtx(ctx, key, value, opts...)
+}
+
+func (t *Typed[T]) DestroyCtx(ctx context.Context, key []byte, opts ...IOption) error {
+	return t.client.DestroyCtx(ctx, key, opts...)
+}
@@ -0,0 +1,89 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"juno/internal/cli"
+)
+
+type memClientT struct {
+	IClient
+	values map[string][]byte
+}
+
+func (c *memClientT) SetCtx(ctx context.Context, key []byte, value []byte, opts ...IOption) (IContext, error) {
+	c.values[string(key)] = value
+	return &cli.RecordInfo{}, nil
+}
+
+func (c *memClientT) GetCtx(ctx context.Context, key []byte, opts ...IOption) ([]byte, IContext, error) {
+	value, ok := c.values[string(key)]
+	if !ok {
+		return nil, nil, ErrNoKey
+	}
+	return value, &cli.RecordInfo{}, nil
+}
+
+type account struct {
+	Name    string
+	Balance int64
+}
+
+func TestTyped(t *testing.T) {
+	mc := &memClientT{values: map[string][]byte{}}
+	in := account{Name: "a", Balance: 10}
+
+	for _, codec := range []ICodec{JSONCodec, GobCodec} {
+		typed := NewTyped[account](mc, codec)
+		if _, err := typed.Set([]byte("key"), in); err != nil {
+			t.Fatal(err)
+		}
+		if mc.values["key"][0] != codec.Version() {
+			t.Errorf("codec version %d, expected %d", mc.values["key"][0], codec.Version())
+		}
+		if out, _, err := typed.Get([]byte("key")); err != nil || out != in {
+			t.Errorf("codec %d: got %v %v, expected %v", codec.Version(), out, err, in)
+		}
+	}
+
+	// value written with gob above can be read once migrated to JSON
+	if out, _, err := NewTyped[account](mc, JSONCodec, GobCodec).Get([]byte("key")); err != nil || out != in {
+		t.Errorf("got %v %v, expected %v", out, err, in)
+	}
+	if _, _, err := NewTyped[account](mc, JSONCodec).Get([]byte("key")); err == nil {
+		t.Error("unregistered codec version should fail")
+	}
+	if _, _, err := NewTyped[account](mc, JSONCodec).Get([]byte("nokey")); err != ErrNoKey {
+		t.Errorf("got %v, expected ErrNoKey", err)
+	}
+
+	typedPb := NewTyped[*wrapperspb.StringValue](mc, ProtoCodec)
+	if _, err := typedPb.Set([]byte("pb"), wrapperspb.String("value")); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, err := typedPb.Get([]byte("pb")); err != nil || out.GetValue() != "value" {
+		t.Errorf("got %v %v", out, err)
+	}
+}
@@ -435,6 +435,10 @@ func (p *ShardManager) GetShardInfoByKey(key []byte) ([]uint32, []uint32, error)
 	return p.shardMap.GetNodes(uint32(shardid), start_zoneid)
 }
 
+func (p *ShardManager) GetNumShards() uint32 {
+	return p.shardMap.cluster.NumShards
+}
+
 func (p *ShardManager) GetShardMap() *ShardMap {
 	return &p.shardMap
 }
@@ -160,3 +160,22 @@ func KeyRedistNodeState(zone int, node int, shardid int) string {
 func KeyRedistTgtNodeState(zone int, node int) string {
 	return Key(TagRedistTgtStatePrefix, zone, node)
 }
+
+// Keys for namespace purge
+var (
+	TagPurgeNamespace   = "purge_ns"
+	TagPurgeStatePrefix = "purge_state"
+
+	// fields of the node state checked by clustermgr
+	TagPurgeStatus = "st"
+	TagPurgeId     = "id"
+
+	// values
+	TagPurgeStateInprogress = "P"
+	TagPurgeStateFinish     = "F"
+	TagPurgeStateFail       = "E"
+)
+
+func KeyPurgeNodeState(zone int, node int) string {
+	return Key(TagPurgeStatePrefix, zone, node)
+}
@@ -0,0 +1,126 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package etcd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"juno/third_party/forked/golang/glog"
+)
+
+// A namespace purge request is published by clustermgr under purge_ns and
+// applied online by every storage server, which reports its progress under
+// purge_state_<zone>_<node>.
+//
+// The request uses the same TOML layout as the namespace delete file:
+//
+//	[[Delete]]
+//	Namespace = "ns1"
+//	Prefix = ["a", "b"]  # optional; whole namespace if omitted
+type PurgeEntry struct {
+	Namespace string
+	Prefix    []string
+}
+
+type PurgeRequest struct {
+	// Records last modified before PurgeTime (unix nano) are purged.
+	// It also identifies the request.
+	PurgeTime int64
+	Delete    []PurgeEntry
+}
+
+// Reads a purge request from file and stamps it with the current time.
+func NewPurgeRequest(file string) (req *PurgeRequest, err error) {
+	req = &PurgeRequest{}
+	if _, err = toml.DecodeFile(file, req); err != nil {
+		return nil, fmt.Errorf("bad format in %s: %s", file, err)
+	}
+	req.PurgeTime = time.Now().UnixNano()
+	if err = req.validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func DecodePurgeRequest(value string) (req *PurgeRequest, err error) {
+	req = &PurgeRequest{}
+	if _, err = toml.Decode(value, req); err != nil {
+		return nil, err
+	}
+	if err = req.validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (r *PurgeRequest) Encode() (string, error) {
+	buf := new(bytes.Buffer)
+	if err := toml.NewEncoder(buf).Encode(*r); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (r *PurgeRequest) validate() error {
+	if r.PurgeTime <= 0 {
+		return errors.New("purge time not set")
+	}
+	if len(r.Delete) == 0 {
+		return errors.New("no namespace to purge")
+	}
+	for _, e := range r.Delete {
+		if len(e.Namespace) == 0 {
+			return errors.New("namespace cannot be empty")
+		}
+	}
+	return nil
+}
+
+// Returns nil if there is no purge request.
+func (cr *EtcdReader) ReadPurgeRequest() (req *PurgeRequest, err error) {
+	resp, err := cr.etcdcli.get(TagPurgeNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	return DecodePurgeRequest(string(resp.Kvs[0].Value))
+}
+
+// Returns the purge state reported by each node, keyed by etcd key.
+func (cr *EtcdReader) ReadPurgeState() (state map[string]string, err error) {
+	resp, err := cr.etcdcli.getWithPrefix(Key(TagPurgeStatePrefix))
+	if err != nil {
+		glog.Errorf("[ERROR] %s", err)
+		return nil, err
+	}
+
+	state = make(map[string]string, len(resp.Kvs))
+	for _, ev := range resp.Kvs {
+		state[string(ev.Key)] = string(ev.Value)
+	}
+	return state, nil
+}
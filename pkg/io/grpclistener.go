@@ -0,0 +1,153 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"context"
+	"crypto/tls"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/stats"
+
+	"juno/pkg/logging/otel"
+	"juno/pkg/proto/junopb"
+	"juno/pkg/sec"
+	"juno/third_party/forked/golang/glog"
+)
+
+type (
+	// GrpcListener serves the juno gRPC service defined in pkg/proto/junopb.
+	// Requests are translated to juno operational messages and handed to the
+	// same IRequestHandler used by the TCP listeners.
+	GrpcListener struct {
+		Listener
+		server     *grpc.Server
+		connStats  grpcConnStatsT
+		chShutdown chan struct{}
+	}
+
+	// grpcConnStatsT implements stats.Handler to count the connections
+	grpcConnStatsT struct {
+		numConns int32
+	}
+)
+
+func newGrpcListener(ln *Listener) (lsnr IListener, err error) {
+	grpcLsnr := &GrpcListener{
+		Listener: *ln,
+	}
+	grpcLsnr.lsnrType = ListenerTypeGRPC
+	if err = grpcLsnr.newServer(); err != nil {
+		ln.netListener.Close()
+		return
+	}
+	lsnr = grpcLsnr
+	return
+}
+
+func (l *GrpcListener) newServer() (err error) {
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(&l.connStats),
+		grpc.ConnectionTimeout(l.ioConfig.HandshakeTimeout.Duration),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: l.ioConfig.IdleTimeout.Duration,
+		}),
+	}
+	if l.config.SSLEnabled {
+		var cfg *tls.Config
+		if cfg, err = sec.GetServerTlsConfig(); err != nil {
+			return
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg)))
+	}
+	l.server = grpc.NewServer(opts...)
+	junopb.RegisterJunoServer(l.server, &grpcServiceT{lsnr: l})
+	l.chShutdown = make(chan struct{})
+	return
+}
+
+func (l *GrpcListener) AcceptAndServe() error {
+	if err := l.server.Serve(l.netListener); err != nil {
+		return err
+	}
+	// Serve returns nil once the server is stopped
+	return grpc.ErrServerStopped
+}
+
+func (l *GrpcListener) Close() error {
+	l.server.Stop()
+	return nil
+}
+
+func (l *GrpcListener) Shutdown() {
+	go func(server *grpc.Server, chDone chan struct{}) {
+		server.GracefulStop()
+		close(chDone)
+	}(l.server, l.chShutdown)
+}
+
+func (l *GrpcListener) WaitForShutdownToComplete(timeout time.Duration) {
+	select {
+	case <-l.chShutdown:
+	case <-time.After(timeout):
+		glog.Warningf("grpc listener %s: shutdown timed out, closing connections", l.GetName())
+		l.server.Stop()
+	}
+}
+
+func (l *GrpcListener) GetType() ListenerType {
+	return ListenerTypeGRPC
+}
+
+func (l *GrpcListener) GetNumActiveConnections() uint32 {
+	return uint32(atomic.LoadInt32(&l.connStats.numConns))
+}
+
+func (l *GrpcListener) Refresh() {
+	l.Listener.Refresh()
+	if err := l.newServer(); err != nil {
+		glog.Error(err)
+	}
+}
+
+func (h *grpcConnStatsT) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *grpcConnStatsT) HandleRPC(ctx context.Context, s stats.RPCStats) {
+}
+
+func (h *grpcConnStatsT) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *grpcConnStatsT) HandleConn(ctx context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		atomic.AddInt32(&h.numConns, 1)
+		otel.RecordCount(otel.Accept, []otel.Tags{{otel.Status, otel.Success}})
+	case *stats.ConnEnd:
+		atomic.AddInt32(&h.numConns, -1)
+	}
+}
@@ -0,0 +1,119 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"context"
+	goio "io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"juno/pkg/proto"
+	"juno/pkg/proto/junopb"
+)
+
+func TestGrpcListener(t *testing.T) {
+	cfg := ListenerConfig{Name: "grpc"}
+	cfg.Addr = "127.0.0.1:0"
+	cfg.GRPCEnabled = true
+	lsnr, err := NewListener(cfg, DefaultInboundConfig, &fakeKVHandler{recs: make(map[string]*proto.OperationalMessage)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lsnr.GetType() != ListenerTypeGRPC {
+		t.Fatal("grpc listener expected")
+	}
+	go lsnr.AcceptAndServe()
+	defer lsnr.Close()
+
+	conn, err := grpc.Dial(lsnr.(*GrpcListener).netListener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cli := junopb.NewJunoClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, GrpcMetadataAppName, "test", GrpcMetadataCorrelationId, "cid")
+
+	key := []byte("key")
+	resp, err := cli.Get(ctx, &junopb.Request{Namespace: "ns", Key: key})
+	if err != nil || resp.GetStatus() != junopb.Status_STATUS_NO_KEY {
+		t.Fatalf("NoKey expected: %v %v", resp, err)
+	}
+	resp, err = cli.Create(ctx, &junopb.Request{Namespace: "ns", Key: key, Value: []byte("v1"), Ttl: 100})
+	if err != nil || resp.GetStatus() != junopb.Status_STATUS_NO_ERROR || resp.GetVersion() != 1 ||
+		resp.GetCreationTime() != 1000 || resp.GetTtl() != 100 || len(resp.GetRequestId()) == 0 {
+		t.Fatalf("create: %v %v", resp, err)
+	}
+	resp, err = cli.Create(ctx, &junopb.Request{Namespace: "ns", Key: key, Value: []byte("v1")})
+	if err != nil || resp.GetStatus() != junopb.Status_STATUS_DUP_KEY {
+		t.Fatalf("DupKey expected: %v %v", resp, err)
+	}
+	resp, err = cli.Update(ctx, &junopb.Request{Namespace: "ns", Key: key, Value: []byte("v2"), Version: 2})
+	if err != nil || resp.GetStatus() != junopb.Status_STATUS_VERSION_CONFLICT {
+		t.Fatalf("VersionConflict expected: %v %v", resp, err)
+	}
+
+	stream, err := cli.Batch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := []*junopb.Request{
+		{Op: junopb.OpCode_OP_SET, Namespace: "ns", Key: []byte("k1"), Value: []byte("a"), Id: 1},
+		{Op: junopb.OpCode_OP_GET, Namespace: "ns", Key: key, Id: 2},
+		{Op: junopb.OpCode_OP_NOP, Namespace: "ns", Key: key, Id: 3},
+	}
+	for _, req := range reqs {
+		if err = stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stream.CloseSend()
+	results := make(map[uint32]*junopb.Response)
+	for {
+		resp, err := stream.Recv()
+		if err == goio.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[resp.GetId()] = resp
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("%d responses expected, got %d", len(reqs), len(results))
+	}
+	if results[1].GetStatus() != junopb.Status_STATUS_NO_ERROR {
+		t.Errorf("set: %v", results[1])
+	}
+	if results[2].GetStatus() != junopb.Status_STATUS_NO_ERROR || string(results[2].GetValue()) != "v1" {
+		t.Errorf("get: %v", results[2])
+	}
+	if results[3].GetStatus() != junopb.Status_STATUS_BAD_PARAM {
+		t.Errorf("BadParam expected for Nop: %v", results[3])
+	}
+}
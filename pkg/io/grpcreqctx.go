@@ -0,0 +1,240 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"context"
+	goio "io"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"juno/pkg/proto"
+	"juno/pkg/proto/junopb"
+	"juno/third_party/forked/golang/glog"
+)
+
+const (
+	GrpcMetadataAppName       = "x-juno-appname"
+	GrpcMetadataCorrelationId = "x-juno-correlation-id"
+	GrpcMetadataTenant        = "x-juno-tenant"
+	GrpcMetadataTraceParent   = "traceparent"
+
+	// max number of requests of a Batch stream processed concurrently
+	kGrpcMaxBatchConcurrency = 256
+)
+
+type grpcServiceT struct {
+	junopb.UnimplementedJunoServer
+	lsnr *GrpcListener
+}
+
+func (s *grpcServiceT) Create(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeCreate, req)
+}
+
+func (s *grpcServiceT) Get(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeGet, req)
+}
+
+func (s *grpcServiceT) Update(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeUpdate, req)
+}
+
+func (s *grpcServiceT) Set(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeSet, req)
+}
+
+func (s *grpcServiceT) Destroy(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeDestroy, req)
+}
+
+func (s *grpcServiceT) UDFGet(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeUDFGet, req)
+}
+
+func (s *grpcServiceT) UDFSet(ctx context.Context, req *junopb.Request) (*junopb.Response, error) {
+	return s.process(ctx, proto.OpCodeUDFSet, req)
+}
+
+func (s *grpcServiceT) Batch(stream junopb.Juno_BatchServer) (err error) {
+	ctx := stream.Context()
+	var wg sync.WaitGroup
+	var mtx sync.Mutex // guards stream.Send and sendErr
+	var sendErr error
+	sem := make(chan struct{}, kGrpcMaxBatchConcurrency)
+
+	for {
+		var req *junopb.Request
+		if req, err = stream.Recv(); err != nil {
+			if err == goio.EOF {
+				err = nil
+			}
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(req *junopb.Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var resp *junopb.Response
+			if op, ok := grpcToJunoOpCode(req.GetOp()); ok {
+				var e error
+				if resp, e = s.process(ctx, op, req); e != nil {
+					resp = &junopb.Response{Status: grpcErrorToStatus(e)}
+				}
+			} else {
+				resp = &junopb.Response{Status: junopb.Status_STATUS_BAD_PARAM}
+			}
+			resp.Id = req.GetId()
+
+			mtx.Lock()
+			if sendErr == nil {
+				sendErr = stream.Send(resp)
+			}
+			mtx.Unlock()
+		}(req)
+	}
+	wg.Wait()
+	if err == nil {
+		err = sendErr
+	}
+	return
+}
+
+func (s *grpcServiceT) process(ctx context.Context, op proto.OpCode, req *junopb.Request) (resp *junopb.Response, err error) {
+	var msg proto.OperationalMessage
+	setRequestFromGrpc(ctx, &msg, op, req)
+
+	var rctx *syncRequestContext
+	if rctx, err = newSyncRequestContext(&msg); err != nil {
+		glog.Errorf("fail to encode grpc request. %s", err.Error())
+		err = status.Error(codes.Internal, err.Error())
+		return
+	}
+	timeout := s.lsnr.ioConfig.RequestTimeout.Duration
+	rctx.SetTimeout(ctx, timeout)
+	go s.lsnr.reqHandler.Process(rctx)
+
+	timer := time.NewTimer(2 * timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-rctx.chReply:
+		resp, err = newGrpcResponse(r)
+		r.OnComplete()
+	case <-timer.C:
+		rctx.abandon()
+		err = status.Error(codes.DeadlineExceeded, "request timed out")
+	case <-ctx.Done():
+		rctx.abandon()
+		err = status.FromContextError(ctx.Err()).Err()
+	}
+	return
+}
+
+func grpcToJunoOpCode(op junopb.OpCode) (opCode proto.OpCode, ok bool) {
+	if op >= junopb.OpCode_OP_CREATE && op <= junopb.OpCode_OP_UDF_SET {
+		opCode = proto.OpCode(op)
+		ok = true
+	}
+	return
+}
+
+func grpcErrorToStatus(err error) junopb.Status {
+	if status.Code(err) == codes.DeadlineExceeded {
+		return junopb.Status_STATUS_REQ_PROC_TIMEOUT
+	}
+	return junopb.Status_STATUS_INTERNAL
+}
+
+func setRequestFromGrpc(ctx context.Context, m *proto.OperationalMessage, op proto.OpCode, req *junopb.Request) {
+	var payload proto.Payload
+	payload.SetWithClearValue(req.GetValue())
+	m.SetRequest(op, req.GetKey(), []byte(req.GetNamespace()), &payload, req.GetTtl())
+	if v := req.GetVersion(); v != 0 {
+		m.SetVersion(v)
+	}
+	if ct := req.GetCreationTime(); ct != 0 {
+		m.SetCreationTime(ct)
+	}
+	if name := req.GetUdfName(); len(name) != 0 {
+		m.SetUDFName([]byte(name))
+	}
+
+	var appName []byte
+	var features proto.Feature
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(GrpcMetadataAppName); len(v) != 0 {
+			appName = []byte(v[0])
+		}
+		if v := md.Get(GrpcMetadataCorrelationId); len(v) != 0 {
+			m.SetCorrelationID([]byte(v[0]))
+		}
+		if v := md.Get(GrpcMetadataTenant); len(v) != 0 {
+			features |= proto.FeatureTenant
+			m.SetTenant([]byte(v[0]))
+		}
+		if v := md.Get(GrpcMetadataTraceParent); len(v) != 0 {
+			features |= proto.FeatureTraceContext
+			m.SetTraceContext([]byte(v[0]))
+		}
+	}
+	if features != 0 {
+		m.EnableFeatures(features)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if addr, ok := p.Addr.(*net.TCPAddr); ok {
+			m.SetSource(addr.IP, uint16(addr.Port), appName)
+		}
+	}
+	m.SetNewRequestID()
+}
+
+func newGrpcResponse(r IResponseContext) (resp *junopb.Response, err error) {
+	var msg proto.OperationalMessage
+	if err = msg.Decode(r.GetMessage()); err != nil {
+		glog.Errorf("fail to decode response. %s", err.Error())
+		err = status.Error(codes.Internal, err.Error())
+		return
+	}
+	resp = &junopb.Response{
+		Status:       junopb.Status(msg.GetOpStatus()),
+		Version:      msg.GetVersion(),
+		CreationTime: msg.GetCreationTime(),
+		Ttl:          msg.GetTimeToLive(),
+		RequestId:    msg.GetRequestIDString(),
+	}
+	if msg.GetPayloadValueLength() != 0 {
+		if resp.Value, err = msg.GetPayload().GetClearValue(); err != nil {
+			glog.Errorf("fail to get value. %s", err.Error())
+			err = status.Error(codes.Internal, err.Error())
+			resp = nil
+		}
+	}
+	return
+}
@@ -0,0 +1,148 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"juno/pkg/logging/otel"
+	"juno/third_party/forked/golang/glog"
+)
+
+type (
+	// HttpListener serves the REST gateway. Requests are translated to juno
+	// operational messages and handed to the same IRequestHandler used by the
+	// TCP listeners.
+	HttpListener struct {
+		Listener
+		server     *http.Server
+		numConns   int32
+		chShutdown chan struct{}
+	}
+)
+
+func newHttpListener(ln *Listener) (lsnr *HttpListener) {
+	lsnr = &HttpListener{
+		Listener: *ln,
+	}
+	lsnr.lsnrType = ListenerTypeHTTP
+	lsnr.newServer()
+	return
+}
+
+func (l *HttpListener) newServer() {
+	l.server = &http.Server{
+		Handler:     l,
+		ReadTimeout: l.ioConfig.ReadTimeout.Duration,
+		// the write deadline is set once the request header is read, so it
+		// has to cover the request processing time as well
+		WriteTimeout: l.ioConfig.RequestTimeout.Duration + l.ioConfig.WriteTimeout.Duration,
+		IdleTimeout:  l.ioConfig.IdleTimeout.Duration,
+		ConnState:    l.onConnState,
+	}
+	l.chShutdown = make(chan struct{})
+}
+
+func (l *HttpListener) onConnState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		atomic.AddInt32(&l.numConns, 1)
+		otel.RecordCount(otel.Accept, []otel.Tags{{otel.Status, otel.Success}})
+	case http.StateHijacked, http.StateClosed:
+		atomic.AddInt32(&l.numConns, -1)
+	}
+}
+
+func (l *HttpListener) AcceptAndServe() error {
+	return l.server.Serve(l.netListener)
+}
+
+func (l *HttpListener) Close() error {
+	return l.server.Close()
+}
+
+func (l *HttpListener) Shutdown() {
+	go func(server *http.Server, chDone chan struct{}) {
+		if err := server.Shutdown(context.Background()); err != nil {
+			glog.Warningf("http listener shutdown: %s", err.Error())
+		}
+		close(chDone)
+	}(l.server, l.chShutdown)
+}
+
+func (l *HttpListener) WaitForShutdownToComplete(timeout time.Duration) {
+	select {
+	case <-l.chShutdown:
+	case <-time.After(timeout):
+		glog.Warningf("http listener %s: shutdown timed out, closing connections", l.GetName())
+		l.server.Close()
+	}
+}
+
+func (l *HttpListener) GetType() ListenerType {
+	return ListenerTypeHTTP
+}
+
+func (l *HttpListener) GetNumActiveConnections() uint32 {
+	return uint32(atomic.LoadInt32(&l.numConns))
+}
+
+func (l *HttpListener) Refresh() {
+	l.Listener.Refresh()
+	l.newServer()
+}
+
+func (l *HttpListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request httpRequest
+	if status, err := request.parse(r); err != nil {
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", kHttpAllowedMethods)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	ctx, err := newSyncRequestContext(&request.msg)
+	if err != nil {
+		glog.Errorf("fail to encode http request. %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	timeout := l.ioConfig.RequestTimeout.Duration
+	ctx.SetTimeout(r.Context(), timeout)
+	go l.reqHandler.Process(ctx)
+
+	timer := time.NewTimer(2 * timeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-ctx.chReply:
+		writeHttpResponse(w, request.msg.GetOpCode(), resp)
+		resp.OnComplete()
+	case <-timer.C:
+		ctx.abandon()
+		http.Error(w, "request timed out", http.StatusGatewayTimeout)
+	case <-r.Context().Done():
+		ctx.abandon()
+	}
+}
@@ -0,0 +1,237 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"encoding/binary"
+	goio "io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"juno/pkg/proto"
+)
+
+// fakeKVHandler is an in-memory IRequestHandler keeping one version per key
+type fakeKVHandler struct {
+	mtx  sync.Mutex
+	recs map[string]*proto.OperationalMessage
+}
+
+func (h *fakeKVHandler) Init()                                          {}
+func (h *fakeKVHandler) Finish()                                        {}
+func (h *fakeKVHandler) GetReqCtxCreator() InboundRequestContextCreator { return nil }
+func (h *fakeKVHandler) OnKeepAlive(c *Connector, r IRequestContext) error {
+	return nil
+}
+
+func (h *fakeKVHandler) Process(reqCtx IRequestContext) error {
+	var req proto.OperationalMessage
+	if err := req.Decode(reqCtx.GetMessage()); err != nil {
+		return err
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	resp := req.CreateResponse()
+	k := string(req.GetNamespace()) + "/" + string(req.GetKey())
+	rec, found := h.recs[k]
+	st := proto.OpStatusNoError
+
+	switch req.GetOpCode() {
+	case proto.OpCodeGet:
+		if !found {
+			st = proto.OpStatusNoKey
+		} else {
+			resp.SetPayload(rec.GetPayload())
+			// the TTL is only extended
+			if req.GetTimeToLive() > rec.GetTimeToLive() {
+				rec.SetTimeToLive(req.GetTimeToLive())
+			}
+		}
+	case proto.OpCodeCreate, proto.OpCodeSet, proto.OpCodeUpdate:
+		if found && req.GetOpCode() == proto.OpCodeCreate {
+			st = proto.OpStatusDupKey
+			break
+		}
+		if req.GetOpCode() == proto.OpCodeUpdate {
+			if !found {
+				st = proto.OpStatusNoKey
+				break
+			}
+			if req.GetVersion() != 0 && rec.GetVersion() != req.GetVersion() {
+				st = proto.OpStatusVersionConflict
+				break
+			}
+		}
+		if !found {
+			rec = &proto.OperationalMessage{}
+			rec.SetCreationTime(1000)
+			h.recs[k] = rec
+		}
+		value, _ := req.GetPayload().GetClearValue()
+		var payload proto.Payload
+		payload.SetWithClearValue(append([]byte{}, value...))
+		rec.SetPayload(&payload)
+		rec.SetVersion(rec.GetVersion() + 1)
+		if ttl := req.GetTimeToLive(); ttl != 0 {
+			rec.SetTimeToLive(ttl)
+		}
+	case proto.OpCodeUDFSet:
+		var counter uint64
+		if found {
+			v, _ := rec.GetPayload().GetClearValue()
+			counter = binary.BigEndian.Uint64(v)
+		} else {
+			rec = &proto.OperationalMessage{}
+			h.recs[k] = rec
+		}
+		delta, _ := req.GetPayload().GetClearValue()
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, counter+binary.BigEndian.Uint64(delta))
+		var payload proto.Payload
+		payload.SetWithClearValue(value)
+		rec.SetPayload(&payload)
+		rec.SetVersion(rec.GetVersion() + 1)
+		// the response carries the result of the udf
+		resp.SetPayload(&payload)
+	case proto.OpCodeDestroy:
+		delete(h.recs, k)
+		rec = nil
+	}
+	if st == proto.OpStatusNoError && rec != nil {
+		resp.SetVersion(rec.GetVersion())
+		resp.SetCreationTime(rec.GetCreationTime())
+		resp.SetTimeToLive(rec.GetTimeToLive())
+	}
+	resp.SetOpStatus(st)
+	var raw proto.RawMessage
+	resp.Encode(&raw)
+	reqCtx.Reply(NewInboundRespose(req.GetOpCode(), &raw))
+	return nil
+}
+
+func TestHttpListener(t *testing.T) {
+	cfg := ListenerConfig{Name: "http"}
+	cfg.Addr = "127.0.0.1:0"
+	cfg.HTTPEnabled = true
+	lsnr, err := NewListener(cfg, DefaultInboundConfig, &fakeKVHandler{recs: make(map[string]*proto.OperationalMessage)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lsnr.GetType() != ListenerTypeHTTP {
+		t.Fatal("http listener expected")
+	}
+	go lsnr.AcceptAndServe()
+	defer lsnr.Close()
+
+	base := "http://" + lsnr.(*HttpListener).netListener.Addr().String()
+	url := base + "/v1/ns/ns1/keys/a%2Fb"
+
+	do := func(method string, url string, body string, header map[string]string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := goio.ReadAll(resp.Body)
+		return resp, string(b)
+	}
+
+	tests := []struct {
+		method  string
+		url     string
+		body    string
+		header  map[string]string
+		code    int
+		value   string
+		version string
+	}{
+		{"GET", url, "", nil, http.StatusNotFound, "", ""},
+		{"POST", url, "v1", map[string]string{HttpHeaderTTL: "100"}, http.StatusCreated, "", "1"},
+		{"POST", url, "v1", nil, http.StatusConflict, "", ""},
+		{"PUT", url, "v2", map[string]string{"If-Match": `"1"`, HttpHeaderTTL: "100"}, http.StatusOK, "", "2"},
+		{"PUT", url, "v3", map[string]string{"If-Match": `"1"`}, http.StatusPreconditionFailed, "", ""},
+		{"PUT", url, "v3", map[string]string{"If-None-Match": "*"}, http.StatusConflict, "", ""},
+		{"GET", url, "", map[string]string{HttpHeaderCorrelationId: "cid"}, http.StatusOK, "v2", "2"},
+		{"PUT", url, "v3", map[string]string{"If-Match": "x"}, http.StatusBadRequest, "", ""},
+		{"DELETE", url, "", nil, http.StatusOK, "", ""},
+		{"GET", url, "", nil, http.StatusNotFound, "", ""},
+		{"PATCH", url, "", nil, http.StatusMethodNotAllowed, "", ""},
+		{"GET", base + "/v1/ns/ns1/a", "", nil, http.StatusNotFound, "", ""},
+	}
+	for i, tc := range tests {
+		resp, body := do(tc.method, tc.url, tc.body, tc.header)
+		if resp.StatusCode != tc.code {
+			t.Errorf("%d: %s expected status %d, got %d", i, tc.method, tc.code, resp.StatusCode)
+			continue
+		}
+		if len(tc.value) != 0 && body != tc.value {
+			t.Errorf("%d: expected value %s, got %s", i, tc.value, body)
+		}
+		if len(tc.version) != 0 {
+			if v := resp.Header.Get(HttpHeaderVersion); v != tc.version {
+				t.Errorf("%d: expected version %s, got %s", i, tc.version, v)
+			}
+			if resp.Header.Get(HttpHeaderCreationTime) != "1000" || resp.Header.Get(HttpHeaderTTL) != "100" {
+				t.Errorf("%d: metadata missing in %v", i, resp.Header)
+			}
+		}
+	}
+}
+
+// countingResponse counts the calls to OnComplete
+type countingResponse struct {
+	ResponseContext
+	completed int
+}
+
+func (r *countingResponse) OnComplete() {
+	r.completed++
+}
+
+func TestSyncRequestContextAbandon(t *testing.T) {
+	var m proto.OperationalMessage
+	m.SetRequest(proto.OpCodeGet, []byte("k"), []byte("ns"), nil, 0)
+
+	// replied before, or after, the listener stops waiting
+	for _, replyFirst := range []bool{true, false} {
+		ctx, err := newSyncRequestContext(&m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp := &countingResponse{}
+		if replyFirst {
+			ctx.Reply(resp)
+		}
+		ctx.abandon()
+		if !replyFirst {
+			ctx.Reply(resp)
+		}
+		if resp.completed != 1 {
+			t.Errorf("reply first=%v: OnComplete called %d times, expected once", replyFirst, resp.completed)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"errors"
+	"fmt"
+	goio "io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"juno/pkg/proto"
+	"juno/third_party/forked/golang/glog"
+)
+
+// REST gateway
+//
+//	GET    /v1/ns/{namespace}/keys/{key}   Get
+//	PUT    /v1/ns/{namespace}/keys/{key}   Set, Update if If-Match is given,
+//	                                       Create if If-None-Match: *
+//	POST   /v1/ns/{namespace}/keys/{key}   Create
+//	DELETE /v1/ns/{namespace}/keys/{key}   Destroy
+//
+// Request headers
+//
+//	X-Juno-TTL             time to live in seconds. For GET, extends the TTL
+//	If-Match               record version for a conditional update
+//	X-Juno-Creation-Time   record creation time for a conditional update
+//	X-Juno-Correlation-Id  correlation id
+//	traceparent            W3C trace context
+//
+// Response headers carry the record metadata: ETag/X-Juno-Version,
+// X-Juno-Creation-Time, X-Juno-TTL, along with X-Juno-Status and
+// X-Juno-Request-Id.
+const (
+	kHttpKeyPathPrefix  = "/v1/ns/"
+	kHttpKeysSegment    = "keys"
+	kHttpAllowedMethods = "GET, PUT, POST, DELETE"
+	kHttpMaxBodySize    = 4 << 20
+
+	HttpHeaderTTL           = "X-Juno-TTL"
+	HttpHeaderVersion       = "X-Juno-Version"
+	HttpHeaderCreationTime  = "X-Juno-Creation-Time"
+	HttpHeaderCorrelationId = "X-Juno-Correlation-Id"
+	HttpHeaderStatus        = "X-Juno-Status"
+	HttpHeaderRequestId     = "X-Juno-Request-Id"
+	HttpHeaderTraceParent   = "traceparent"
+)
+
+var (
+	errHttpNotFound         = errors.New("not found")
+	errHttpMethodNotAllowed = errors.New("method not allowed")
+	errHttpBodyTooLarge     = errors.New("request body too large")
+)
+
+type (
+	httpRequest struct {
+		msg proto.OperationalMessage
+	}
+)
+
+func parseHttpKeyPath(r *http.Request) (namespace []byte, key []byte, err error) {
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, kHttpKeyPathPrefix) {
+		err = errHttpNotFound
+		return
+	}
+	segs := strings.Split(strings.TrimPrefix(path, kHttpKeyPathPrefix), "/")
+	if len(segs) != 3 || segs[1] != kHttpKeysSegment || len(segs[0]) == 0 || len(segs[2]) == 0 {
+		err = errHttpNotFound
+		return
+	}
+	var ns, k string
+	if ns, err = url.PathUnescape(segs[0]); err != nil {
+		return
+	}
+	if k, err = url.PathUnescape(segs[2]); err != nil {
+		return
+	}
+	namespace = []byte(ns)
+	key = []byte(k)
+	return
+}
+
+func parseHttpUint32(h http.Header, name string) (value uint32, err error) {
+	str := h.Get(name)
+	if len(str) == 0 {
+		return
+	}
+	var v uint64
+	if v, err = strconv.ParseUint(str, 10, 32); err != nil {
+		err = fmt.Errorf("invalid %s header: %s", name, str)
+		return
+	}
+	value = uint32(v)
+	return
+}
+
+func parseHttpVersion(etag string) (version uint32, err error) {
+	str := strings.TrimSpace(etag)
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		str = str[1 : len(str)-1]
+	}
+	var v uint64
+	if v, err = strconv.ParseUint(str, 10, 32); err != nil || v == 0 {
+		err = fmt.Errorf("invalid If-Match header: %s", etag)
+		return
+	}
+	version = uint32(v)
+	return
+}
+
+// parse maps the http request to a juno request. On error, the http status
+// code to reply with is returned.
+func (req *httpRequest) parse(r *http.Request) (status int, err error) {
+	var namespace, key []byte
+	if namespace, key, err = parseHttpKeyPath(r); err != nil {
+		status = http.StatusNotFound
+		return
+	}
+	var ttl uint32
+	if ttl, err = parseHttpUint32(r.Header, HttpHeaderTTL); err != nil {
+		status = http.StatusBadRequest
+		return
+	}
+	var opCode proto.OpCode
+	var version, creationTime uint32
+	var withPayload bool
+
+	switch r.Method {
+	case http.MethodGet:
+		opCode = proto.OpCodeGet
+	case http.MethodPut:
+		withPayload = true
+		if etag := r.Header.Get("If-Match"); len(etag) != 0 {
+			opCode = proto.OpCodeUpdate
+			if version, err = parseHttpVersion(etag); err != nil {
+				status = http.StatusBadRequest
+				return
+			}
+			if creationTime, err = parseHttpUint32(r.Header, HttpHeaderCreationTime); err != nil {
+				status = http.StatusBadRequest
+				return
+			}
+		} else if strings.TrimSpace(r.Header.Get("If-None-Match")) == "*" {
+			opCode = proto.OpCodeCreate
+		} else {
+			opCode = proto.OpCodeSet
+		}
+	case http.MethodPost:
+		opCode = proto.OpCodeCreate
+		withPayload = true
+	case http.MethodDelete:
+		opCode = proto.OpCodeDestroy
+	default:
+		status = http.StatusMethodNotAllowed
+		err = errHttpMethodNotAllowed
+		return
+	}
+
+	var payload *proto.Payload
+	if withPayload {
+		var value []byte
+		if value, err = goio.ReadAll(goio.LimitReader(r.Body, kHttpMaxBodySize+1)); err != nil {
+			status = http.StatusBadRequest
+			return
+		}
+		if len(value) > kHttpMaxBodySize {
+			status = http.StatusRequestEntityTooLarge
+			err = errHttpBodyTooLarge
+			return
+		}
+		payload = &proto.Payload{}
+		payload.SetWithClearValue(value)
+	}
+	m := &req.msg
+	m.SetRequest(opCode, key, namespace, payload, ttl)
+	if version != 0 {
+		m.SetVersion(version)
+	}
+	if creationTime != 0 {
+		m.SetCreationTime(creationTime)
+	}
+	if id := r.Header.Get(HttpHeaderCorrelationId); len(id) != 0 {
+		m.SetCorrelationID([]byte(id))
+	}
+	if tp := r.Header.Get(HttpHeaderTraceParent); len(tp) != 0 {
+		m.EnableFeatures(proto.FeatureTraceContext)
+		m.SetTraceContext([]byte(tp))
+	}
+	if host, port, e := net.SplitHostPort(r.RemoteAddr); e == nil {
+		if p, e := strconv.Atoi(port); e == nil {
+			m.SetSource(net.ParseIP(host), uint16(p), []byte(r.UserAgent()))
+		}
+	}
+	m.SetNewRequestID()
+	return
+}
+
+// HttpStatusFromOpStatus translates a juno response status to the http
+// status code returned by the REST gateway.
+func HttpStatusFromOpStatus(opCode proto.OpCode, st proto.OpStatus) int {
+	switch st {
+	case proto.OpStatusNoError, proto.OpStatusInconsistent:
+		if opCode == proto.OpCodeCreate {
+			return http.StatusCreated
+		}
+		return http.StatusOK
+	case proto.OpStatusNoKey, proto.OpStatusKeyMarkedDelete:
+		return http.StatusNotFound
+	case proto.OpStatusDupKey:
+		return http.StatusConflict
+	case proto.OpStatusVersionConflict:
+		return http.StatusPreconditionFailed
+	case proto.OpStatusRecordLocked:
+		return http.StatusLocked
+	case proto.OpStatusBadParam, proto.OpStatusBadMsg:
+		return http.StatusBadRequest
+	case proto.OpStatusServiceDenied:
+		return http.StatusForbidden
+	case proto.OpStatusNoStorageServer, proto.OpStatusBusy:
+		return http.StatusServiceUnavailable
+	case proto.OpStatusReqProcTimeout:
+		return http.StatusGatewayTimeout
+	case proto.OpStatusNotSupported:
+		return http.StatusNotImplemented
+	case proto.OpStatusUDFError, proto.OpStatusNoUDF:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+func writeHttpResponse(w http.ResponseWriter, opCode proto.OpCode, resp IResponseContext) {
+	var msg proto.OperationalMessage
+	if err := msg.Decode(resp.GetMessage()); err != nil {
+		glog.Errorf("fail to decode response. %s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	st := msg.GetOpStatus()
+	h := w.Header()
+	h.Set(HttpHeaderStatus, st.String())
+	h.Set(HttpHeaderRequestId, msg.GetRequestIDString())
+	if ver := msg.GetVersion(); ver != 0 {
+		h.Set("ETag", strconv.Quote(strconv.FormatUint(uint64(ver), 10)))
+		h.Set(HttpHeaderVersion, strconv.FormatUint(uint64(ver), 10))
+	}
+	if ct := msg.GetCreationTime(); ct != 0 {
+		h.Set(HttpHeaderCreationTime, strconv.FormatUint(uint64(ct), 10))
+	}
+	if ttl := msg.GetTimeToLive(); ttl != 0 {
+		h.Set(HttpHeaderTTL, strconv.FormatUint(uint64(ttl), 10))
+	}
+	code := HttpStatusFromOpStatus(opCode, st)
+
+	var value []byte
+	if code < http.StatusMultipleChoices {
+		if msg.GetPayloadValueLength() != 0 {
+			var err error
+			if value, err = msg.GetPayload().GetClearValue(); err != nil {
+				glog.Errorf("fail to get value. %s", err.Error())
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		h.Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(code)
+		w.Write(value)
+		return
+	}
+	http.Error(w, st.String(), code)
+}
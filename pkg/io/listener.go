@@ -34,6 +34,9 @@ import (
 const (
 	ListenerTypeTCP = ListenerType(iota)
 	ListenerTypeTCPwSSL
+	ListenerTypeHTTP
+	ListenerTypeGRPC
+	ListenerTypeRESP
 )
 
 type (
@@ -84,7 +87,13 @@ func NewListener(cfg ListenerConfig, iocfg InboundConfig, reqHandler IRequestHan
 		ln.config.Network = "tcp"
 	}
 	if ln.netListener, err = net.Listen(ln.config.Network, ln.config.Addr); err == nil {
-		if cfg.SSLEnabled {
+		if cfg.HTTPEnabled {
+			lsnr = newHttpListener(ln)
+		} else if cfg.GRPCEnabled {
+			lsnr, err = newGrpcListener(ln)
+		} else if cfg.RESPEnabled {
+			lsnr = newRespListener(ln)
+		} else if cfg.SSLEnabled {
 			sslLsnr := &SslListener{
 				Listener: *ln,
 			}
@@ -112,7 +121,13 @@ func NewListenerWithFd(cfg ListenerConfig, iocfg InboundConfig, f *os.File, reqH
 		ln.config.Network = "tcp"
 	}
 	if ln.netListener, err = net.FileListener(f); err == nil {
-		if cfg.SSLEnabled {
+		if cfg.HTTPEnabled {
+			lsnr = newHttpListener(ln)
+		} else if cfg.GRPCEnabled {
+			lsnr, err = newGrpcListener(ln)
+		} else if cfg.RESPEnabled {
+			lsnr = newRespListener(ln)
+		} else if cfg.SSLEnabled {
 			sslLsnr := &SslListener{
 				Listener: *ln,
 			}
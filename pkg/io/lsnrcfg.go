@@ -33,7 +33,12 @@ type (
 
 	ListenerConfig struct {
 		ServiceEndpoint
-		Name string
+		Name        string
+		HTTPEnabled bool
+		GRPCEnabled bool
+		RESPEnabled bool
+		// Namespace of the requests of a RESP listener
+		Namespace string
 	}
 )
 
@@ -72,6 +77,17 @@ func (p *ServiceEndpoint) SetFromConnString(connStr string) error {
 	return nil
 }
 
+func (cfg *ListenerConfig) GetConnString() string {
+	if cfg.HTTPEnabled {
+		return "http:" + cfg.ServiceEndpoint.GetConnString()
+	} else if cfg.GRPCEnabled {
+		return "grpc:" + cfg.ServiceEndpoint.GetConnString()
+	} else if cfg.RESPEnabled {
+		return "resp:" + cfg.ServiceEndpoint.GetConnString()
+	}
+	return cfg.ServiceEndpoint.GetConnString()
+}
+
 func (cfg *ListenerConfig) SetDefaultIfNotDefined() {
 	///TODO
 }
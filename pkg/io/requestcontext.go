@@ -24,6 +24,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
 	"time"
 
 	"juno/third_party/forked/golang/glog"
@@ -80,6 +81,17 @@ type (
 		RequestContext
 	}
 
+	// syncRequestContext is used by the listeners serving a request in the
+	// goroutine of its own, waiting on chReply for the response, and calling
+	// abandon if they stop waiting
+	syncRequestContext struct {
+		RequestContext
+		chReply chan IResponseContext
+
+		mtx       sync.Mutex
+		abandoned bool
+	}
+
 	// Implement IResponseContext
 	ResponseContext struct {
 		message proto.RawMessage
@@ -150,6 +162,19 @@ func NewInboundRequestContext(c *Connector) (r *InboundRequestContext) {
 	return
 }
 
+func newSyncRequestContext(msg *proto.OperationalMessage) (r *syncRequestContext, err error) {
+	ch := make(chan IResponseContext, 1)
+	r = &syncRequestContext{
+		RequestContext: RequestContext{
+			chResponse:   ch,
+			timeReceived: time.Now(),
+		},
+		chReply: ch,
+	}
+	err = msg.Encode(&r.message)
+	return
+}
+
 func NewOutboundRequestContext(msg *proto.RawMessage, opaque uint32,
 	ctx context.Context, ch chan<- IResponseContext, to time.Duration) (r *OutboundRequestContext) {
 	r = &OutboundRequestContext{
@@ -284,6 +309,37 @@ func (r *OutboundRequestContext) Reply(resp IResponseContext) {
 	}
 }
 
+// Reply never blocks. The response is dropped if the listener is no longer
+// waiting for it.
+func (r *syncRequestContext) Reply(resp IResponseContext) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.abandoned {
+		glog.Debugf("response dropped")
+		resp.OnComplete()
+		return
+	}
+	select {
+	case r.chReply <- resp:
+	default:
+		glog.Debugf("response dropped")
+		resp.OnComplete()
+	}
+}
+
+// abandon is called by the listener no longer waiting for the response, for
+// the response replied already, or later, to be completed.
+func (r *syncRequestContext) abandon() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.abandoned = true
+	select {
+	case resp := <-r.chReply:
+		resp.OnComplete()
+	default:
+	}
+}
+
 func (r *OutboundRequestContext) OnCleanup() {
 
 	glog.Debugf("RB cleanup")
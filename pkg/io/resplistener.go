@@ -0,0 +1,220 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"juno/pkg/io/ioutil"
+	"juno/pkg/logging/otel"
+	"juno/third_party/forked/golang/glog"
+)
+
+const (
+	DefaultRespNamespace = "resp"
+)
+
+type (
+	// RespListener serves a subset of the Redis protocol (RESP). See
+	// respreqctx.go for the supported commands. Commands of a connection are
+	// processed in order, each mapped to one or more juno requests handed to
+	// the same IRequestHandler used by the TCP listeners.
+	RespListener struct {
+		Listener
+		namespace []byte
+		mtx       sync.Mutex
+		conns     map[*respConnT]struct{}
+		wg        sync.WaitGroup
+		stopping  bool
+	}
+
+	respConnT struct {
+		conn    net.Conn
+		reader  *bufio.Reader
+		writer  *bufio.Writer
+		lsnr    *RespListener
+		ctx     context.Context
+		cancel  context.CancelFunc
+		ip      net.IP
+		port    uint16
+		appName []byte
+	}
+)
+
+func newRespListener(ln *Listener) (lsnr *RespListener) {
+	lsnr = &RespListener{
+		Listener:  *ln,
+		namespace: []byte(ln.config.Namespace),
+		conns:     make(map[*respConnT]struct{}),
+	}
+	if len(lsnr.namespace) == 0 {
+		lsnr.namespace = []byte(DefaultRespNamespace)
+	}
+	lsnr.lsnrType = ListenerTypeRESP
+	return
+}
+
+func (l *RespListener) AcceptAndServe() error {
+	conn, err := l.netListener.Accept()
+	if err != nil {
+		otel.RecordCount(otel.Accept, []otel.Tags{{otel.Status, otel.Error}})
+		return err
+	}
+	otel.RecordCount(otel.Accept, []otel.Tags{{otel.Status, otel.Success}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &respConnT{
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, l.ioConfig.IOBufSize),
+		writer: bufio.NewWriterSize(conn, l.ioConfig.IOBufSize),
+		lsnr:   l,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		c.ip = addr.IP
+		c.port = uint16(addr.Port)
+	}
+	if !l.trackConn(c, true) {
+		c.close()
+		return nil
+	}
+	go c.serve()
+	return nil
+}
+
+func (l *RespListener) trackConn(c *respConnT, add bool) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if add {
+		if l.stopping {
+			return false
+		}
+		l.conns[c] = struct{}{}
+		l.wg.Add(1)
+	} else {
+		delete(l.conns, c)
+		l.wg.Done()
+	}
+	return true
+}
+
+// Shutdown stops accepting connections, and interrupts the connections
+// waiting for the next command.
+func (l *RespListener) Shutdown() {
+	l.netListener.Close()
+	l.mtx.Lock()
+	l.stopping = true
+	for c := range l.conns {
+		c.conn.SetReadDeadline(time.Now())
+	}
+	l.mtx.Unlock()
+}
+
+func (l *RespListener) WaitForShutdownToComplete(timeout time.Duration) {
+	chDone := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(chDone)
+	}()
+	select {
+	case <-chDone:
+	case <-time.After(timeout):
+		glog.Warningf("resp listener %s: shutdown timed out, closing connections", l.GetName())
+		l.mtx.Lock()
+		for c := range l.conns {
+			c.conn.Close()
+		}
+		l.mtx.Unlock()
+	}
+}
+
+func (l *RespListener) GetType() ListenerType {
+	return ListenerTypeRESP
+}
+
+func (l *RespListener) GetNumActiveConnections() uint32 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return uint32(len(l.conns))
+}
+
+func (l *RespListener) Refresh() {
+	l.Listener.Refresh()
+	l.mtx.Lock()
+	l.stopping = false
+	l.mtx.Unlock()
+}
+
+func (l *RespListener) isStopping() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.stopping
+}
+
+func (c *respConnT) serve() {
+	defer func() {
+		c.close()
+		c.lsnr.trackConn(c, false)
+	}()
+	for !c.lsnr.isStopping() {
+		c.conn.SetReadDeadline(time.Now().Add(c.lsnr.ioConfig.IdleTimeout.Duration))
+		args, err := readRespCommand(c.reader)
+		if err != nil {
+			if perr, ok := err.(respProtocolError); ok {
+				writeRespError(c.writer, perr.Error())
+				c.writer.Flush()
+			} else if c.lsnr.isStopping() {
+				glog.Debugf("resp connection closed for shutdown")
+			} else if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				glog.Debugf("idle timeout")
+			} else {
+				ioutil.LogError(err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		quit := c.execute(args)
+
+		// flush once the pipelined commands have been processed
+		if c.reader.Buffered() == 0 || quit {
+			c.conn.SetWriteDeadline(time.Now().Add(c.lsnr.ioConfig.WriteTimeout.Duration))
+			if err = c.writer.Flush(); err != nil {
+				ioutil.LogError(err)
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+func (c *respConnT) close() {
+	c.cancel()
+	c.conn.Close()
+	otel.RecordCount(otel.Close, []otel.Tags{})
+}
@@ -0,0 +1,110 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"juno/pkg/proto"
+)
+
+func TestRespListener(t *testing.T) {
+	cfg := ListenerConfig{Name: "resp"}
+	cfg.Addr = "127.0.0.1:0"
+	cfg.RESPEnabled = true
+	handler := &fakeKVHandler{recs: make(map[string]*proto.OperationalMessage)}
+	lsnr, err := NewListener(cfg, DefaultInboundConfig, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lsnr.GetType() != ListenerTypeRESP {
+		t.Fatal("resp listener expected")
+	}
+	go func() {
+		for lsnr.AcceptAndServe() == nil {
+		}
+	}()
+	defer lsnr.Close()
+
+	conn, err := net.Dial("tcp", lsnr.(*RespListener).netListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	tests := []struct {
+		cmd   string
+		reply string
+	}{
+		{"*1\r\n$4\r\nPING\r\n", "+PONG"},
+		{"GET k1\r\n", "$-1"},
+		{"*5\r\n$3\r\nSET\r\n$2\r\nk1\r\n$2\r\nv1\r\n$2\r\nEX\r\n$2\r\n10\r\n", "+OK"},
+		{"SET k1 v2 NX\r\n", "$-1"},
+		{"SET k2 v2 XX\r\n", "$-1"},
+		{"SET k1 v3 XX\r\n", "+OK"},
+		{"GET k1\r\n", "$2\r\nv3"},
+		{"TTL k1\r\n", ":10"},
+		{"TTL k2\r\n", ":-2"},
+		{"MGET k1 k2\r\n", "*2\r\n$2\r\nv3\r\n$-1"},
+		{"EXPIRE k1 20\r\n", ":1"},
+		{"TTL k1\r\n", ":20"},
+		{"EXPIRE k1 5\r\n", "-ERR juno can't shorten the TTL of a key"},
+		{"EXPIRE k2 5\r\n", ":0"},
+		{"INCRBY c 5\r\n", ":5"},
+		{"DECR c\r\n", ":4"},
+		{"INCRBY c x\r\n", "-ERR value is not an integer or out of range"},
+		{"SET k4 v4\r\n", "+OK"},
+		{"EXPIRE k4 0\r\n", ":1"},
+		{"GET k4\r\n", "$-1"},
+		{"DEL k1 c\r\n", ":2"},
+		{"GET k1\r\n", "$-1"},
+		{"SET k1\r\n", "-ERR wrong number of arguments for 'set' command"},
+		{"FLUSHALL\r\n", "-ERR unknown command 'FLUSHALL'"},
+		{"SET k3 v3\r\n", "+OK"},
+		{"QUIT\r\n", "+OK"},
+	}
+	for _, tc := range tests {
+		if _, err = conn.Write([]byte(tc.cmd)); err != nil {
+			t.Fatal(err)
+		}
+		expected := strings.Split(tc.reply, "\r\n")
+		for _, exp := range expected {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("%q: %s", tc.cmd, err)
+			}
+			if line = strings.TrimRight(line, "\r\n"); line != exp {
+				t.Errorf("%q: expected %q, got %q", tc.cmd, exp, line)
+			}
+		}
+	}
+	if _, err = reader.ReadByte(); err == nil {
+		t.Error("connection should be closed after QUIT")
+	}
+	handler.mtx.Lock()
+	defer handler.mtx.Unlock()
+	if _, ok := handler.recs[DefaultRespNamespace+"/k3"]; !ok {
+		t.Error("default namespace expected")
+	}
+}
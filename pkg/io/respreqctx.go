@@ -0,0 +1,531 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	goio "io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"juno/pkg/proto"
+	"juno/third_party/forked/golang/glog"
+)
+
+// RESP front-end
+//
+// The commands are mapped to juno requests on the namespace of the listener.
+//
+//	GET key                              Get
+//	SET key value [EX s|PX ms] [NX|XX]   Set, Create with NX, Update with XX
+//	DEL key [key ...]                    Destroy
+//	EXPIRE key seconds                   Get with TTL, Destroy if seconds <= 0
+//	TTL key                              Get
+//	INCRBY key delta                     UDFSet counter64
+//	INCR, DECR, DECRBY                   same as INCRBY
+//	MGET key [key ...]                   Get
+//	PING, QUIT, COMMAND, CLIENT SETNAME
+//
+// Counters are 8-byte big-endian values as stored by the counter64 UDF, and
+// INCRBY returns the value of the counter after the increment. DEL counts the
+// keys destroyed without error, whether they existed or not.
+//
+// Juno only extends the TTL of a record, so EXPIRE fails with a TTL shorter
+// than the remaining one. SET without EX or PX sends no TTL, so the default
+// TTL of the namespace applies, and a SET keeps the remaining TTL if longer.
+const (
+	kRespMaxArgs      = 1024 * 1024
+	kRespMaxBulkLen   = 8 << 20
+	kRespCounterUDF   = "counter64"
+	kRespMaxInlineLen = 64 * 1024
+
+	// Maximum number of keys of a MGET got at a time.
+	kRespMaxMGetConcurrency = 16
+)
+
+type (
+	respProtocolError string
+
+	respResultT struct {
+		status proto.OpStatus
+		value  []byte
+		ttl    uint32
+	}
+)
+
+var errRespNil = errors.New("nil")
+
+func (e respProtocolError) Error() string {
+	return "ERR Protocol error: " + string(e)
+}
+
+func readRespLine(r *bufio.Reader) (line []byte, err error) {
+	if line, err = r.ReadSlice('\n'); err != nil {
+		if err == bufio.ErrBufferFull {
+			err = respProtocolError("too big inline request")
+		}
+		return
+	}
+	line = bytes.TrimRight(line, "\r\n")
+	return
+}
+
+func readRespInt(r *bufio.Reader, prefix byte) (n int, err error) {
+	var line []byte
+	if line, err = readRespLine(r); err != nil {
+		return
+	}
+	if len(line) == 0 || line[0] != prefix {
+		err = respProtocolError(fmt.Sprintf("expected '%c'", prefix))
+		return
+	}
+	if n, err = strconv.Atoi(string(line[1:])); err != nil {
+		err = respProtocolError("invalid length")
+	}
+	return
+}
+
+// readRespCommand reads an array of bulk strings, or an inline command.
+func readRespCommand(r *bufio.Reader) (args [][]byte, err error) {
+	var b []byte
+	if b, err = r.Peek(1); err != nil {
+		return
+	}
+	if b[0] != '*' {
+		var line []byte
+		if line, err = readRespLine(r); err != nil {
+			return
+		}
+		for _, f := range bytes.Fields(line) {
+			args = append(args, append([]byte(nil), f...))
+		}
+		return
+	}
+	var n int
+	if n, err = readRespInt(r, '*'); err != nil {
+		return
+	}
+	if n > kRespMaxArgs {
+		err = respProtocolError("invalid multibulk length")
+		return
+	}
+	args = make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		var sz int
+		if sz, err = readRespInt(r, '$'); err != nil {
+			return
+		}
+		if sz < 0 || sz > kRespMaxBulkLen {
+			err = respProtocolError("invalid bulk length")
+			return
+		}
+		arg := make([]byte, sz+2)
+		if _, err = goio.ReadFull(r, arg); err != nil {
+			return
+		}
+		args = append(args, arg[:sz])
+	}
+	return
+}
+
+func writeRespSimpleString(w *bufio.Writer, s string) {
+	w.WriteByte('+')
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func writeRespError(w *bufio.Writer, s string) {
+	w.WriteByte('-')
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func writeRespInt(w *bufio.Writer, n int64) {
+	w.WriteByte(':')
+	w.WriteString(strconv.FormatInt(n, 10))
+	w.WriteString("\r\n")
+}
+
+func writeRespBulk(w *bufio.Writer, b []byte) {
+	w.WriteByte('$')
+	w.WriteString(strconv.Itoa(len(b)))
+	w.WriteString("\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func writeRespNil(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
+
+func writeRespArrayHeader(w *bufio.Writer, n int) {
+	w.WriteByte('*')
+	w.WriteString(strconv.Itoa(n))
+	w.WriteString("\r\n")
+}
+
+func writeRespStatusError(w *bufio.Writer, st proto.OpStatus) {
+	writeRespError(w, "ERR juno "+st.String())
+}
+
+func isRespFound(st proto.OpStatus) bool {
+	return st == proto.OpStatusNoError || st == proto.OpStatusInconsistent
+}
+
+func isRespNotFound(st proto.OpStatus) bool {
+	return st == proto.OpStatusNoKey || st == proto.OpStatusKeyMarkedDelete
+}
+
+// call sends one juno request to the request handler and waits for the
+// response.
+func (c *respConnT) call(op proto.OpCode, key []byte, value []byte, ttl uint32,
+	setup func(m *proto.OperationalMessage)) (res respResultT, err error) {
+
+	var m proto.OperationalMessage
+	var payload proto.Payload
+	payload.SetWithClearValue(value)
+	m.SetRequest(op, key, c.lsnr.namespace, &payload, ttl)
+	m.SetSource(c.ip, c.port, c.appName)
+	m.SetNewRequestID()
+	if setup != nil {
+		setup(&m)
+	}
+	var rctx *syncRequestContext
+	if rctx, err = newSyncRequestContext(&m); err != nil {
+		return
+	}
+	timeout := c.lsnr.ioConfig.RequestTimeout.Duration
+	rctx.SetTimeout(c.ctx, timeout)
+	go c.lsnr.reqHandler.Process(rctx)
+
+	timer := time.NewTimer(2 * timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-rctx.chReply:
+		var resp proto.OperationalMessage
+		if err = resp.Decode(r.GetMessage()); err == nil {
+			res.status = resp.GetOpStatus()
+			res.ttl = resp.GetTimeToLive()
+			if resp.GetPayloadValueLength() != 0 {
+				var v []byte
+				if v, err = resp.GetPayload().GetClearValue(); err == nil {
+					res.value = append([]byte(nil), v...)
+				}
+			}
+		}
+		r.OnComplete()
+	case <-timer.C:
+		rctx.abandon()
+		res.status = proto.OpStatusReqProcTimeout
+	case <-c.ctx.Done():
+		rctx.abandon()
+		err = c.ctx.Err()
+	}
+	return
+}
+
+// execute runs one command, writing the reply to the buffered writer. It
+// returns true if the connection is to be closed.
+func (c *respConnT) execute(args [][]byte) (quit bool) {
+	w := c.writer
+	cmd := strings.ToUpper(string(args[0]))
+	argc := len(args)
+
+	wrongArgs := func() {
+		writeRespError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(cmd)))
+	}
+
+	switch cmd {
+	case "PING":
+		if argc > 2 {
+			wrongArgs()
+		} else if argc == 2 {
+			writeRespBulk(w, args[1])
+		} else {
+			writeRespSimpleString(w, "PONG")
+		}
+	case "QUIT":
+		writeRespSimpleString(w, "OK")
+		quit = true
+	case "COMMAND":
+		writeRespArrayHeader(w, 0)
+	case "CLIENT":
+		if argc == 3 && strings.ToUpper(string(args[1])) == "SETNAME" {
+			c.appName = args[2]
+			writeRespSimpleString(w, "OK")
+		} else {
+			writeRespError(w, "ERR unsupported CLIENT subcommand")
+		}
+	case "GET":
+		if argc != 2 {
+			wrongArgs()
+			return
+		}
+		c.get(args[1])
+	case "SET":
+		if argc < 3 {
+			wrongArgs()
+			return
+		}
+		c.set(args[1:])
+	case "DEL":
+		if argc < 2 {
+			wrongArgs()
+			return
+		}
+		c.del(args[1:])
+	case "EXPIRE":
+		if argc != 3 {
+			wrongArgs()
+			return
+		}
+		c.expire(args[1], args[2])
+	case "TTL":
+		if argc != 2 {
+			wrongArgs()
+			return
+		}
+		c.ttl(args[1])
+	case "INCR", "DECR":
+		if argc != 2 {
+			wrongArgs()
+			return
+		}
+		if cmd == "INCR" {
+			c.incrBy(args[1], 1)
+		} else {
+			c.incrBy(args[1], -1)
+		}
+	case "INCRBY", "DECRBY":
+		if argc != 3 {
+			wrongArgs()
+			return
+		}
+		delta, err := strconv.ParseInt(string(args[2]), 10, 64)
+		if err != nil {
+			writeRespError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		if cmd == "DECRBY" {
+			delta = -delta
+		}
+		c.incrBy(args[1], delta)
+	case "MGET":
+		if argc < 2 {
+			wrongArgs()
+			return
+		}
+		c.mget(args[1:])
+	default:
+		writeRespError(w, fmt.Sprintf("ERR unknown command '%s'", string(args[0])))
+	}
+	return
+}
+
+func (c *respConnT) get(key []byte) {
+	res, err := c.call(proto.OpCodeGet, key, nil, 0, nil)
+	if err != nil {
+		writeRespError(c.writer, "ERR "+err.Error())
+	} else if isRespFound(res.status) {
+		writeRespBulk(c.writer, res.value)
+	} else if isRespNotFound(res.status) {
+		writeRespNil(c.writer)
+	} else {
+		writeRespStatusError(c.writer, res.status)
+	}
+}
+
+func (c *respConnT) set(args [][]byte) {
+	w := c.writer
+	key, value := args[0], args[1]
+	op := proto.OpCodeSet
+	var ttl uint64
+	for i := 2; i < len(args); i++ {
+		opt := strings.ToUpper(string(args[i]))
+		switch opt {
+		case "NX", "XX":
+			if op != proto.OpCodeSet {
+				writeRespError(w, "ERR syntax error")
+				return
+			}
+			if opt == "NX" {
+				op = proto.OpCodeCreate
+			} else {
+				op = proto.OpCodeUpdate
+			}
+		case "EX", "PX":
+			if ttl != 0 || i+1 >= len(args) {
+				writeRespError(w, "ERR syntax error")
+				return
+			}
+			i++
+			v, err := strconv.ParseUint(string(args[i]), 10, 32)
+			if err != nil || v == 0 {
+				writeRespError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			ttl = v
+			if opt == "PX" {
+				ttl = (v + 999) / 1000
+			}
+		default:
+			writeRespError(w, "ERR syntax error")
+			return
+		}
+	}
+	res, err := c.call(op, key, value, uint32(ttl), nil)
+	if err != nil {
+		writeRespError(w, "ERR "+err.Error())
+	} else if isRespFound(res.status) {
+		writeRespSimpleString(w, "OK")
+	} else if (op == proto.OpCodeCreate && res.status == proto.OpStatusDupKey) ||
+		(op == proto.OpCodeUpdate && isRespNotFound(res.status)) {
+		writeRespNil(w)
+	} else {
+		writeRespStatusError(w, res.status)
+	}
+}
+
+func (c *respConnT) del(keys [][]byte) {
+	var n int64
+	for _, key := range keys {
+		res, err := c.call(proto.OpCodeDestroy, key, nil, 0, nil)
+		if err != nil {
+			writeRespError(c.writer, "ERR "+err.Error())
+			return
+		}
+		if isRespFound(res.status) {
+			n++
+		} else if !isRespNotFound(res.status) {
+			writeRespStatusError(c.writer, res.status)
+			return
+		}
+	}
+	writeRespInt(c.writer, n)
+}
+
+// expire extends the TTL of the key with a Get, as Juno can't shorten it, or
+// destroys the key if seconds <= 0.
+func (c *respConnT) expire(key []byte, seconds []byte) {
+	ttl, err := strconv.ParseInt(string(seconds), 10, 32)
+	if err != nil {
+		writeRespError(c.writer, "ERR value is not an integer or out of range")
+		return
+	}
+	res, err := c.call(proto.OpCodeGet, key, nil, 0, nil)
+	if err != nil {
+		writeRespError(c.writer, "ERR "+err.Error())
+		return
+	} else if isRespNotFound(res.status) {
+		writeRespInt(c.writer, 0)
+		return
+	} else if !isRespFound(res.status) {
+		writeRespStatusError(c.writer, res.status)
+		return
+	}
+
+	switch {
+	case ttl <= 0:
+		res, err = c.call(proto.OpCodeDestroy, key, nil, 0, nil)
+	case ttl < int64(res.ttl):
+		writeRespError(c.writer, "ERR juno can't shorten the TTL of a key")
+		return
+	default:
+		res, err = c.call(proto.OpCodeGet, key, nil, uint32(ttl), nil)
+	}
+	if err != nil {
+		writeRespError(c.writer, "ERR "+err.Error())
+	} else if isRespFound(res.status) {
+		writeRespInt(c.writer, 1)
+	} else if isRespNotFound(res.status) {
+		writeRespInt(c.writer, 0)
+	} else {
+		writeRespStatusError(c.writer, res.status)
+	}
+}
+
+func (c *respConnT) ttl(key []byte) {
+	res, err := c.call(proto.OpCodeGet, key, nil, 0, nil)
+	if err != nil {
+		writeRespError(c.writer, "ERR "+err.Error())
+	} else if isRespFound(res.status) {
+		writeRespInt(c.writer, int64(res.ttl))
+	} else if isRespNotFound(res.status) {
+		writeRespInt(c.writer, -2)
+	} else {
+		writeRespStatusError(c.writer, res.status)
+	}
+}
+
+func (c *respConnT) incrBy(key []byte, delta int64) {
+	params := make([]byte, 8)
+	binary.BigEndian.PutUint64(params, uint64(delta))
+	// the response carries the result of the udf, the new value
+	res, err := c.call(proto.OpCodeUDFSet, key, params, 0, func(m *proto.OperationalMessage) {
+		m.SetUDFName([]byte(kRespCounterUDF))
+	})
+	if err != nil {
+		writeRespError(c.writer, "ERR "+err.Error())
+	} else if res.status == proto.OpStatusUDFError {
+		writeRespError(c.writer, "ERR value is not a counter")
+	} else if !isRespFound(res.status) {
+		writeRespStatusError(c.writer, res.status)
+	} else if len(res.value) != 8 {
+		writeRespError(c.writer, "ERR value is not a counter")
+	} else {
+		writeRespInt(c.writer, int64(binary.BigEndian.Uint64(res.value)))
+	}
+}
+
+// mget gets the keys concurrently, kRespMaxMGetConcurrency at a time
+func (c *respConnT) mget(keys [][]byte) {
+	results := make([]respResultT, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, kRespMaxMGetConcurrency)
+	for i := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			results[i], errs[i] = c.call(proto.OpCodeGet, keys[i], nil, 0, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	writeRespArrayHeader(c.writer, len(keys))
+	for i, res := range results {
+		if errs[i] == nil && isRespFound(res.status) {
+			writeRespBulk(c.writer, res.value)
+		} else {
+			if errs[i] != nil {
+				glog.Debugf("resp mget: %s", errs[i].Error())
+			}
+			writeRespNil(c.writer)
+		}
+	}
+}
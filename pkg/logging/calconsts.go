@@ -37,6 +37,7 @@ const (
 const (
 	CalMsgNameDbPut              string = "Put"
 	CalMsgNameDbGet              string = "Get"
+	CalMsgNameDbScan             string = "Scan"
 	CalMsgNameStart              string = "Start"
 	CalMsgNameExit               string = "Exit"
 	CalMsgNameInbound            string = "In"
@@ -41,6 +41,12 @@ type Config struct {
 	Resolution       uint32
 	UseTls           bool
 	HistogramBuckets HistBuckets
+
+	// Export spans to the collector, with the given ratio of the traces not
+	// sampled by the caller.
+	TraceEnabled     bool
+	TraceUrlPath     string
+	TraceSampleRatio float64
 }
 
 func (c *Config) Validate() {
@@ -66,6 +72,12 @@ func (c *Config) setDefaultIfNotDefined() {
 	if c.UrlPath == "" {
 		c.UrlPath = "v1/datapoint"
 	}
+	if c.TraceUrlPath == "" {
+		c.TraceUrlPath = "v1/traces"
+	}
+	if c.TraceSampleRatio <= 0 || c.TraceSampleRatio > 1 {
+		c.TraceSampleRatio = 1
+	}
 	if c.HistogramBuckets.Inbound == nil {
 		c.HistogramBuckets.Inbound = []float64{200, 400, 800, 1200, 2400, 3600, 7200, 10800, 21600, 43200, 86400, 172800}
 	}
@@ -88,6 +100,9 @@ func (c *Config) Dump() {
 	glog.Infof("Resolution: %d", c.Resolution)
 	glog.Infof("UseTls: %t", c.UseTls)
 	glog.Infof("UrlPath: %s", c.UrlPath)
+	glog.Infof("TraceEnabled: %t", c.TraceEnabled)
+	glog.Infof("TraceUrlPath: %s", c.TraceUrlPath)
+	glog.Infof("TraceSampleRatio: %g", c.TraceSampleRatio)
 	glog.Info("Inbound Bucket: ", c.HistogramBuckets.Inbound)
 	glog.Info("OutboundConnection Bucket: ", c.HistogramBuckets.OutboundConnection)
 	glog.Info("Replication Bucket: ", c.HistogramBuckets.Replication)
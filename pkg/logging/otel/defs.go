@@ -182,6 +182,9 @@ var (
 	stallOnce             sync.Once
 	TCPConnCountOnce      sync.Once
 	SSLConnCountOnce      sync.Once
+	HTTPConnCountOnce     sync.Once
+	GRPCConnCountOnce     sync.Once
+	RESPConnCountOnce     sync.Once
 )
 
 var (
@@ -201,6 +204,9 @@ var GaugeMetricList = []*GaugeMetric{
 
 	{"conns", "conns_count", "number of current TCP connections", nil, nil, &TCPConnCountOnce, SvrTypeProxy},
 	{"ssl_conns", "conns_ssl_count", "number of current SSL connections", nil, nil, &SSLConnCountOnce, SvrTypeProxy},
+	{"http_conns", "conns_http_count", "number of current HTTP connections", nil, nil, &HTTPConnCountOnce, SvrTypeProxy},
+	{"grpc_conns", "conns_grpc_count", "number of current gRPC connections", nil, nil, &GRPCConnCountOnce, SvrTypeProxy},
+	{"resp_conns", "conns_resp_count", "number of current RESP connections", nil, nil, &RESPConnCountOnce, SvrTypeProxy},
 	{"keys", "key_count", "Key Counte in rocksDB", nil, nil, &keyCountOnce, SvrTypeStorage},
 	{"free", "free_mb_storage_space", "Free Storage Space (mbytes)", nil, nil, &freeStorageOnce, SvrTypeStorage},
 	{"used", "storage_used_mb", "Used Storage Space (mbytes)", nil, nil, &usedStorageOnce, SvrTypeStorage},
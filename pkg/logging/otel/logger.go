@@ -65,6 +65,9 @@ func Initialize(args ...interface{}) (err error) {
 		// Initialize only if OTEL is enabled
 		InitMetricProvider(c)
 	}
+	if c.TraceEnabled {
+		InitTracerProvider(c)
+	}
 	return
 }
 
@@ -82,6 +85,7 @@ func Finalize() {
 			close(val.doneCh)
 		}
 	}
+	shutdownTracerProvider()
 }
 
 func InitMetricProvider(config *otelCfg.Config) {
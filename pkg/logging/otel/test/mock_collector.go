@@ -43,10 +43,13 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	collectormetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
 )
 
 const DefaultMetricsPath string = "/v1/metrics"
+const DefaultTracesPath string = "/v1/traces"
 
 type mockCollector struct {
 	endpoint string
@@ -54,6 +57,7 @@ type mockCollector struct {
 
 	spanLock       sync.Mutex
 	metricsStorage MetricsStorage
+	spans          []*tracepb.Span
 
 	injectHTTPStatus  []int
 	injectContentType string
@@ -77,6 +81,12 @@ func (c *mockCollector) GetMetrics() []*metricpb.Metric {
 	return c.metricsStorage.GetMetrics()
 }
 
+func (c *mockCollector) GetSpans() []*tracepb.Span {
+	c.spanLock.Lock()
+	defer c.spanLock.Unlock()
+	return append([]*tracepb.Span(nil), c.spans...)
+}
+
 func (c *mockCollector) Endpoint() string {
 	return c.endpoint
 }
@@ -127,6 +137,34 @@ func (c *mockCollector) serveMetrics(w http.ResponseWriter, r *http.Request) {
 	c.metricsStorage.AddMetrics(request)
 }
 
+func (c *mockCollector) serveTraces(w http.ResponseWriter, r *http.Request) {
+	response := collectortracepb.ExportTraceServiceResponse{}
+	rawResponse, err := proto.Marshal(&response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rawRequest, err := readRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	request := &collectortracepb.ExportTraceServiceRequest{}
+	if err = proto.Unmarshal(rawRequest, request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	writeReply(w, rawResponse, 0, c.injectContentType)
+	c.spanLock.Lock()
+	defer c.spanLock.Unlock()
+
+	for _, rs := range request.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			c.spans = append(c.spans, ss.GetSpans()...)
+		}
+	}
+}
+
 func unmarshalMetricsRequest(rawRequest []byte, contentType string) (*collectormetricpb.ExportMetricsServiceRequest, error) {
 	request := &collectormetricpb.ExportMetricsServiceRequest{}
 	if contentType != "application/x-protobuf" {
@@ -195,6 +233,7 @@ func writeReply(w http.ResponseWriter, rawResponse []byte, injectHTTPStatus int,
 
 type mockCollectorConfig struct {
 	MetricsURLPath    string
+	TracesURLPath     string
 	Port              int
 	InjectHTTPStatus  []int
 	InjectContentType string
@@ -207,6 +246,9 @@ func (c *mockCollectorConfig) fillInDefaults() {
 	if c.MetricsURLPath == "" {
 		c.MetricsURLPath = DefaultMetricsPath
 	}
+	if c.TracesURLPath == "" {
+		c.TracesURLPath = DefaultTracesPath
+	}
 }
 
 func runMockCollector(t *testing.T, cfg mockCollectorConfig) *mockCollector {
@@ -225,6 +267,7 @@ func runMockCollector(t *testing.T, cfg mockCollectorConfig) *mockCollector {
 	}
 	mux := http.NewServeMux()
 	mux.Handle(cfg.MetricsURLPath, http.HandlerFunc(m.serveMetrics))
+	mux.Handle(cfg.TracesURLPath, http.HandlerFunc(m.serveTraces))
 	server := &http.Server{
 		Handler: mux,
 	}
@@ -0,0 +1,95 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package otel
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"juno/pkg/logging/otel"
+	config "juno/pkg/logging/otel/config"
+	"juno/pkg/proto"
+)
+
+// Runs last, as it finalizes the otel package to flush the spans.
+func TestTraceSpans(t *testing.T) {
+	mc := runMockCollector(t, mockCollectorConfig{
+		Port:    4318,
+		WithTLS: false,
+	})
+	defer mc.MustStop(t)
+
+	cfg := config.Config{
+		Host:         "localhost",
+		Port:         4318,
+		Poolname:     "test",
+		TraceEnabled: true,
+	}
+	cfg.Validate()
+	otel.InitTracerProvider(&cfg)
+	if !otel.IsTraceEnabled() {
+		t.Fatal("tracing not enabled")
+	}
+
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceId + "-00f067aa0ba902b7-01"
+	span := otel.StartSpan("proxy.Set", []byte(traceparent), trace.SpanKindServer,
+		otel.AttrNamespace.String("ns"))
+	tp := string(otel.TraceParent(span))
+	if !strings.HasPrefix(tp, "00-"+traceId+"-") || tp == traceparent {
+		t.Errorf("unexpected traceparent %q", tp)
+	}
+	child := otel.StartChildSpan(span, "ss.PrepareSet", trace.SpanKindClient)
+	otel.EndSpan(child, proto.OpStatusNoStorageServer)
+	otel.EndSpan(span, proto.OpStatusNoError)
+
+	otel.Finalize()
+
+	spans := mc.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	byName := make(map[string]*tracepb.Span)
+	for _, s := range spans {
+		if hex.EncodeToString(s.GetTraceId()) != traceId {
+			t.Errorf("span %s not in the trace of the caller", s.GetName())
+		}
+		byName[s.GetName()] = s
+	}
+	parent, ss := byName["proxy.Set"], byName["ss.PrepareSet"]
+	if parent == nil || ss == nil {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	if hex.EncodeToString(parent.GetParentSpanId()) != "00f067aa0ba902b7" {
+		t.Errorf("unexpected parent of the proxy span %x", parent.GetParentSpanId())
+	}
+	if !bytes.Equal(ss.GetParentSpanId(), parent.GetSpanId()) {
+		t.Error("the SS span is not a child of the proxy span")
+	}
+	if parent.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR ||
+		ss.GetStatus().GetCode() != tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("unexpected span status %s %s", parent.GetStatus(), ss.GetStatus())
+	}
+}
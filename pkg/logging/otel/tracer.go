@@ -0,0 +1,160 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"juno/pkg/logging"
+	otelCfg "juno/pkg/logging/otel/config"
+	"juno/pkg/proto"
+	"juno/third_party/forked/golang/glog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TracerName = "juno"
+
+	kTraceParent = "traceparent"
+)
+
+// Span attributes
+const (
+	AttrOpCode    = attribute.Key("juno.opcode")
+	AttrOpStatus  = attribute.Key("juno.opstatus")
+	AttrNamespace = attribute.Key("juno.namespace")
+	AttrRequestID = attribute.Key("juno.rid")
+	AttrPeer      = attribute.Key("juno.peer")
+	AttrTarget    = attribute.Key("juno.target")
+)
+
+var (
+	tracerProvider *sdktrace.TracerProvider
+	tracer         trace.Tracer = trace.NewNoopTracerProvider().Tracer(TracerName)
+	propagator     propagation.TraceContext
+)
+
+// InitTracerProvider starts exporting the spans to the OTLP collector of the
+// config.
+func InitTracerProvider(config *otelCfg.Config) {
+	if tracerProvider != nil {
+		return
+	}
+	exp, err := NewHTTPTraceExporter(context.Background(), config)
+	if err != nil {
+		glog.Errorf("fail to create trace exporter: %s", err)
+		return
+	}
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(getResourceInfo(config.Poolname)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TraceSampleRatio))),
+	)
+	tracer = tracerProvider.Tracer(TracerName)
+}
+
+func NewHTTPTraceExporter(ctx context.Context, config *otelCfg.Config) (*otlptrace.Exporter, error) {
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(config.Host + ":" + fmt.Sprintf("%d", config.Port)),
+		otlptracehttp.WithURLPath(config.TraceUrlPath),
+		otlptracehttp.WithTimeout(7 * time.Second),
+		otlptracehttp.WithCompression(otlptracehttp.NoCompression),
+	}
+	if !config.UseTls {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// shutdownTracerProvider flushes the pending spans.
+func shutdownTracerProvider() {
+	if tracerProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			glog.Warningf("fail to shutdown tracer provider: %s", err)
+		}
+	}
+}
+
+func IsTraceEnabled() bool {
+	return tracerProvider != nil
+}
+
+// StartSpan starts a span continuing the trace of the W3C traceparent, or a
+// new trace if it is empty. The returned span is a no-op one if tracing is not
+// enabled.
+func StartSpan(name string, traceparent []byte, kind trace.SpanKind, attrs ...attribute.KeyValue) trace.Span {
+	ctx := context.Background()
+	if len(traceparent) != 0 {
+		ctx = propagator.Extract(ctx, propagation.MapCarrier{kTraceParent: string(traceparent)})
+	}
+	_, span := tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	return span
+}
+
+// StartChildSpan starts a span with the given parent.
+func StartChildSpan(parent trace.Span, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) trace.Span {
+	ctx := trace.ContextWithSpan(context.Background(), parent)
+	_, span := tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	return span
+}
+
+// TraceParent returns the W3C traceparent of the span, or nil if the span is
+// not recording.
+func TraceParent(span trace.Span) []byte {
+	if span == nil || !span.IsRecording() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(trace.ContextWithSpan(context.Background(), span), carrier)
+	if tp := carrier.Get(kTraceParent); tp != "" {
+		return []byte(tp)
+	}
+	return nil
+}
+
+// EndSpan records the OpStatus of the operation and ends the span.
+func EndSpan(span trace.Span, status proto.OpStatus, options ...trace.SpanEndOption) {
+	EndSpanWithStatus(span, status.String(), !logging.CalStatus(status).NotSuccess(), options...)
+}
+
+// EndSpanWithStatus records the status of the operation, as an error unless ok,
+// and ends the span.
+func EndSpanWithStatus(span trace.Span, status string, ok bool, options ...trace.SpanEndOption) {
+	if span == nil {
+		return
+	}
+	if span.IsRecording() {
+		span.SetAttributes(AttrOpStatus.String(status))
+		if !ok {
+			span.SetStatus(codes.Error, status)
+		}
+	}
+	span.End(options...)
+}
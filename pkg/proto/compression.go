@@ -0,0 +1,179 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ICompressionCodec compresses the value of a PayloadTypecompressedByClient
+// payload. The payload data is
+//
+//	[1 byte codec name length][codec name][compressed value]
+//
+// so that the codec has to be registered by the same name with every client
+// reading the value.
+type ICompressionCodec interface {
+	Name() string
+	Compress(value []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+type (
+	snappyCodecT struct{}
+	gzipCodecT   struct{}
+	zstdCodecT   struct {
+		encoder *zstd.Encoder
+		decoder *zstd.Decoder
+	}
+)
+
+const (
+	ZstdCompression string = "Zstd"
+	GzipCompression string = "Gzip"
+)
+
+var (
+	compressionCodecs   = map[string]ICompressionCodec{}
+	compressionCodecsMu sync.RWMutex
+)
+
+func init() {
+	RegisterCompressionCodec(snappyCodecT{})
+	RegisterCompressionCodec(gzipCodecT{})
+	RegisterCompressionCodec(newZstdCodec())
+}
+
+// RegisterCompressionCodec registers codec by its name, replacing the one
+// registered by the same name if any.
+func RegisterCompressionCodec(codec ICompressionCodec) {
+	compressionCodecsMu.Lock()
+	defer compressionCodecsMu.Unlock()
+	compressionCodecs[codec.Name()] = codec
+}
+
+func GetCompressionCodec(name string) (codec ICompressionCodec, err error) {
+	compressionCodecsMu.RLock()
+	defer compressionCodecsMu.RUnlock()
+	var ok bool
+	if codec, ok = compressionCodecs[name]; !ok {
+		err = ErrUnsupportedCompressionType
+	}
+	return
+}
+
+// SetWithCompressedValue sets the payload with value compressed by the given
+// codec.
+func (p *Payload) SetWithCompressedValue(value []byte, codecName string) (err error) {
+	if len(codecName) > 255 {
+		return fmt.Errorf("compression codec name too long")
+	}
+	var codec ICompressionCodec
+	var compressed []byte
+	if codec, err = GetCompressionCodec(codecName); err != nil {
+		return
+	}
+	if compressed, err = codec.Compress(value); err != nil {
+		return
+	}
+	data := make([]byte, 1+len(codecName)+len(compressed))
+	data[0] = uint8(len(codecName))
+	copy(data[1:], codecName)
+	copy(data[1+len(codecName):], compressed)
+	p.SetPayload(PayloadTypecompressedByClient, data)
+	return
+}
+
+func (p *Payload) getDecompressedValue() (value []byte, err error) {
+	szName := int(p.data[0])
+	if len(p.data) <= szName+1 {
+		err = fmt.Errorf("invalid compressed payload")
+		return
+	}
+	var codec ICompressionCodec
+	if codec, err = GetCompressionCodec(string(p.data[1 : szName+1])); err == nil {
+		value, err = codec.Decompress(p.data[szName+1:])
+	}
+	return
+}
+
+func (snappyCodecT) Name() string {
+	return SnappyCompression
+}
+
+func (snappyCodecT) Compress(value []byte) ([]byte, error) {
+	return snappy.Encode(nil, value), nil
+}
+
+func (snappyCodecT) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+func (gzipCodecT) Name() string {
+	return GzipCompression
+}
+
+func (gzipCodecT) Compress(value []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(value); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodecT) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func newZstdCodec() *zstdCodecT {
+	// with nil reader/writer, both are only used with EncodeAll/DecodeAll,
+	// which are safe for concurrent use
+	encoder, _ := zstd.NewWriter(nil)
+	decoder, _ := zstd.NewReader(nil)
+	return &zstdCodecT{encoder: encoder, decoder: decoder}
+}
+
+func (c *zstdCodecT) Name() string {
+	return ZstdCompression
+}
+
+func (c *zstdCodecT) Compress(value []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(value, nil), nil
+}
+
+func (c *zstdCodecT) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
@@ -0,0 +1,65 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/snappy"
+)
+
+func TestCompressedPayload(t *testing.T) {
+	value := bytes.Repeat([]byte(`{"name":"juno","type":"kv"}`), 100)
+
+	for _, codec := range []string{SnappyCompression, ZstdCompression, GzipCompression} {
+		var p Payload
+		if err := p.SetWithCompressedValue(value, codec); err != nil {
+			t.Fatalf("%s: %s", codec, err)
+		}
+		if p.GetPayloadType() != PayloadTypecompressedByClient {
+			t.Errorf("%s: wrong payload type %d", codec, p.GetPayloadType())
+		}
+		if p.GetValueLength() >= uint32(len(value)) {
+			t.Errorf("%s: value not compressed", codec)
+		}
+		if v, err := p.GetClearValue(); err != nil || !bytes.Equal(v, value) {
+			t.Errorf("%s: fail to get clear value: %v", codec, err)
+		}
+	}
+
+	var p Payload
+	if err := p.SetWithCompressedValue(value, "unknown"); err != ErrUnsupportedCompressionType {
+		t.Errorf("expected ErrUnsupportedCompressionType, got %v", err)
+	}
+}
+
+// payload compressed by the clients predating the codec registry
+func TestSnappyCompressedPayload(t *testing.T) {
+	value := []byte("snappy compressed value")
+	data := append([]byte{byte(len(SnappyCompression))}, SnappyCompression...)
+	data = append(data, snappy.Encode(nil, value)...)
+
+	var p Payload
+	p.SetPayload(PayloadTypecompressedByClient, data)
+	if v, err := p.GetClearValue(); err != nil || !bytes.Equal(v, value) {
+		t.Errorf("fail to get clear value: %v", err)
+	}
+}
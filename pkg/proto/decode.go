@@ -90,6 +90,10 @@ func (op *OperationalMessage) decode(raw []byte, msgHeader *messageHeaderT, copy
 	op.flags = header.flags
 	op.shardIdOrStatus = header.shardIdOrStatus
 	op.typeFlag = msgHeader.typeFlag
+	op.protocolVersion = msgHeader.version
+	if msgHeader.version >= ProtocolVersion2 {
+		op.features = SupportedFeatures
+	}
 
 	if msgHeader.msgSize == kOperationalMessageHeaderSize {
 		return nil
@@ -167,6 +171,18 @@ func (op *OperationalMessage) decodeMetaField(tag byte, szField uint8, raw []byt
 		if err = op.udfName.decode(szField, raw, copyData); err != nil {
 			return
 		}
+	case kFieldTagTenant:
+		if err = op.tenant.decode(szField, raw, copyData); err != nil {
+			return
+		}
+	case kFieldTagTraceContext:
+		if err = op.traceContext.decode(szField, raw, copyData); err != nil {
+			return
+		}
+	case kFieldTagFeatures:
+		if err = op.handshakeFeatures.decode(raw); err != nil {
+			return
+		}
 	default:
 
 	}
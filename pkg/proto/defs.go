@@ -84,6 +84,7 @@ const (
 
 	OpCodeClone        = OpCode(0xE1)
 	OpCodeVerHandshake = OpCode(0xE2)
+	OpCodeScan         = OpCode(0xE3)
 
 	OpCodeMockGetExtendTTL = OpCode(0xFD)
 	OpCodeMockSetParam     = OpCode(0xFE)
@@ -112,6 +113,8 @@ const (
 	OpStatusInconsistent       = OpStatus(26)
 	OpStatusReqProcTimeout     = OpStatus(24)
 	OpStatusNotSupported       = OpStatus(28)
+	OpStatusUDFError           = OpStatus(29)
+	OpStatusNoUDF              = OpStatus(30)
 )
 
 const (
@@ -145,6 +148,7 @@ var (
 		OpCodeMarkDelete:    "MarkDelete",
 		OpCodeClone:         "Clone",
 		OpCodeVerHandshake:  "VerHandshake",
+		OpCodeScan:          "Scan",
 
 		OpCodeMockGetExtendTTL: "GetE",
 		OpCodeMockSetParam:     "OpCodeMockSetParam",
@@ -172,6 +176,7 @@ var (
 		OpCodeRepair:        "RR",
 		OpCodeClone:         "CL",
 		OpCodeVerHandshake:  "VH",
+		OpCodeScan:          "SC",
 	}
 )
 
@@ -198,6 +203,8 @@ var (
 		OpStatusInconsistent:       "InconsistentState",          //26
 		OpStatusKeyMarkedDelete:    "MarkedDelete",               //27
 		OpStatusNotSupported:       "OpNotSupported",             //28
+		OpStatusUDFError:           "UDFError",                   //29
+		OpStatusNoUDF:              "NoUDF",                      //30
 		OpStatusInternal:           "Internal",                   //255
 	}
 
@@ -223,6 +230,8 @@ var (
 		OpStatusInconsistent:       "InConst", //26
 		OpStatusKeyMarkedDelete:    "MDel",    //27
 		OpStatusNotSupported:       "BadOp",   //28
+		OpStatusUDFError:           "UDFErr",  //29
+		OpStatusNoUDF:              "NoUDF",   //30
 		OpStatusInternal:           "Intl",    //255
 	}
 )
@@ -254,6 +263,7 @@ func (op OpCode) IsForStorage() bool {
 	case OpCodePrepareCreate, OpCodeRead, OpCodePrepareUpdate, OpCodePrepareSet, OpCodePrepareDelete,
 		OpCodeDelete,
 		OpCodeCommit, OpCodeAbort, OpCodeRepair, OpCodeClone, OpCodeVerHandshake, OpCodeMarkDelete,
+		OpCodeScan,
 		OpCodeMockSetParam, OpCodeMockReSet:
 		return true
 	}
@@ -43,7 +43,7 @@ Message header
   magic number:
     0x5050
   protocol version:
-    1
+    1, or 2 if the features negotiated with VerHandshake allow (see Feature)
 
   message type and flag:
     type:
@@ -106,6 +106,8 @@ Details
     0xC3	Repair
     0xC4	MarkDelete
     0xE1	Clone
+    0xE2	VerHandshake
+    0xE3	Scan (see ScanRequest)
     0xFE	MockSetParam
     oxFF	MockReSet
 
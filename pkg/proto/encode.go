@@ -106,6 +106,24 @@ func (m *OperationalMessage) getInfoForMetaHeader(tagAndSizeTypes []uint8) (numF
 		numFields++
 	}
 
+	if m.tenant.isSet() && m.features.Has(FeatureTenant) {
+		tagAndSizeTypes[numFields] = m.tenant.tagAndSizeTypeByte()
+		totalSize += m.tenant.size()
+		numFields++
+	}
+
+	if m.traceContext.isSet() && m.features.Has(FeatureTraceContext) {
+		tagAndSizeTypes[numFields] = m.traceContext.tagAndSizeTypeByte()
+		totalSize += m.traceContext.size()
+		numFields++
+	}
+
+	if m.handshakeFeatures.isSet() {
+		tagAndSizeTypes[numFields] = m.handshakeFeatures.tagAndSizeTypeByte()
+		totalSize += m.handshakeFeatures.size()
+		numFields++
+	}
+
 	return
 }
 
@@ -187,6 +205,24 @@ func (m *OperationalMessage) encodeMetaFields(szComp int, buf []byte) (err error
 		}
 		off += fsz
 	}
+	if m.tenant.isSet() && m.features.Has(FeatureTenant) {
+		if fsz, err = m.tenant.encode(buf[off:]); err != nil {
+			return
+		}
+		off += fsz
+	}
+	if m.traceContext.isSet() && m.features.Has(FeatureTraceContext) {
+		if fsz, err = m.traceContext.encode(buf[off:]); err != nil {
+			return
+		}
+		off += fsz
+	}
+	if m.handshakeFeatures.isSet() {
+		if fsz, err = m.handshakeFeatures.encode(buf[off:]); err != nil {
+			return
+		}
+		off += fsz
+	}
 
 	for ; off < szComp; off++ {
 		buf[off] = 0
@@ -196,7 +232,7 @@ func (m *OperationalMessage) encodeMetaFields(szComp int, buf []byte) (err error
 
 func (op *OperationalMessage) Encode(wMsg *RawMessage) (err error) {
 	wMsg.magic = kMessageMagic
-	wMsg.version = kCurrentVersion
+	wMsg.version = op.GetProtocolVersion()
 	wMsg.typeFlag = op.typeFlag
 	wMsg.opaque = op.GetOpaque()
 	header := operationalHeaderT{
@@ -353,6 +389,12 @@ func SetOpCode(wmsg *RawMessage, opcode OpCode) (err error) {
 	return
 }
 
+// SetProtocolVersion sets the protocol version in the message header. The
+// message must not have meta fields of features not enabled for the version.
+func SetProtocolVersion(wmsg *RawMessage, version uint8) {
+	wmsg.version = version
+}
+
 func SetOpStatus(wmsg *RawMessage, st OpStatus) (err error) {
 	if wmsg.typeFlag != kOperationalMessageType || len(wmsg.body) < kOpMsgSubHeaderSize {
 		if !wmsg.typeFlag.isResponse() {
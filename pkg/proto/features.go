@@ -0,0 +1,156 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proto
+
+import (
+	"strings"
+)
+
+// Protocol versions. Version 1 messages only have the meta fields of version
+// 1, and are understood by all clients and servers. Version 2 messages may
+// also have the meta fields of the features negotiated for the connection.
+const (
+	ProtocolVersion1 uint8 = kCurrentVersion
+	ProtocolVersion2 uint8 = 2
+)
+
+// Feature is a bitmap of protocol version 2 features.
+//
+// A client sends a VerHandshake request with the features it wants to use in
+// the Features meta field, right after connecting. The server replies with the
+// features it supports among them, and only the negotiated features may be
+// used on the connection. A server not supporting protocol version 2 does not
+// reply with any feature, so the connection sticks to version 1.
+type Feature uint32
+
+const (
+	// Tenant meta field
+	FeatureTenant Feature = 1 << iota
+	// Trace Context meta field
+	FeatureTraceContext
+)
+
+// SupportedFeatures is the features implemented by this package.
+const SupportedFeatures = FeatureTenant | FeatureTraceContext
+
+var featureNames = []string{
+	"Tenant",
+	"TraceContext",
+}
+
+func (f Feature) Has(feature Feature) bool {
+	return f&feature == feature
+}
+
+func (f Feature) String() string {
+	var names []string
+	for i, name := range featureNames {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// NegotiateFeatures returns the features to use for a connection, given the
+// ones requested by the client.
+func NegotiateFeatures(requested Feature) Feature {
+	return requested & SupportedFeatures
+}
+
+// NewVerHandshakeRequest returns the VerHandshake request asking for features.
+func NewVerHandshakeRequest(features Feature) *OperationalMessage {
+	m := &OperationalMessage{}
+	m.SetRequest(OpCodeVerHandshake, nil, nil, nil, 0)
+	m.SetProtocolVersion(ProtocolVersion2)
+	m.SetHandshakeFeatures(features)
+	return m
+}
+
+// CreateVerHandshakeResponse returns the response to the VerHandshake request
+// m, with the features negotiated by NegotiateFeatures.
+func (m *OperationalMessage) CreateVerHandshakeResponse() *OperationalMessage {
+	resp := m.CreateResponse()
+	resp.SetOpStatus(OpStatusNoError)
+	features := NegotiateFeatures(m.GetHandshakeFeatures())
+	resp.SetHandshakeFeatures(features)
+	resp.EnableFeatures(features)
+	return resp
+}
+
+// GetProtocolVersion returns the protocol version the message is encoded, or
+// has been decoded, with.
+func (m *OperationalMessage) GetProtocolVersion() uint8 {
+	if m.protocolVersion == 0 {
+		return kCurrentVersion
+	}
+	return m.protocolVersion
+}
+
+func (m *OperationalMessage) SetProtocolVersion(version uint8) {
+	m.protocolVersion = version
+}
+
+// EnableFeatures sets the features the message may use. The message is
+// encoded with protocol version 2, and the meta fields of the features, if
+// any, or version 1 otherwise. Responses created with CreateResponse have the
+// features of the request.
+func (m *OperationalMessage) EnableFeatures(features Feature) {
+	m.features = features
+	if features != 0 {
+		m.protocolVersion = ProtocolVersion2
+	} else {
+		m.protocolVersion = ProtocolVersion1
+	}
+}
+
+func (m *OperationalMessage) GetEnabledFeatures() Feature {
+	return m.features
+}
+
+// SetHandshakeFeatures sets the Features meta field of a VerHandshake message.
+func (m *OperationalMessage) SetHandshakeFeatures(features Feature) {
+	m.handshakeFeatures.set(uint32(features))
+}
+
+func (m *OperationalMessage) GetHandshakeFeatures() Feature {
+	return Feature(m.handshakeFeatures.value())
+}
+
+// SetTenant sets the tenant of the request. It is only sent if FeatureTenant
+// is enabled.
+func (m *OperationalMessage) SetTenant(tenant []byte) {
+	m.tenant.set(tenant)
+}
+
+func (m *OperationalMessage) GetTenant() []byte {
+	return m.tenant.value()
+}
+
+// SetTraceContext sets the W3C traceparent of the request, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". It is only sent
+// if FeatureTraceContext is enabled.
+func (m *OperationalMessage) SetTraceContext(traceparent []byte) {
+	m.traceContext.set(traceparent)
+}
+
+func (m *OperationalMessage) GetTraceContext() []byte {
+	return m.traceContext.value()
+}
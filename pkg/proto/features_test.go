@@ -0,0 +1,108 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func encodeDecode(t *testing.T, m *OperationalMessage) (r *OperationalMessage) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	r = &OperationalMessage{}
+	if err := NewDecoder(&buf).Decode(r); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestProtocolVersion2(t *testing.T) {
+	request := &OperationalMessage{}
+	request.SetRequest(OpCodeGet, []byte("key"), []byte("ns"), nil, 0)
+	request.SetNewRequestID()
+	request.SetTenant([]byte("tenant1"))
+
+	// tenant not sent without the feature
+	r := encodeDecode(t, request)
+	if r.GetProtocolVersion() != ProtocolVersion1 || r.GetTenant() != nil {
+		t.Errorf("expected version 1 without tenant, got version %d tenant %q", r.GetProtocolVersion(), r.GetTenant())
+	}
+
+	request.EnableFeatures(FeatureTenant)
+	r = encodeDecode(t, request)
+	if r.GetProtocolVersion() != ProtocolVersion2 || string(r.GetTenant()) != "tenant1" {
+		t.Errorf("expected version 2 with tenant, got version %d tenant %q", r.GetProtocolVersion(), r.GetTenant())
+	}
+	if !bytes.Equal(r.GetKey(), []byte("key")) || r.GetRequestID() != request.GetRequestID() {
+		t.Error("v1 fields mismatch")
+	}
+
+	// responses are encoded with the version of the request
+	resp := r.CreateResponse()
+	resp.SetTenant(r.GetTenant())
+	if r = encodeDecode(t, resp); r.GetProtocolVersion() != ProtocolVersion2 || string(r.GetTenant()) != "tenant1" {
+		t.Errorf("expected version 2 response with tenant, got version %d", r.GetProtocolVersion())
+	}
+}
+
+func TestVerHandshake(t *testing.T) {
+	request := NewVerHandshakeRequest(FeatureTenant | Feature(1<<31))
+	r := encodeDecode(t, request)
+	if r.GetOpCode() != OpCodeVerHandshake || r.GetHandshakeFeatures() != FeatureTenant|Feature(1<<31) {
+		t.Fatalf("unexpected handshake request %s %s", r.GetOpCode(), r.GetHandshakeFeatures())
+	}
+	resp := encodeDecode(t, r.CreateVerHandshakeResponse())
+	if !resp.IsResponse() || resp.GetHandshakeFeatures() != FeatureTenant {
+		t.Errorf("unexpected negotiated features %s", resp.GetHandshakeFeatures())
+	}
+	if resp.GetProtocolVersion() != ProtocolVersion2 {
+		t.Errorf("unexpected response version %d", resp.GetProtocolVersion())
+	}
+
+	// nothing negotiated, version 1
+	resp = encodeDecode(t, NewVerHandshakeRequest(Feature(1<<31)).CreateVerHandshakeResponse())
+	if resp.GetHandshakeFeatures() != 0 || resp.GetProtocolVersion() != ProtocolVersion1 {
+		t.Errorf("unexpected response features %s version %d", resp.GetHandshakeFeatures(), resp.GetProtocolVersion())
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	traceparent := []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	request := &OperationalMessage{}
+	request.SetRequest(OpCodePrepareSet, []byte("key"), []byte("ns"), &Payload{}, 0)
+	request.SetTenant([]byte("tenant1"))
+	request.SetTraceContext(traceparent)
+
+	if r := encodeDecode(t, request); r.GetTraceContext() != nil {
+		t.Errorf("trace context %q sent without the feature", r.GetTraceContext())
+	}
+	request.EnableFeatures(FeatureTraceContext)
+	r := encodeDecode(t, request)
+	if !bytes.Equal(r.GetTraceContext(), traceparent) {
+		t.Errorf("expected trace context %q, got %q", traceparent, r.GetTraceContext())
+	}
+	if r.GetTenant() != nil {
+		t.Errorf("tenant %q sent without the feature", r.GetTenant())
+	}
+}
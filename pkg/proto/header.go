@@ -96,7 +96,7 @@ func (h *messageHeaderT) SetAsResponse() {
 }
 
 func (h *messageHeaderT) IsSupported() bool {
-	if h.magic == kMessageMagic && h.version == kCurrentVersion {
+	if h.magic == kMessageMagic && h.version >= ProtocolVersion1 && h.version <= ProtocolVersion2 {
 		if h.typeFlag.getMessageType() == kOperationalMessageType {
 			return true
 		}
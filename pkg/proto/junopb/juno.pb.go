@@ -0,0 +1,583 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.23.4
+// source: juno.proto
+
+package junopb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type OpCode int32
+
+const (
+	OpCode_OP_NOP     OpCode = 0
+	OpCode_OP_CREATE  OpCode = 1
+	OpCode_OP_GET     OpCode = 2
+	OpCode_OP_UPDATE  OpCode = 3
+	OpCode_OP_SET     OpCode = 4
+	OpCode_OP_DESTROY OpCode = 5
+	OpCode_OP_UDF_GET OpCode = 6
+	OpCode_OP_UDF_SET OpCode = 7
+)
+
+// Enum value maps for OpCode.
+var (
+	OpCode_name = map[int32]string{
+		0: "OP_NOP",
+		1: "OP_CREATE",
+		2: "OP_GET",
+		3: "OP_UPDATE",
+		4: "OP_SET",
+		5: "OP_DESTROY",
+		6: "OP_UDF_GET",
+		7: "OP_UDF_SET",
+	}
+	OpCode_value = map[string]int32{
+		"OP_NOP":     0,
+		"OP_CREATE":  1,
+		"OP_GET":     2,
+		"OP_UPDATE":  3,
+		"OP_SET":     4,
+		"OP_DESTROY": 5,
+		"OP_UDF_GET": 6,
+		"OP_UDF_SET": 7,
+	}
+)
+
+func (x OpCode) Enum() *OpCode {
+	p := new(OpCode)
+	*p = x
+	return p
+}
+
+func (x OpCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OpCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_juno_proto_enumTypes[0].Descriptor()
+}
+
+func (OpCode) Type() protoreflect.EnumType {
+	return &file_juno_proto_enumTypes[0]
+}
+
+func (x OpCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OpCode.Descriptor instead.
+func (OpCode) EnumDescriptor() ([]byte, []int) {
+	return file_juno_proto_rawDescGZIP(), []int{0}
+}
+
+// Status has the same values as the juno protocol operation status.
+type Status int32
+
+const (
+	Status_STATUS_NO_ERROR          Status = 0
+	Status_STATUS_BAD_MSG           Status = 1
+	Status_STATUS_SERVICE_DENIED    Status = 2
+	Status_STATUS_NO_KEY            Status = 3
+	Status_STATUS_DUP_KEY           Status = 4
+	Status_STATUS_BAD_PARAM         Status = 7
+	Status_STATUS_RECORD_LOCKED     Status = 8
+	Status_STATUS_NO_STORAGE_SERVER Status = 12
+	Status_STATUS_BUSY              Status = 14
+	Status_STATUS_VERSION_CONFLICT  Status = 19
+	Status_STATUS_REQ_PROC_TIMEOUT  Status = 24
+	Status_STATUS_COMMIT_FAILURE    Status = 25
+	Status_STATUS_INCONSISTENT      Status = 26
+	Status_STATUS_KEY_MARKED_DELETE Status = 27
+	Status_STATUS_NOT_SUPPORTED     Status = 28
+	Status_STATUS_UDF_ERROR         Status = 29
+	Status_STATUS_NO_UDF            Status = 30
+	Status_STATUS_INTERNAL          Status = 255
+)
+
+// Enum value maps for Status.
+var (
+	Status_name = map[int32]string{
+		0:   "STATUS_NO_ERROR",
+		1:   "STATUS_BAD_MSG",
+		2:   "STATUS_SERVICE_DENIED",
+		3:   "STATUS_NO_KEY",
+		4:   "STATUS_DUP_KEY",
+		7:   "STATUS_BAD_PARAM",
+		8:   "STATUS_RECORD_LOCKED",
+		12:  "STATUS_NO_STORAGE_SERVER",
+		14:  "STATUS_BUSY",
+		19:  "STATUS_VERSION_CONFLICT",
+		24:  "STATUS_REQ_PROC_TIMEOUT",
+		25:  "STATUS_COMMIT_FAILURE",
+		26:  "STATUS_INCONSISTENT",
+		27:  "STATUS_KEY_MARKED_DELETE",
+		28:  "STATUS_NOT_SUPPORTED",
+		29:  "STATUS_UDF_ERROR",
+		30:  "STATUS_NO_UDF",
+		255: "STATUS_INTERNAL",
+	}
+	Status_value = map[string]int32{
+		"STATUS_NO_ERROR":          0,
+		"STATUS_BAD_MSG":           1,
+		"STATUS_SERVICE_DENIED":    2,
+		"STATUS_NO_KEY":            3,
+		"STATUS_DUP_KEY":           4,
+		"STATUS_BAD_PARAM":         7,
+		"STATUS_RECORD_LOCKED":     8,
+		"STATUS_NO_STORAGE_SERVER": 12,
+		"STATUS_BUSY":              14,
+		"STATUS_VERSION_CONFLICT":  19,
+		"STATUS_REQ_PROC_TIMEOUT":  24,
+		"STATUS_COMMIT_FAILURE":    25,
+		"STATUS_INCONSISTENT":      26,
+		"STATUS_KEY_MARKED_DELETE": 27,
+		"STATUS_NOT_SUPPORTED":     28,
+		"STATUS_UDF_ERROR":         29,
+		"STATUS_NO_UDF":            30,
+		"STATUS_INTERNAL":          255,
+	}
+)
+
+func (x Status) Enum() *Status {
+	p := new(Status)
+	*p = x
+	return p
+}
+
+func (x Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_juno_proto_enumTypes[1].Descriptor()
+}
+
+func (Status) Type() protoreflect.EnumType {
+	return &file_juno_proto_enumTypes[1]
+}
+
+func (x Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Status.Descriptor instead.
+func (Status) EnumDescriptor() ([]byte, []int) {
+	return file_juno_proto_rawDescGZIP(), []int{1}
+}
+
+type Request struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Key       []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// value to store, or the parameters of a UDF call
+	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// time to live in seconds. For Get, extends the TTL if larger
+	Ttl uint32 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// record version for a conditional Update
+	Version uint32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// record creation time for a conditional Update
+	CreationTime uint32 `protobuf:"varint,6,opt,name=creation_time,json=creationTime,proto3" json:"creation_time,omitempty"`
+	UdfName      string `protobuf:"bytes,7,opt,name=udf_name,json=udfName,proto3" json:"udf_name,omitempty"`
+	// operation of a Batch request. Ignored by the other RPCs
+	Op OpCode `protobuf:"varint,8,opt,name=op,proto3,enum=juno.OpCode" json:"op,omitempty"`
+	// echoed back in the response of a Batch request
+	Id uint32 `protobuf:"varint,9,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *Request) Reset() {
+	*x = Request{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_juno_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Request) ProtoMessage() {}
+
+func (x *Request) ProtoReflect() protoreflect.Message {
+	mi := &file_juno_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Request.ProtoReflect.Descriptor instead.
+func (*Request) Descriptor() ([]byte, []int) {
+	return file_juno_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Request) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Request) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *Request) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Request) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *Request) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Request) GetCreationTime() uint32 {
+	if x != nil {
+		return x.CreationTime
+	}
+	return 0
+}
+
+func (x *Request) GetUdfName() string {
+	if x != nil {
+		return x.UdfName
+	}
+	return ""
+}
+
+func (x *Request) GetOp() OpCode {
+	if x != nil {
+		return x.Op
+	}
+	return OpCode_OP_NOP
+}
+
+func (x *Request) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status Status `protobuf:"varint,1,opt,name=status,proto3,enum=juno.Status" json:"status,omitempty"`
+	// value of Get and UDFGet, or the error message of a UDF call
+	Value        []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Version      uint32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	CreationTime uint32 `protobuf:"varint,4,opt,name=creation_time,json=creationTime,proto3" json:"creation_time,omitempty"`
+	Ttl          uint32 `protobuf:"varint,5,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	RequestId    string `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Id           uint32 `protobuf:"varint,7,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_juno_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_juno_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_juno_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Response) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_NO_ERROR
+}
+
+func (x *Response) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Response) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Response) GetCreationTime() uint32 {
+	if x != nil {
+		return x.CreationTime
+	}
+	return 0
+}
+
+func (x *Response) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *Response) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Response) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+var File_juno_proto protoreflect.FileDescriptor
+
+var file_juno_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x6a, 0x75,
+	0x6e, 0x6f, 0x22, 0xe9, 0x01, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x75, 0x64, 0x66, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x75, 0x64, 0x66, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x1c, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x6a,
+	0x75, 0x6e, 0x6f, 0x2e, 0x4f, 0x70, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x02, 0x6f, 0x70, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x22, 0xc6,
+	0x01, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x6a, 0x75,
+	0x6e, 0x6f, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x2a, 0x7a, 0x0a, 0x06, 0x4f, 0x70, 0x43, 0x6f, 0x64,
+	0x65, 0x12, 0x0a, 0x0a, 0x06, 0x4f, 0x50, 0x5f, 0x4e, 0x4f, 0x50, 0x10, 0x00, 0x12, 0x0d, 0x0a,
+	0x09, 0x4f, 0x50, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06,
+	0x4f, 0x50, 0x5f, 0x47, 0x45, 0x54, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x4f, 0x50, 0x5f, 0x55,
+	0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x4f, 0x50, 0x5f, 0x53, 0x45,
+	0x54, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x50, 0x5f, 0x44, 0x45, 0x53, 0x54, 0x52, 0x4f,
+	0x59, 0x10, 0x05, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x50, 0x5f, 0x55, 0x44, 0x46, 0x5f, 0x47, 0x45,
+	0x54, 0x10, 0x06, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x50, 0x5f, 0x55, 0x44, 0x46, 0x5f, 0x53, 0x45,
+	0x54, 0x10, 0x07, 0x2a, 0xb7, 0x03, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x13,
+	0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x4e, 0x4f, 0x5f, 0x45, 0x52, 0x52, 0x4f,
+	0x52, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x42, 0x41,
+	0x44, 0x5f, 0x4d, 0x53, 0x47, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x53, 0x45, 0x52, 0x56, 0x49, 0x43, 0x45, 0x5f, 0x44, 0x45, 0x4e, 0x49, 0x45, 0x44,
+	0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x4e, 0x4f, 0x5f,
+	0x4b, 0x45, 0x59, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x44, 0x55, 0x50, 0x5f, 0x4b, 0x45, 0x59, 0x10, 0x04, 0x12, 0x14, 0x0a, 0x10, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x42, 0x41, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x41, 0x4d, 0x10, 0x07, 0x12,
+	0x18, 0x0a, 0x14, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x43, 0x4f, 0x52, 0x44,
+	0x5f, 0x4c, 0x4f, 0x43, 0x4b, 0x45, 0x44, 0x10, 0x08, 0x12, 0x1c, 0x0a, 0x18, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x4e, 0x4f, 0x5f, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x53,
+	0x45, 0x52, 0x56, 0x45, 0x52, 0x10, 0x0c, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x42, 0x55, 0x53, 0x59, 0x10, 0x0e, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x54, 0x41, 0x54,
+	0x55, 0x53, 0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x4c,
+	0x49, 0x43, 0x54, 0x10, 0x13, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x52, 0x45, 0x51, 0x5f, 0x50, 0x52, 0x4f, 0x43, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f, 0x55, 0x54,
+	0x10, 0x18, 0x12, 0x19, 0x0a, 0x15, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x43, 0x4f, 0x4d,
+	0x4d, 0x49, 0x54, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x55, 0x52, 0x45, 0x10, 0x19, 0x12, 0x17, 0x0a,
+	0x13, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x49, 0x4e, 0x43, 0x4f, 0x4e, 0x53, 0x49, 0x53,
+	0x54, 0x45, 0x4e, 0x54, 0x10, 0x1a, 0x12, 0x1c, 0x0a, 0x18, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x4b, 0x45, 0x59, 0x5f, 0x4d, 0x41, 0x52, 0x4b, 0x45, 0x44, 0x5f, 0x44, 0x45, 0x4c, 0x45,
+	0x54, 0x45, 0x10, 0x1b, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x4e,
+	0x4f, 0x54, 0x5f, 0x53, 0x55, 0x50, 0x50, 0x4f, 0x52, 0x54, 0x45, 0x44, 0x10, 0x1c, 0x12, 0x14,
+	0x0a, 0x10, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x44, 0x46, 0x5f, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x10, 0x1d, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x4e,
+	0x4f, 0x5f, 0x55, 0x44, 0x46, 0x10, 0x1e, 0x12, 0x14, 0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0xff, 0x01, 0x32, 0xcc, 0x02,
+	0x0a, 0x04, 0x4a, 0x75, 0x6e, 0x6f, 0x12, 0x27, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x12, 0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0e, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x24, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e,
+	0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24,
+	0x0a, 0x03, 0x53, 0x65, 0x74, 0x12, 0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x07, 0x44, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79, 0x12,
+	0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e,
+	0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27,
+	0x0a, 0x06, 0x55, 0x44, 0x46, 0x47, 0x65, 0x74, 0x12, 0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x55, 0x44, 0x46, 0x53, 0x65,
+	0x74, 0x12, 0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0e, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2a, 0x0a, 0x05, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x0d, 0x2e, 0x6a, 0x75, 0x6e, 0x6f,
+	0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x6a, 0x75, 0x6e, 0x6f, 0x2e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x17, 0x5a, 0x15,
+	0x6a, 0x75, 0x6e, 0x6f, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6a,
+	0x75, 0x6e, 0x6f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_juno_proto_rawDescOnce sync.Once
+	file_juno_proto_rawDescData = file_juno_proto_rawDesc
+)
+
+func file_juno_proto_rawDescGZIP() []byte {
+	file_juno_proto_rawDescOnce.Do(func() {
+		file_juno_proto_rawDescData = protoimpl.X.CompressGZIP(file_juno_proto_rawDescData)
+	})
+	return file_juno_proto_rawDescData
+}
+
+var file_juno_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_juno_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_juno_proto_goTypes = []interface{}{
+	(OpCode)(0),      // 0: juno.OpCode
+	(Status)(0),      // 1: juno.Status
+	(*Request)(nil),  // 2: juno.Request
+	(*Response)(nil), // 3: juno.Response
+}
+var file_juno_proto_depIdxs = []int32{
+	0,  // 0: juno.Request.op:type_name -> juno.OpCode
+	1,  // 1: juno.Response.status:type_name -> juno.Status
+	2,  // 2: juno.Juno.Create:input_type -> juno.Request
+	2,  // 3: juno.Juno.Get:input_type -> juno.Request
+	2,  // 4: juno.Juno.Update:input_type -> juno.Request
+	2,  // 5: juno.Juno.Set:input_type -> juno.Request
+	2,  // 6: juno.Juno.Destroy:input_type -> juno.Request
+	2,  // 7: juno.Juno.UDFGet:input_type -> juno.Request
+	2,  // 8: juno.Juno.UDFSet:input_type -> juno.Request
+	2,  // 9: juno.Juno.Batch:input_type -> juno.Request
+	3,  // 10: juno.Juno.Create:output_type -> juno.Response
+	3,  // 11: juno.Juno.Get:output_type -> juno.Response
+	3,  // 12: juno.Juno.Update:output_type -> juno.Response
+	3,  // 13: juno.Juno.Set:output_type -> juno.Response
+	3,  // 14: juno.Juno.Destroy:output_type -> juno.Response
+	3,  // 15: juno.Juno.UDFGet:output_type -> juno.Response
+	3,  // 16: juno.Juno.UDFSet:output_type -> juno.Response
+	3,  // 17: juno.Juno.Batch:output_type -> juno.Response
+	10, // [10:18] is the sub-list for method output_type
+	2,  // [2:10] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_juno_proto_init() }
+func file_juno_proto_init() {
+	if File_juno_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_juno_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Request); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_juno_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Response); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_juno_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_juno_proto_goTypes,
+		DependencyIndexes: file_juno_proto_depIdxs,
+		EnumInfos:         file_juno_proto_enumTypes,
+		MessageInfos:      file_juno_proto_msgTypes,
+	}.Build()
+	File_juno_proto = out.File
+	file_juno_proto_rawDesc = nil
+	file_juno_proto_goTypes = nil
+	file_juno_proto_depIdxs = nil
+}
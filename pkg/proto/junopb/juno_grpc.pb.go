@@ -0,0 +1,405 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: juno.proto
+
+package junopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Juno_Create_FullMethodName  = "/juno.Juno/Create"
+	Juno_Get_FullMethodName     = "/juno.Juno/Get"
+	Juno_Update_FullMethodName  = "/juno.Juno/Update"
+	Juno_Set_FullMethodName     = "/juno.Juno/Set"
+	Juno_Destroy_FullMethodName = "/juno.Juno/Destroy"
+	Juno_UDFGet_FullMethodName  = "/juno.Juno/UDFGet"
+	Juno_UDFSet_FullMethodName  = "/juno.Juno/UDFSet"
+	Juno_Batch_FullMethodName   = "/juno.Juno/Batch"
+)
+
+// JunoClient is the client API for Juno service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JunoClient interface {
+	Create(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	Get(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	Update(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	Set(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	Destroy(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	UDFGet(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	UDFSet(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	// Batch processes the requests concurrently. Responses may come back out of
+	// order, and are matched with the requests by id.
+	Batch(ctx context.Context, opts ...grpc.CallOption) (Juno_BatchClient, error)
+}
+
+type junoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJunoClient(cc grpc.ClientConnInterface) JunoClient {
+	return &junoClient{cc}
+}
+
+func (c *junoClient) Create(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) Get(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) Update(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) Set(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_Set_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) Destroy(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_Destroy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) UDFGet(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_UDFGet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) UDFSet(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Juno_UDFSet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *junoClient) Batch(ctx context.Context, opts ...grpc.CallOption) (Juno_BatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Juno_ServiceDesc.Streams[0], Juno_Batch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &junoBatchClient{stream}
+	return x, nil
+}
+
+type Juno_BatchClient interface {
+	Send(*Request) error
+	Recv() (*Response, error)
+	grpc.ClientStream
+}
+
+type junoBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *junoBatchClient) Send(m *Request) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *junoBatchClient) Recv() (*Response, error) {
+	m := new(Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// JunoServer is the server API for Juno service.
+// All implementations must embed UnimplementedJunoServer
+// for forward compatibility
+type JunoServer interface {
+	Create(context.Context, *Request) (*Response, error)
+	Get(context.Context, *Request) (*Response, error)
+	Update(context.Context, *Request) (*Response, error)
+	Set(context.Context, *Request) (*Response, error)
+	Destroy(context.Context, *Request) (*Response, error)
+	UDFGet(context.Context, *Request) (*Response, error)
+	UDFSet(context.Context, *Request) (*Response, error)
+	// Batch processes the requests concurrently. Responses may come back out of
+	// order, and are matched with the requests by id.
+	Batch(Juno_BatchServer) error
+	mustEmbedUnimplementedJunoServer()
+}
+
+// UnimplementedJunoServer must be embedded to have forward compatible implementations.
+type UnimplementedJunoServer struct {
+}
+
+func (UnimplementedJunoServer) Create(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedJunoServer) Get(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedJunoServer) Update(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedJunoServer) Set(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Set not implemented")
+}
+func (UnimplementedJunoServer) Destroy(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Destroy not implemented")
+}
+func (UnimplementedJunoServer) UDFGet(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UDFGet not implemented")
+}
+func (UnimplementedJunoServer) UDFSet(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UDFSet not implemented")
+}
+func (UnimplementedJunoServer) Batch(Juno_BatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedJunoServer) mustEmbedUnimplementedJunoServer() {}
+
+// UnsafeJunoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JunoServer will
+// result in compilation errors.
+type UnsafeJunoServer interface {
+	mustEmbedUnimplementedJunoServer()
+}
+
+func RegisterJunoServer(s grpc.ServiceRegistrar, srv JunoServer) {
+	s.RegisterService(&Juno_ServiceDesc, srv)
+}
+
+func _Juno_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).Create(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).Get(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).Update(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).Set(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_Set_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).Set(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_Destroy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).Destroy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_Destroy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).Destroy(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_UDFGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).UDFGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_UDFGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).UDFGet(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_UDFSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JunoServer).UDFSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Juno_UDFSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JunoServer).UDFSet(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Juno_Batch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(JunoServer).Batch(&junoBatchServer{stream})
+}
+
+type Juno_BatchServer interface {
+	Send(*Response) error
+	Recv() (*Request, error)
+	grpc.ServerStream
+}
+
+type junoBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *junoBatchServer) Send(m *Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *junoBatchServer) Recv() (*Request, error) {
+	m := new(Request)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Juno_ServiceDesc is the grpc.ServiceDesc for Juno service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Juno_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "juno.Juno",
+	HandlerType: (*JunoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Juno_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Juno_Get_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Juno_Update_Handler,
+		},
+		{
+			MethodName: "Set",
+			Handler:    _Juno_Set_Handler,
+		},
+		{
+			MethodName: "Destroy",
+			Handler:    _Juno_Destroy_Handler,
+		},
+		{
+			MethodName: "UDFGet",
+			Handler:    _Juno_UDFGet_Handler,
+		},
+		{
+			MethodName: "UDFSet",
+			Handler:    _Juno_UDFSet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Batch",
+			Handler:       _Juno_Batch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "juno.proto",
+}
@@ -35,6 +35,9 @@
     0x09 | Correlation ID                       | 0
     0x0a | RequestHandlingTime                  | 0x01
 	0x0b | UDF Name			                    | 0
+    0x0c | Tenant (protocol version 2)          | 0
+    0x0d | Features (VerHandshake only)         | 0x01
+    0x0e | Trace Context (protocol version 2)   | 0
   -------+--------------------------------------+------
 
 
@@ -50,7 +53,7 @@
   | application name, padding to 4-byte aligned                                                   |
   +-----------------------------------------------------------------------------------------------+
 
-  Tag/ID: 0x09; 0x0b
+  Tag/ID: 0x09; 0x0b; 0x0c; 0x0e
   +----+-------------------------------------------
   |  0 | field size (including padding)
   +----+-------------------------------------------
@@ -81,6 +84,9 @@ const (
 	kFieldTagCorrelationID
 	kFieldTagRequestHandlingTime
 	kFieldTagUDFName
+	kFieldTagTenant
+	kFieldTagFeatures
+	kFieldTagTraceContext
 	kNumSupportedFields
 )
 
@@ -119,6 +125,9 @@ type (
 
 	correlationIdT struct{ byteSequenceT }
 	udfNameT       struct{ byteSequenceT }
+	tenantT        struct{ byteSequenceT }
+	featuresT      struct{ uint32T }
+	traceContextT  struct{ byteSequenceT }
 )
 
 func (t uint32T) isSet() bool {
@@ -173,6 +182,10 @@ func (t requestHandlingTimeT) tagAndSizeTypeByte() uint8 {
 	return kFieldTagRequestHandlingTime | kMetaField_4Bytes
 }
 
+func (t featuresT) tagAndSizeTypeByte() uint8 {
+	return kFieldTagFeatures | kMetaField_4Bytes
+}
+
 //uint64 meta field
 func (t uint64T) isSet() bool {
 	return t != 0
@@ -462,3 +475,11 @@ func (t correlationIdT) tagAndSizeTypeByte() uint8 {
 func (t udfNameT) tagAndSizeTypeByte() uint8 {
 	return kFieldTagUDFName | kMetaFieldVariableSize
 }
+
+func (t tenantT) tagAndSizeTypeByte() uint8 {
+	return kFieldTagTenant | kMetaFieldVariableSize
+}
+
+func (t traceContextT) tagAndSizeTypeByte() uint8 {
+	return kFieldTagTraceContext | kMetaFieldVariableSize
+}
@@ -52,6 +52,13 @@ type OperationalMessage struct {
 	correlationID        correlationIdT
 	requestHandlingTime  requestHandlingTimeT
 	udfName              udfNameT
+	tenant               tenantT
+	handshakeFeatures    featuresT
+	traceContext         traceContextT
+
+	// protocol version of the message, and the features which it may use.
+	protocolVersion uint8
+	features        Feature
 }
 
 func (op *OperationalMessage) SetMessage(opcode OpCode, key []byte, namespace []byte, payload *Payload, ttl uint32) {
@@ -80,6 +87,8 @@ func (op *OperationalMessage) CreateResponse() (resp *OperationalMessage) {
 	resp.key = op.key
 	resp.namespace = op.namespace
 	resp.requestID = op.requestID
+	resp.protocolVersion = op.protocolVersion
+	resp.features = op.features
 	//	if resp.originatorRequestID.IsSet() {
 	//		panic("")
 	//	}
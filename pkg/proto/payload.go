@@ -31,8 +31,6 @@ import (
 	"juno/third_party/forked/golang/glog"
 
 	"juno/pkg/util"
-
-	"github.com/golang/snappy"
 )
 
 const (
@@ -71,6 +69,8 @@ func (t PayloadType) String() string {
 		return "encrypted by client"
 	case PayloadTypeEncryptedByProxy:
 		return "encrypted by proxy"
+	case PayloadTypecompressedByClient:
+		return "compressed by client"
 	default:
 		return fmt.Sprintf("unsupported payload type: %d", t)
 	}
@@ -106,28 +106,25 @@ func (p *Payload) SetWithClearValue(value []byte) {
 	p.data = value
 }
 
-///TODO
+// /TODO
 func (p *Payload) GetClearValue() (value []byte, err error) {
+	return p.GetClearValueWithKeyStore(nil)
+}
+
+// GetClearValueWithKeyStore is GetClearValue, but decrypts the value with ks,
+// instead of the key store initialized with InitializeKeyStore, if ks is not
+// nil.
+func (p *Payload) GetClearValueWithKeyStore(ks IEncryptionKeyStore) (value []byte, err error) {
 	if p.GetLength() == 0 {
 		return
 	}
 	pl := *p
 	if pl.tag == PayloadTypecompressedByClient {
-		//Get compression type
-		compTypeSize := uint8(pl.data[0])
-		if len(pl.data[1:]) > int(compTypeSize) {
-			compType := string(pl.data[1 : compTypeSize+1])
-			if compType == SnappyCompression {
-				err = nil
-				if value, err = snappy.Decode(nil, pl.data[compTypeSize+1:]); err != nil {
-					glog.Error("Error while uncompressing :", err)
-				}
-			} else {
-				err = ErrUnsupportedCompressionType
-			}
+		if value, err = pl.getDecompressedValue(); err != nil {
+			glog.Error("Error while uncompressing :", err)
 		}
 	} else {
-		if err = pl.Decrypt(); err == nil {
+		if err = pl.DecryptWithKeyStore(ks); err == nil {
 			value = pl.data
 		}
 	}
@@ -135,12 +132,20 @@ func (p *Payload) GetClearValue() (value []byte, err error) {
 }
 
 func (p *Payload) Encrypt(pType PayloadType) (err error) {
+	return p.EncryptWithKeyStore(pType, nil)
+}
+
+// EncryptWithKeyStore is Encrypt, but uses ks, instead of the key store
+// initialized with InitializeKeyStore, if ks is not nil.
+func (p *Payload) EncryptWithKeyStore(pType PayloadType, ks IEncryptionKeyStore) (err error) {
 	if p.GetLength() == 0 {
 		return nil
 	}
 	if p.tag == PayloadTypeClear {
-		var ks IEncryptionKeyStore
-		if ks, err = getKeyStore(pType); err == nil {
+		if ks == nil {
+			ks, err = getKeyStore(pType)
+		}
+		if err == nil {
 			var key []byte
 			var version uint32
 			var block cipher.Block
@@ -172,13 +177,22 @@ func (p *Payload) Encrypt(pType PayloadType) (err error) {
 }
 
 func (p *Payload) Decrypt() (err error) {
+	return p.DecryptWithKeyStore(nil)
+}
+
+// DecryptWithKeyStore is Decrypt, but uses ks, instead of the key store
+// initialized with InitializeKeyStore, if ks is not nil.
+func (p *Payload) DecryptWithKeyStore(ks IEncryptionKeyStore) (err error) {
 	if p.GetLength() == 0 || p.tag == PayloadTypeClear {
 		return nil
 	}
 
-	var ks IEncryptionKeyStore
-
-	if ks, err = getKeyStore(p.tag); err == nil {
+	if ks == nil {
+		ks, err = getKeyStore(p.tag)
+	} else if p.tag != PayloadTypeEncryptedByClient && p.tag != PayloadTypeEncryptedByProxy {
+		err = ErrUnsupportedPayloadType
+	}
+	if err == nil {
 
 		data := p.GetData()
 		if len(data) < 4+12+16 {
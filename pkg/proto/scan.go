@@ -0,0 +1,307 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Scan returns the records of the namespace of the request, in key order, one
+// page at a time. The key of the request is the key prefix of the records to
+// return, and may be empty. The payload is a clear ScanRequest.
+//
+// The response payload is a clear ScanPage. The Token of the page is to be set
+// to the ScanRequest for the next page, and is empty on the last page.
+//
+// A proxy sends a Scan request to the storage servers of each shard, with the
+// shard id set, and merges the pages returned.
+
+const (
+	// DefaultScanLimit is the page size of a ScanRequest without Limit.
+	DefaultScanLimit = 100
+	// MaxScanLimit is the maximum page size of a ScanRequest.
+	MaxScanLimit = 1000
+)
+
+var errScanTruncated = fmt.Errorf("truncated scan payload")
+
+// ScanRequest is the payload of a Scan request.
+//
+//	Offset | Field       | Size
+//	-------+-------------+---------
+//	     0 | limit       | 4 bytes
+//	     4 | token       | ...
+type ScanRequest struct {
+	Limit uint32
+	Token []byte
+}
+
+// ScanEntry is a record returned by Scan.
+type ScanEntry struct {
+	Key          []byte
+	Payload      Payload
+	Version      uint32
+	CreationTime uint32
+	TimeToLive   uint32
+	// MarkedDelete is set for the tombstones returned by the storage servers,
+	// for the merge to drop the older versions of the other replicas. They
+	// are not returned to clients.
+	MarkedDelete bool
+}
+
+const kScanEntryMarkedDelete = 0x1
+
+// ScanPage is the payload of a Scan response.
+//
+//	Field                 | Size
+//	----------------------+---------
+//	token length          | 2 bytes
+//	token                 | ...
+//	number of entries     | 4 bytes
+//	entries               | ...
+//
+// each entry being
+//
+//	Field                 | Size
+//	----------------------+---------
+//	key length            | 2 bytes
+//	key                   | ...
+//	flags                 | 1 byte
+//	version               | 4 bytes
+//	creation time         | 4 bytes
+//	time to live          | 4 bytes
+//	payload length        | 4 bytes
+//	payload               | ...
+type ScanPage struct {
+	Entries []ScanEntry
+	Token   []byte
+}
+
+// GetLimit returns the page size, capped to MaxScanLimit.
+func (r *ScanRequest) GetLimit() int {
+	if r.Limit == 0 {
+		return DefaultScanLimit
+	}
+	if r.Limit > MaxScanLimit {
+		return MaxScanLimit
+	}
+	return int(r.Limit)
+}
+
+func (r *ScanRequest) Encode() []byte {
+	buf := make([]byte, 4+len(r.Token))
+	binary.BigEndian.PutUint32(buf, r.Limit)
+	copy(buf[4:], r.Token)
+	return buf
+}
+
+func (r *ScanRequest) Decode(raw []byte) error {
+	if len(raw) == 0 {
+		*r = ScanRequest{}
+		return nil
+	}
+	if len(raw) < 4 {
+		return errScanTruncated
+	}
+	r.Limit = binary.BigEndian.Uint32(raw)
+	r.Token = append([]byte(nil), raw[4:]...)
+	return nil
+}
+
+// Encode encodes the page, failing if a token, key or payload is too long for
+// its length field.
+func (p *ScanPage) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	var b [4]byte
+
+	if len(p.Token) > math.MaxUint16 {
+		return nil, fmt.Errorf("scan token of %d bytes too long", len(p.Token))
+	}
+	binary.BigEndian.PutUint16(b[:2], uint16(len(p.Token)))
+	buf.Write(b[:2])
+	buf.Write(p.Token)
+	binary.BigEndian.PutUint32(b[:], uint32(len(p.Entries)))
+	buf.Write(b[:])
+
+	for i := range p.Entries {
+		e := &p.Entries[i]
+		if len(e.Key) > math.MaxUint16 {
+			return nil, fmt.Errorf("scan key of %d bytes too long", len(e.Key))
+		}
+		if uint64(len(e.Payload.GetData())) >= math.MaxUint32 {
+			return nil, fmt.Errorf("scan payload of %d bytes too long", len(e.Payload.GetData()))
+		}
+		binary.BigEndian.PutUint16(b[:2], uint16(len(e.Key)))
+		buf.Write(b[:2])
+		buf.Write(e.Key)
+		var flags byte
+		if e.MarkedDelete {
+			flags |= kScanEntryMarkedDelete
+		}
+		buf.WriteByte(flags)
+		for _, v := range []uint32{e.Version, e.CreationTime, e.TimeToLive, e.Payload.GetLength()} {
+			binary.BigEndian.PutUint32(b[:], v)
+			buf.Write(b[:])
+		}
+		e.Payload.EncodeToBuffer(&buf)
+	}
+	if uint64(buf.Len()) > math.MaxUint32 {
+		return nil, fmt.Errorf("scan page of %d bytes too large", buf.Len())
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decodes the page. The entries are copied from raw.
+func (p *ScanPage) Decode(raw []byte) error {
+	*p = ScanPage{}
+	if len(raw) == 0 {
+		return nil
+	}
+	next := func(n int) (b []byte, err error) {
+		if len(raw) < n {
+			return nil, errScanTruncated
+		}
+		b, raw = raw[:n], raw[n:]
+		return
+	}
+
+	b, err := next(2)
+	if err != nil {
+		return err
+	}
+	if b, err = next(int(binary.BigEndian.Uint16(b))); err != nil {
+		return err
+	}
+	p.Token = append([]byte(nil), b...)
+	if b, err = next(4); err != nil {
+		return err
+	}
+	num := binary.BigEndian.Uint32(b)
+
+	for i := uint32(0); i < num; i++ {
+		var e ScanEntry
+		if b, err = next(2); err != nil {
+			return err
+		}
+		if b, err = next(int(binary.BigEndian.Uint16(b))); err != nil {
+			return err
+		}
+		e.Key = append([]byte(nil), b...)
+		if b, err = next(17); err != nil {
+			return err
+		}
+		e.MarkedDelete = b[0]&kScanEntryMarkedDelete != 0
+		e.Version = binary.BigEndian.Uint32(b[1:5])
+		e.CreationTime = binary.BigEndian.Uint32(b[5:9])
+		e.TimeToLive = binary.BigEndian.Uint32(b[9:13])
+		if b, err = next(int(binary.BigEndian.Uint32(b[13:17]))); err != nil {
+			return err
+		}
+		e.Payload.Decode(b, true)
+		p.Entries = append(p.Entries, e)
+	}
+	return nil
+}
+
+// MergeScanPages merges the pages returned for the same ScanRequest, by the
+// shards or by the replicas of a shard, into the page of the first limit
+// entries in key order. The entries of the same key are merged into the one of
+// the newest version, a tombstone winning over a record of the same version,
+// so that the tombstones are to be dropped only once merged. A page with a token is only complete up to the token, so
+// the merged page does not go beyond the smallest one.
+func MergeScanPages(pages []ScanPage, limit int) (merged ScanPage) {
+	var bound []byte
+	more := false
+	index := make(map[string]int)
+	for i := range pages {
+		if token := pages[i].Token; len(token) != 0 {
+			if !more || bytes.Compare(token, bound) < 0 {
+				bound = token
+			}
+			more = true
+		}
+	}
+	for i := range pages {
+		for _, e := range pages[i].Entries {
+			if more && bytes.Compare(e.Key, bound) > 0 {
+				continue
+			}
+			if j, found := index[string(e.Key)]; found {
+				if m := &merged.Entries[j]; e.Version > m.Version ||
+					(e.Version == m.Version && e.MarkedDelete && !m.MarkedDelete) {
+					merged.Entries[j] = e
+				}
+				continue
+			}
+			index[string(e.Key)] = len(merged.Entries)
+			merged.Entries = append(merged.Entries, e)
+		}
+	}
+	sort.Slice(merged.Entries, func(i, j int) bool {
+		return bytes.Compare(merged.Entries[i].Key, merged.Entries[j].Key) < 0
+	})
+	if len(merged.Entries) > limit {
+		merged.Entries = merged.Entries[:limit]
+		more = true
+	}
+	if more && len(merged.Entries) != 0 {
+		merged.Token = merged.Entries[len(merged.Entries)-1].Key
+	}
+	return
+}
+
+// NewScanRequest returns the Scan request of a page of the records of ns with
+// the key prefix.
+func NewScanRequest(ns []byte, prefix []byte, scanReq *ScanRequest) *OperationalMessage {
+	var payload Payload
+	payload.SetWithClearValue(scanReq.Encode())
+	m := &OperationalMessage{}
+	m.SetRequest(OpCodeScan, prefix, ns, &payload, 0)
+	m.SetNewRequestID()
+	return m
+}
+
+// GetScanRequest decodes the payload of a Scan request.
+func (m *OperationalMessage) GetScanRequest() (scanReq ScanRequest, err error) {
+	err = scanReq.Decode(m.GetPayload().GetData())
+	return
+}
+
+// GetScanPage decodes the payload of a Scan response.
+func (m *OperationalMessage) GetScanPage() (page ScanPage, err error) {
+	err = page.Decode(m.GetPayload().GetData())
+	return
+}
+
+// SetScanPage sets the page to the payload of a Scan response.
+func (m *OperationalMessage) SetScanPage(page *ScanPage) error {
+	raw, err := page.Encode()
+	if err != nil {
+		return err
+	}
+	var payload Payload
+	payload.SetWithClearValue(raw)
+	m.SetPayload(&payload)
+	return nil
+}
@@ -0,0 +1,154 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScanRequest(t *testing.T) {
+	request := NewScanRequest([]byte("ns"), []byte("a:"), &ScanRequest{Limit: 10, Token: []byte("a:5")})
+	r := encodeDecode(t, request)
+	if r.GetOpCode() != OpCodeScan || string(r.GetNamespace()) != "ns" || string(r.GetKey()) != "a:" {
+		t.Errorf("unexpected request %s ns=%q key=%q", r.GetOpCode(), r.GetNamespace(), r.GetKey())
+	}
+	scanReq, err := r.GetScanRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scanReq.GetLimit() != 10 || string(scanReq.Token) != "a:5" {
+		t.Errorf("unexpected scan request %+v", scanReq)
+	}
+
+	for limit, expected := range map[uint32]int{0: DefaultScanLimit, MaxScanLimit + 1: MaxScanLimit} {
+		if l := (&ScanRequest{Limit: limit}).GetLimit(); l != expected {
+			t.Errorf("limit %d: expected %d, got %d", limit, expected, l)
+		}
+	}
+}
+
+func TestScanPage(t *testing.T) {
+	page := &ScanPage{Token: []byte("a:2")}
+	for i, key := range []string{"a:1", "a:2"} {
+		e := ScanEntry{Key: []byte(key), Version: uint32(i + 1), CreationTime: 100, TimeToLive: 60}
+		e.Payload.SetPayload(PayloadTypeEncryptedByClient, []byte("value "+key))
+		page.Entries = append(page.Entries, e)
+	}
+	page.Entries = append(page.Entries, ScanEntry{Key: []byte("a:3")}, ScanEntry{Key: []byte("a:4"), MarkedDelete: true})
+
+	request := NewScanRequest([]byte("ns"), nil, &ScanRequest{})
+	response := request.CreateResponse()
+	if err := response.SetScanPage(page); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := encodeDecode(t, response).GetScanPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Token, page.Token) || len(decoded.Entries) != len(page.Entries) {
+		t.Fatalf("unexpected page %+v", decoded)
+	}
+	for i := range page.Entries {
+		e, d := &page.Entries[i], &decoded.Entries[i]
+		if !bytes.Equal(e.Key, d.Key) || !e.Payload.Equal(&d.Payload) || e.Version != d.Version ||
+			e.CreationTime != d.CreationTime || e.TimeToLive != d.TimeToLive || e.MarkedDelete != d.MarkedDelete {
+			t.Errorf("entry %d: expected %+v, got %+v", i, e, d)
+		}
+	}
+
+	raw, _ := page.Encode()
+	if err = decoded.Decode(raw[:10]); err == nil {
+		t.Error("expected error on truncated page")
+	}
+
+	for _, p := range []*ScanPage{
+		{Token: make([]byte, 1<<16)},
+		{Entries: []ScanEntry{{Key: make([]byte, 1<<16)}}},
+	} {
+		if _, err = p.Encode(); err == nil {
+			t.Error("expected error on too long token or key")
+		}
+	}
+}
+
+func TestMergeScanPages(t *testing.T) {
+	page := func(token string, keys ...string) (p ScanPage) {
+		p.Token = []byte(token)
+		for _, k := range keys {
+			p.Entries = append(p.Entries, ScanEntry{Key: []byte(k)})
+		}
+		return
+	}
+	tests := []struct {
+		pages []ScanPage
+		limit int
+		keys  string
+		token string
+	}{
+		{[]ScanPage{page("", "b", "d"), page("", "a", "c"), page("")}, 10, "abcd", ""},
+		{[]ScanPage{page("", "b", "d"), page("", "a", "c")}, 3, "abc", "c"},
+		{[]ScanPage{page("c", "b", "c"), page("", "a")}, 3, "abc", "c"},
+		{[]ScanPage{page("b", "a", "b"), page("", "c", "d")}, 2, "ab", "b"},
+		{[]ScanPage{page(""), page("")}, 2, "", ""},
+		// replicas, complete up to the smallest token
+		{[]ScanPage{page("c", "a", "c"), page("d", "b", "c", "d")}, 10, "abc", "c"},
+		{[]ScanPage{page("", "a", "b"), page("", "b", "c")}, 10, "abc", ""},
+	}
+	for i, tc := range tests {
+		merged := MergeScanPages(tc.pages, tc.limit)
+		var keys string
+		for _, e := range merged.Entries {
+			keys += string(e.Key)
+		}
+		if keys != tc.keys || string(merged.Token) != tc.token {
+			t.Errorf("case %d: expected keys %q token %q, got %q %q", i, tc.keys, tc.token, keys, merged.Token)
+		}
+	}
+
+	// the newest version of a key is kept
+	old := ScanPage{Entries: []ScanEntry{{Key: []byte("k"), Version: 1}}}
+	newer := ScanPage{Entries: []ScanEntry{{Key: []byte("k"), Version: 2}}}
+	for _, pages := range [][]ScanPage{{old, newer}, {newer, old}} {
+		merged := MergeScanPages(pages, 10)
+		if len(merged.Entries) != 1 || merged.Entries[0].Version != 2 {
+			t.Errorf("expected the version 2 only, got %+v", merged.Entries)
+		}
+	}
+
+	// a newer tombstone, or one of the same version, wins over the record
+	for _, v := range []uint32{2, 1} {
+		tombstone := ScanPage{Entries: []ScanEntry{{Key: []byte("k"), Version: v, MarkedDelete: true}}}
+		for _, pages := range [][]ScanPage{{old, tombstone}, {tombstone, old}} {
+			merged := MergeScanPages(pages, 10)
+			if len(merged.Entries) != 1 || !merged.Entries[0].MarkedDelete {
+				t.Errorf("expected the tombstone version %d only, got %+v", v, merged.Entries)
+			}
+		}
+	}
+	// but not over a newer record
+	tombstone := ScanPage{Entries: []ScanEntry{{Key: []byte("k"), Version: 1, MarkedDelete: true}}}
+	for _, pages := range [][]ScanPage{{newer, tombstone}, {tombstone, newer}} {
+		merged := MergeScanPages(pages, 10)
+		if len(merged.Entries) != 1 || merged.Entries[0].MarkedDelete {
+			t.Errorf("expected the version 2 only, got %+v", merged.Entries)
+		}
+	}
+}
@@ -290,6 +290,13 @@ func (ctx *tlsContextT) newServerConn(conn net.Conn) (sconn Conn, err error) {
 	return
 }
 
+func (ctx *tlsContextT) getConfig() *tls.Config {
+	if ctx.config == nil {
+		return nil
+	}
+	return ctx.config.Clone()
+}
+
 func (ctx *tlsContextT) dial(target string, timeout time.Duration) (conn Conn, err error) {
 	if ctx.config == nil {
 		err = fmt.Errorf("nil config")
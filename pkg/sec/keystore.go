@@ -24,7 +24,6 @@ import (
 	"errors"
 	"fmt"
 	"juno/pkg/proto"
-	"juno/third_party/forked/golang/glog"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -49,9 +48,20 @@ type localSecretsConfig struct {
 
 // Initialize a localFileStore
 func initLocalFileStore(cfg *Config) (proto.IEncryptionKeyStore, error) {
+	return NewLocalFileStore(cfg.KeyStoreFilePath)
+}
+
+// NewLocalFileStore loads the hex encoded keys from the TOML file, e.g.
+//
+//	hexKeys = ["E1E7B65F...", "76B86D23..."]
+//
+// The key version is the index of the key in the list, and is stored with the
+// encrypted value. New keys can be appended, but a key cannot be removed as long
+// as values encrypted with it may still be stored.
+func NewLocalFileStore(keyStoreFilePath string) (proto.IEncryptionKeyStore, error) {
 
 	secretcfg := &localSecretsConfig{}
-	if _, err := toml.DecodeFile(cfg.KeyStoreFilePath, secretcfg); err != nil {
+	if _, err := toml.DecodeFile(keyStoreFilePath, secretcfg); err != nil {
 		return nil, err
 	}
 
@@ -69,7 +79,7 @@ func initLocalFileStore(cfg *Config) (proto.IEncryptionKeyStore, error) {
 	for i, str := range secretcfg.HexKeys {
 		ks.keys[i], err = hex.DecodeString(str)
 		if err != nil {
-			glog.Exitf("fail to generate keys for test encryption key store, exiting...")
+			return nil, fmt.Errorf("invalid hex key at index %d in %s: %s", i, keyStoreFilePath, err)
 		}
 	}
 	return ks, nil
@@ -20,6 +20,7 @@
 package sec
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"time"
@@ -39,6 +40,7 @@ type (
 	tlsContextI interface {
 		newServerConn(conn net.Conn) (Conn, error)
 		dial(target string, timeout time.Duration) (conn Conn, err error)
+		getConfig() *tls.Config
 		//	loadSessions(sessions [][]byte)
 	}
 )
@@ -59,6 +61,18 @@ func Dial(target string, timeout time.Duration) (conn Conn, err error) {
 	return
 }
 
+// GetServerTlsConfig returns a copy of the server side tls config, for the
+// servers not built on Conn, like the gRPC server
+func GetServerTlsConfig() (cfg *tls.Config, err error) {
+	var ctx tlsContextI
+	if ctx, err = getServerTlsContext(); err == nil {
+		if cfg = ctx.getConfig(); cfg == nil {
+			err = fmt.Errorf("nil config")
+		}
+	}
+	return
+}
+
 func getServerTlsContext() (ctx tlsContextI, err error) {
 	gRwCtxMtx.RLock()
 	ctx = gSvrTlsCtx
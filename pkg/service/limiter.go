@@ -19,11 +19,125 @@
 
 package service
 
-import ()
+import (
+	"math"
+	"sync"
+	"time"
+)
 
 type (
 	ILimiter interface {
 		LimitReached() bool
 		Throttle()
 	}
+
+	// TokenBucket is a token bucket rate limiter. The bucket holds up to one
+	// second worth of tokens. A cost larger than the rate is allowed once the
+	// bucket is full, which drives the bucket into debt to be paid before
+	// anything else is allowed.
+	TokenBucket struct {
+		mtx      sync.Mutex
+		rate     float64
+		tokens   float64
+		lastFill time.Time
+	}
 )
+
+// NewTokenBucket returns a full bucket refilled at rate tokens per second. A
+// rate of 0 means no limit.
+func NewTokenBucket(rate uint32) *TokenBucket {
+	return &TokenBucket{
+		rate:     float64(rate),
+		tokens:   float64(rate),
+		lastFill: time.Now(),
+	}
+}
+
+func (b *TokenBucket) fill(now time.Time) {
+	b.tokens += now.Sub(b.lastFill).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.lastFill = now
+}
+
+// SetRate changes the rate, keeping the tokens in the bucket up to the new rate.
+func (b *TokenBucket) SetRate(rate uint32) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if float64(rate) == b.rate {
+		return
+	}
+	b.fill(time.Now())
+	b.rate = float64(rate)
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+}
+
+func (b *TokenBucket) GetRate() uint32 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return uint32(b.rate)
+}
+
+// Take takes n tokens and returns true if there are enough tokens, or the
+// bucket is full. Otherwise, it returns false without taking anything.
+func (b *TokenBucket) Take(n uint32) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.rate == 0 {
+		return true
+	}
+	b.fill(time.Now())
+	if b.tokens < math.Min(float64(n), b.rate) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// Refund gives back n tokens taken, for a request rejected by another limit.
+func (b *TokenBucket) Refund(n uint32) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.rate == 0 {
+		return
+	}
+	b.fill(time.Now())
+	b.tokens += float64(n)
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+}
+
+func (b *TokenBucket) LimitReached() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.rate == 0 {
+		return false
+	}
+	b.fill(time.Now())
+	return b.tokens < math.Min(1, b.rate)
+}
+
+// Throttle waits until a token is available, and takes it.
+func (b *TokenBucket) Throttle() {
+	for {
+		b.mtx.Lock()
+		if b.rate == 0 {
+			b.mtx.Unlock()
+			return
+		}
+		b.fill(time.Now())
+		need := math.Min(1, b.rate)
+		if b.tokens >= need {
+			b.tokens--
+			b.mtx.Unlock()
+			return
+		}
+		wait := time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+		b.mtx.Unlock()
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,65 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(10)
+	for i := 0; i < 10; i++ {
+		if !b.Take(1) {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	if b.Take(1) || !b.LimitReached() {
+		t.Error("limit should be reached")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if !b.Take(1) {
+		t.Error("bucket should be refilled")
+	}
+
+	// a cost larger than the rate is allowed once, and has to be paid back
+	b = NewTokenBucket(100)
+	if !b.Take(150) {
+		t.Error("large cost should be allowed with a full bucket")
+	}
+	if b.Take(1) {
+		t.Error("debt should be paid first")
+	}
+
+	// tokens refunded are available again
+	b = NewTokenBucket(10)
+	if !b.Take(10) || b.Take(1) {
+		t.Error("bucket should be empty")
+	}
+	b.Refund(2)
+	if !b.Take(2) {
+		t.Error("refunded tokens should be available")
+	}
+
+	b.SetRate(0)
+	if !b.Take(1000) || b.LimitReached() {
+		t.Error("0 rate should mean no limit")
+	}
+}
@@ -81,6 +81,19 @@ func (cfg *Config) SetListeners(values []string) {
 		if strings.HasPrefix(str, "ssl:") {
 			str = strings.TrimPrefix(str, "ssl:")
 			lncfg.SSLEnabled = true
+		} else if strings.HasPrefix(str, "http:") {
+			str = strings.TrimPrefix(str, "http:")
+			lncfg.HTTPEnabled = true
+		} else if strings.HasPrefix(str, "grpc:") {
+			str = strings.TrimPrefix(str, "grpc:")
+			lncfg.GRPCEnabled = true
+			if strings.HasPrefix(str, "ssl:") {
+				str = strings.TrimPrefix(str, "ssl:")
+				lncfg.SSLEnabled = true
+			}
+		} else if strings.HasPrefix(str, "resp:") {
+			str = strings.TrimPrefix(str, "resp:")
+			lncfg.RESPEnabled = true
 		}
 		if !strings.Contains(str, ":") {
 			lncfg.Addr = ":" + str
@@ -0,0 +1,57 @@
+;; append.wasm, the UDF returning the value with the params appended.
+(module
+  (memory (export "memory") 1)
+  (global $heap (mut i32) (i32.const 1024))
+  (data (i32.const 16) "no param")
+
+  (func $alloc (export "alloc") (param $size i32) (result i32) (local $grow i32)
+    global.get $heap
+    global.get $heap
+    local.get $size
+    i32.add
+    global.set $heap
+    ;; grow the memory to hold the heap
+    global.get $heap
+    i32.const 16
+    i32.shr_u
+    i32.const 1
+    i32.add
+    memory.size
+    i32.sub
+    local.tee $grow
+    i32.const 0
+    i32.gt_s
+    if
+      local.get $grow
+      memory.grow
+      drop
+    end)
+
+  (func (export "udf_call") (param $kp i32) (param $kl i32) (param $vp i32) (param $vl i32)
+      (param $pp i32) (param $pl i32) (result i64) (local $res i32)
+    local.get $pl
+    i32.eqz
+    if
+      ;; error message "no param" at 16
+      i64.const -68719476744
+      return
+    end
+    local.get $vl
+    local.get $pl
+    i32.add
+    call $alloc
+    local.set $res
+    (memory.copy (local.get $res) (local.get $vp) (local.get $vl))
+    (memory.copy (i32.add (local.get $res) (local.get $vl)) (local.get $pp) (local.get $pl))
+    local.get $res
+    i64.extend_i32_u
+    i64.const 32
+    i64.shl
+    local.get $vl
+    local.get $pl
+    i32.add
+    i64.extend_i32_u
+    i64.or)
+
+  (func (export "udf_version") (result i32)
+    i32.const 1))
@@ -22,18 +22,43 @@ package udf
 import (
 	"encoding/binary"
 	"errors"
+	"math"
 )
 
-// each built-in UDF class implements IUDF interface
+// Built-in UDFs. Each built-in UDF class implements IUDF interface.
+//
+// All integers in the values and the params are big-endian. A missing value
+// (UDFSet on a key that does not exist) is handled as the zero value: 0 for
+// counters, an empty list or set, and {} for JSON.
+//
+//	name              op      value            params
+//	sc                UDFSet  uint32           delta uint32
+//	counter32         UDFSet  int32            delta int32 [min int32 max int32]
+//	counter64         UDFSet  int64            delta int64 [min int64 max int64]
+//	list_append       UDFSet  list             maxItems uint32, item
+//	set_add           UDFSet  list             member
+//	set_remove        UDFSet  list             member
+//	set_contains      UDFGet  list             member
+//	json_merge_patch  UDFSet  JSON object      JSON merge patch (RFC 7396)
+//	json_path         UDFGet  JSON             path
+//
+// See each UDF for details, and EncodeList for the list format.
 
-// built-in simple counter UDF
+var errBadParam = errors.New("Bad Param")
+
+// built-in simple counter UDF. A missing value counts as 0, so that UDFSet
+// creates the counter. The counter wraps around on overflow, use counter32 or
+// counter64 instead.
 type CounterUDF struct{}
 
 func (u *CounterUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
-	if len(value) != 4 || len(params) != 4 {
-		return nil, errors.New("Bad Param")
+	if (len(value) != 0 && len(value) != 4) || len(params) != 4 {
+		return nil, errBadParam
+	}
+	var counter uint32
+	if len(value) != 0 {
+		counter = binary.BigEndian.Uint32(value)
 	}
-	var counter uint32 = binary.BigEndian.Uint32(value)
 	var delta uint32 = binary.BigEndian.Uint32(params)
 	counter += delta
 	res = make([]byte, 4)
@@ -49,7 +74,104 @@ func (u *CounterUDF) GetName() string {
 	return "sc"
 }
 
+// Counter32UDF adds a signed delta to a 4-byte signed counter. The params are
+// the 4-byte delta, optionally followed by the 4-byte min and max of the
+// counter. The result is clamped to [min, max], and to the int32 range if min
+// and max are not given, rather than overflowing.
+type Counter32UDF struct{}
+
+func (u *Counter32UDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	if (len(value) != 0 && len(value) != 4) || (len(params) != 4 && len(params) != 12) {
+		return nil, errBadParam
+	}
+	var counter int64
+	if len(value) != 0 {
+		counter = int64(int32(binary.BigEndian.Uint32(value)))
+	}
+	min, max := int64(math.MinInt32), int64(math.MaxInt32)
+	if len(params) == 12 {
+		min = int64(int32(binary.BigEndian.Uint32(params[4:])))
+		max = int64(int32(binary.BigEndian.Uint32(params[8:])))
+		if min > max {
+			return nil, errBadParam
+		}
+	}
+	counter = clamp(counter+int64(int32(binary.BigEndian.Uint32(params))), min, max)
+	res = make([]byte, 4)
+	binary.BigEndian.PutUint32(res, uint32(int32(counter)))
+	return res, nil
+}
+
+func (u *Counter32UDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *Counter32UDF) GetName() string {
+	return "counter32"
+}
+
+// Counter64UDF is Counter32UDF with 8-byte counter, delta, min and max.
+type Counter64UDF struct{}
+
+func (u *Counter64UDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	if (len(value) != 0 && len(value) != 8) || (len(params) != 8 && len(params) != 24) {
+		return nil, errBadParam
+	}
+	var counter int64
+	if len(value) != 0 {
+		counter = int64(binary.BigEndian.Uint64(value))
+	}
+	min, max := int64(math.MinInt64), int64(math.MaxInt64)
+	if len(params) == 24 {
+		min = int64(binary.BigEndian.Uint64(params[8:]))
+		max = int64(binary.BigEndian.Uint64(params[16:]))
+		if min > max {
+			return nil, errBadParam
+		}
+	}
+	delta := int64(binary.BigEndian.Uint64(params))
+	sum := counter + delta
+	if delta > 0 && sum < counter {
+		sum = math.MaxInt64
+	} else if delta < 0 && sum > counter {
+		sum = math.MinInt64
+	}
+	res = make([]byte, 8)
+	binary.BigEndian.PutUint64(res, uint64(clamp(sum, min, max)))
+	return res, nil
+}
+
+func (u *Counter64UDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *Counter64UDF) GetName() string {
+	return "counter64"
+}
+
+func clamp(v int64, min int64, max int64) int64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
 // Register built-in UDFs
 func registerBuiltinUDFs(um *UDFMap) {
-	(*um)["sc"] = &CounterUDF{}
+	for _, u := range []IUDF{
+		&CounterUDF{},
+		&Counter32UDF{},
+		&Counter64UDF{},
+		&ListAppendUDF{},
+		&SetAddUDF{},
+		&SetRemoveUDF{},
+		&SetContainsUDF{},
+		&JSONMergePatchUDF{},
+		&JSONPathUDF{},
+	} {
+		(*um)[u.GetName()] = u
+	}
 }
@@ -0,0 +1,146 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func callBuiltin(t *testing.T, name string, value []byte, params []byte) []byte {
+	t.Helper()
+	mgr, _ := NewUDFManager("")
+	u := mgr.GetUDF(name)
+	if u == nil {
+		t.Fatalf("no built-in udf %s", name)
+	}
+	res, err := u.Call([]byte("k"), value, params)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	return res
+}
+
+func int64Bytes(v ...int64) []byte {
+	b := make([]byte, 8*len(v))
+	for i, n := range v {
+		binary.BigEndian.PutUint64(b[8*i:], uint64(n))
+	}
+	return b
+}
+
+func int32Bytes(v ...int32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, n := range v {
+		binary.BigEndian.PutUint32(b[4*i:], uint32(n))
+	}
+	return b
+}
+
+func TestBuiltinCounters(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  []byte
+		params []byte
+		expect []byte
+	}{
+		{"counter64", nil, int64Bytes(-3), int64Bytes(-3)},
+		{"counter64", int64Bytes(5), int64Bytes(2), int64Bytes(7)},
+		{"counter64", int64Bytes(math.MaxInt64 - 1), int64Bytes(10), int64Bytes(math.MaxInt64)},
+		{"counter64", int64Bytes(math.MinInt64 + 1), int64Bytes(-10), int64Bytes(math.MinInt64)},
+		{"counter64", int64Bytes(8), int64Bytes(5, 0, 10), int64Bytes(10)},
+		{"counter64", int64Bytes(2), int64Bytes(-5, 0, 10), int64Bytes(0)},
+		{"counter32", nil, int32Bytes(4), int32Bytes(4)},
+		{"counter32", int32Bytes(math.MaxInt32), int32Bytes(1), int32Bytes(math.MaxInt32)},
+		{"counter32", int32Bytes(-1), int32Bytes(-2, -2, 2), int32Bytes(-2)},
+	}
+	for _, tc := range tests {
+		if res := callBuiltin(t, tc.name, tc.value, tc.params); !bytes.Equal(res, tc.expect) {
+			t.Errorf("%s(%v, %v) = %v, expected %v", tc.name, tc.value, tc.params, res, tc.expect)
+		}
+	}
+
+	mgr, _ := NewUDFManager("")
+	if _, err := mgr.GetUDF("counter64").Call(nil, nil, int64Bytes(1, 10, 0)); err == nil {
+		t.Error("expected error for min > max")
+	}
+	if _, err := mgr.GetUDF("counter32").Call(nil, []byte{1}, int32Bytes(1)); err == nil {
+		t.Error("expected error for bad value")
+	}
+}
+
+func TestBuiltinListAndSet(t *testing.T) {
+	max := int32Bytes(2)
+	list := callBuiltin(t, "list_append", nil, append(max, 'a'))
+	list = callBuiltin(t, "list_append", list, append(max, 'b'))
+	list = callBuiltin(t, "list_append", list, append(max, 'c'))
+	if !bytes.Equal(list, EncodeList([][]byte{[]byte("b"), []byte("c")})) {
+		t.Errorf("unexpected list %v", list)
+	}
+
+	set := callBuiltin(t, "set_add", nil, []byte("x"))
+	set = callBuiltin(t, "set_add", set, []byte("y"))
+	set = callBuiltin(t, "set_add", set, []byte("x"))
+	items, err := DecodeList(set)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("unexpected set %v, err=%v", set, err)
+	}
+	if res := callBuiltin(t, "set_contains", set, []byte("y")); !bytes.Equal(res, []byte{1}) {
+		t.Error("y should be in the set")
+	}
+	set = callBuiltin(t, "set_remove", set, []byte("y"))
+	if res := callBuiltin(t, "set_contains", set, []byte("y")); !bytes.Equal(res, []byte{0}) {
+		t.Error("y should not be in the set")
+	}
+	if !bytes.Equal(set, EncodeList([][]byte{[]byte("x")})) {
+		t.Errorf("unexpected set %v", set)
+	}
+	if _, err = DecodeList([]byte{0, 0, 0, 5, 'a'}); err == nil {
+		t.Error("expected error for truncated list")
+	}
+}
+
+func TestBuiltinJSON(t *testing.T) {
+	doc := callBuiltin(t, "json_merge_patch", nil, []byte(`{"a":{"b":1,"c":2},"d":[1,2]}`))
+	doc = callBuiltin(t, "json_merge_patch", doc, []byte(`{"a":{"c":null,"e":12345678901234567890},"f":"x"}`))
+	if string(doc) != `{"a":{"b":1,"e":12345678901234567890},"d":[1,2],"f":"x"}` {
+		t.Errorf("unexpected merge result %s", doc)
+	}
+
+	for path, expect := range map[string]string{
+		"":      string(doc),
+		"$.a.b": "1",
+		"d.1":   "2",
+		"f":     `"x"`,
+		"$.a":   `{"b":1,"e":12345678901234567890}`,
+	} {
+		if res := callBuiltin(t, "json_path", doc, []byte(path)); string(res) != expect {
+			t.Errorf("json_path %q = %s, expected %s", path, res, expect)
+		}
+	}
+	mgr, _ := NewUDFManager("")
+	for _, path := range []string{"x", "d.2", "a.b.c"} {
+		if _, err := mgr.GetUDF("json_path").Call(nil, doc, []byte(path)); err != errPathNotFound {
+			t.Errorf("json_path %q: expected path not found, got %v", path, err)
+		}
+	}
+}
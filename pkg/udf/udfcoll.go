@@ -0,0 +1,174 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Built-in UDFs on lists and sets. Both are stored as a list: the items one
+// after the other, each prefixed with its 4-byte big-endian length. The items
+// of a set are unique, in insertion order.
+
+// EncodeList returns the list of the given items.
+func EncodeList(items [][]byte) []byte {
+	sz := 0
+	for _, item := range items {
+		sz += 4 + len(item)
+	}
+	buf := make([]byte, 0, sz)
+	for _, item := range items {
+		buf = appendItem(buf, item)
+	}
+	return buf
+}
+
+// DecodeList returns the items of a list. The items refer to data.
+func DecodeList(data []byte) (items [][]byte, err error) {
+	for len(data) != 0 {
+		if len(data) < 4 {
+			return nil, errBadParam
+		}
+		sz := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(sz) > uint64(len(data)) {
+			return nil, errBadParam
+		}
+		items = append(items, data[:sz])
+		data = data[sz:]
+	}
+	return
+}
+
+func appendItem(buf []byte, item []byte) []byte {
+	var sz [4]byte
+	binary.BigEndian.PutUint32(sz[:], uint32(len(item)))
+	buf = append(buf, sz[:]...)
+	return append(buf, item...)
+}
+
+func indexOf(items [][]byte, member []byte) int {
+	for i, item := range items {
+		if bytes.Equal(item, member) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ListAppendUDF appends an item to a list. The params are the 4-byte max number
+// of items, followed by the item. The oldest items are removed if the list
+// grows over the max. The list is not capped if the max is 0.
+type ListAppendUDF struct{}
+
+func (u *ListAppendUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	if len(params) < 4 {
+		return nil, errBadParam
+	}
+	var items [][]byte
+	if items, err = DecodeList(value); err != nil {
+		return
+	}
+	maxItems := binary.BigEndian.Uint32(params)
+	items = append(items, params[4:])
+	if maxItems != 0 && uint64(len(items)) > uint64(maxItems) {
+		items = items[uint64(len(items))-uint64(maxItems):]
+	}
+	return EncodeList(items), nil
+}
+
+func (u *ListAppendUDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *ListAppendUDF) GetName() string {
+	return "list_append"
+}
+
+// SetAddUDF adds the member in params to a set, if not already in it.
+type SetAddUDF struct{}
+
+func (u *SetAddUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	var items [][]byte
+	if items, err = DecodeList(value); err != nil {
+		return
+	}
+	if indexOf(items, params) >= 0 {
+		return value, nil
+	}
+	res = make([]byte, 0, len(value)+4+len(params))
+	res = append(res, value...)
+	return appendItem(res, params), nil
+}
+
+func (u *SetAddUDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *SetAddUDF) GetName() string {
+	return "set_add"
+}
+
+// SetRemoveUDF removes the member in params from a set, if in it.
+type SetRemoveUDF struct{}
+
+func (u *SetRemoveUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	var items [][]byte
+	if items, err = DecodeList(value); err != nil {
+		return
+	}
+	i := indexOf(items, params)
+	if i < 0 {
+		return value, nil
+	}
+	return EncodeList(append(items[:i:i], items[i+1:]...)), nil
+}
+
+func (u *SetRemoveUDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *SetRemoveUDF) GetName() string {
+	return "set_remove"
+}
+
+// SetContainsUDF returns 1 byte, 1 if the member in params is in the set, 0
+// otherwise. It is meant for UDFGet.
+type SetContainsUDF struct{}
+
+func (u *SetContainsUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	var items [][]byte
+	if items, err = DecodeList(value); err != nil {
+		return
+	}
+	if indexOf(items, params) >= 0 {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
+func (u *SetContainsUDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *SetContainsUDF) GetName() string {
+	return "set_contains"
+}
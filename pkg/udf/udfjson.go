@@ -0,0 +1,132 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Built-in UDFs on JSON values.
+
+var errPathNotFound = errors.New("path not found")
+
+func decodeJSON(data []byte) (v interface{}, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		return
+	}
+	if dec.More() {
+		err = errors.New("invalid JSON: trailing data")
+	}
+	return
+}
+
+// JSONMergePatchUDF applies the JSON merge patch (RFC 7396) in params to a JSON
+// value: the members of a patch object replace the ones of the value,
+// recursively for objects, and the members set to null are removed.
+type JSONMergePatchUDF struct{}
+
+func (u *JSONMergePatchUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	var target, patch interface{}
+	if len(value) != 0 {
+		if target, err = decodeJSON(value); err != nil {
+			return
+		}
+	}
+	if patch, err = decodeJSON(params); err != nil {
+		return
+	}
+	return json.Marshal(mergePatch(target, patch))
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func (u *JSONMergePatchUDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *JSONMergePatchUDF) GetName() string {
+	return "json_merge_patch"
+}
+
+// JSONPathUDF returns the JSON of the element of a JSON value at the path in
+// params. It is meant for UDFGet. The path is the member names and the array
+// indexes separated by '.', optionally prefixed with "$", e.g. "$.a.b.0". An
+// empty path, or "$", is the whole value.
+type JSONPathUDF struct{}
+
+func (u *JSONPathUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	var v interface{}
+	if v, err = decodeJSON(value); err != nil {
+		return
+	}
+	path := strings.TrimPrefix(string(params), "$")
+	path = strings.TrimPrefix(path, ".")
+	if len(path) != 0 {
+		for _, name := range strings.Split(path, ".") {
+			switch e := v.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if v, ok = e[name]; !ok {
+					return nil, errPathNotFound
+				}
+			case []interface{}:
+				i, err := strconv.Atoi(name)
+				if err != nil || i < 0 || i >= len(e) {
+					return nil, errPathNotFound
+				}
+				v = e[i]
+			default:
+				return nil, errPathNotFound
+			}
+		}
+	}
+	return json.Marshal(v)
+}
+
+func (u *JSONPathUDF) GetVersion() uint32 {
+	return 1
+}
+
+func (u *JSONPathUDF) GetName() string {
+	return "json_path"
+}
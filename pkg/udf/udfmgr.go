@@ -20,7 +20,17 @@
 package udf
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"sync/atomic"
+	"time"
+
+	"juno/third_party/forked/golang/glog"
+
+	"juno/pkg/util"
 )
 
 type IUDF interface {
@@ -36,17 +46,39 @@ func newUDFMap() (m *UDFMap) {
 	return (*UDFMap)(&um)
 }
 
+type Config struct {
+	Dir string
+	// Limits of each call to a WASM UDF. The memory limit is in 64KiB pages.
+	WasmCallTimeout      util.Duration
+	WasmMemoryLimitPages uint32
+	// If not 0, the UDF dir is checked at this interval, and the UDFs are
+	// reloaded if any module has been added, removed or replaced.
+	ReloadInterval util.Duration
+}
+
+var DefaultConfig = Config{
+	WasmCallTimeout:      util.Duration{100 * time.Millisecond},
+	WasmMemoryLimitPages: 256,
+}
+
 type UDFMgr struct {
-	index  int32
-	udfs   [2]*UDFMap
-	udfDir string
+	index int32
+	udfs  [2]*UDFMap
+	conf  Config
+	mtx   sync.Mutex
 }
 
 var theMgr *UDFMgr
 
 func Init(udfDir string) {
+	conf := DefaultConfig
+	conf.Dir = udfDir
+	InitWithConfig(conf)
+}
+
+func InitWithConfig(conf Config) {
 	if theMgr == nil {
-		theMgr, _ = NewUDFManager(udfDir)
+		theMgr, _ = NewUDFManagerWithConfig(conf)
 	}
 }
 
@@ -55,28 +87,79 @@ func GetUDFManager() *UDFMgr {
 }
 
 func NewUDFManager(udfDir string) (m *UDFMgr, err error) {
+	conf := DefaultConfig
+	conf.Dir = udfDir
+	return NewUDFManagerWithConfig(conf)
+}
+
+func NewUDFManagerWithConfig(conf Config) (m *UDFMgr, err error) {
 	mgr := &UDFMgr{
-		index:  0,
-		udfDir: udfDir,
+		index: 0,
+		conf:  conf,
 	}
+	sig := dirSignature(conf.Dir)
 	mgr.Init()
+	if conf.ReloadInterval.Duration != 0 && len(conf.Dir) != 0 {
+		go mgr.watch(sig)
+	}
 	return mgr, nil
 }
 
 // thread safe
 func (m *UDFMgr) Init() (err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
 	var next int32 = (m.index + 1) % 2
 	mp := newUDFMap()
 
 	registerBuiltinUDFs(mp)
 	registerDummyUDFs(mp)
-	loadUDFPlugins(m.udfDir, mp)
+	loadUDFPlugins(m.conf.Dir, mp)
+	loadWasmUDFs(&m.conf, mp)
 
+	// the map being replaced has been retired by the previous swap. A UDF may
+	// still be called if it was looked up before, closing a WASM UDF waits
+	// for its calls in progress.
+	old := m.udfs[next]
 	m.udfs[next] = mp
 	atomic.StoreInt32(&m.index, next)
+	if old != nil {
+		go func() {
+			for _, u := range *old {
+				if w, ok := u.(*WasmUDF); ok {
+					w.Close()
+				}
+			}
+		}()
+	}
 	return nil
 }
 
+// watch reloads the UDFs once the content of the UDF dir has changed.
+func (m *UDFMgr) watch(last string) {
+	for range time.Tick(m.conf.ReloadInterval.Duration) {
+		if sig := dirSignature(m.conf.Dir); sig != last {
+			glog.Infof("udf dir %s changed, reloading", m.conf.Dir)
+			m.Init()
+			last = sig
+		}
+	}
+}
+
+func dirSignature(dir string) string {
+	if len(dir) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	list, _ := filepath.Glob(filepath.Join(dir, "*"))
+	for _, path := range list {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&buf, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return buf.String()
+}
+
 // thread safe
 func (m *UDFMgr) GetUDF(name string) IUDF {
 	var ix int32 = atomic.LoadInt32(&m.index)
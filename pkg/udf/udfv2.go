@@ -0,0 +1,136 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RecordInfo is the record a UDF is applied to.
+type RecordInfo struct {
+	Namespace    []byte
+	Key          []byte
+	Version      uint32
+	CreationTime uint32
+	TimeToLive   uint32
+}
+
+// IUDFv2 is implemented by the UDFs taking the record info. UDFs implementing
+// IUDF only are called with the key only.
+//
+// An error returned by a UDF fails the request with OpStatusUDFError, and its
+// message is returned to the client as the payload of the response.
+type IUDFv2 interface {
+	IUDF
+	CallWithRecord(rec *RecordInfo, value []byte, params []byte) (res []byte, err error)
+}
+
+// CallStats is the statistics of the calls to one UDF.
+type CallStats struct {
+	Name         string
+	NumCalls     uint64
+	NumErrors    uint64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+type callStatsT struct {
+	numCalls     uint64
+	numErrors    uint64
+	totalLatency int64
+	maxLatency   int64
+}
+
+var (
+	ErrNoUDF = errors.New("udf not found")
+
+	callStats   = make(map[string]*callStatsT)
+	callStatsMu sync.RWMutex
+)
+
+// Call calls the UDF of the given name, with the record info if it implements
+// IUDFv2, and records the call statistics. It returns ErrNoUDF if the UDF does
+// not exist.
+func (m *UDFMgr) Call(name string, rec *RecordInfo, value []byte, params []byte) (res []byte, err error) {
+	u := m.GetUDF(name)
+	if u == nil {
+		return nil, ErrNoUDF
+	}
+	start := time.Now()
+	if v2, ok := u.(IUDFv2); ok {
+		res, err = v2.CallWithRecord(rec, value, params)
+	} else {
+		res, err = u.Call(rec.Key, value, params)
+	}
+	getCallStats(name).onCall(time.Since(start), err)
+	return
+}
+
+func getCallStats(name string) *callStatsT {
+	callStatsMu.RLock()
+	st, ok := callStats[name]
+	callStatsMu.RUnlock()
+	if ok {
+		return st
+	}
+	callStatsMu.Lock()
+	defer callStatsMu.Unlock()
+	if st, ok = callStats[name]; !ok {
+		st = &callStatsT{}
+		callStats[name] = st
+	}
+	return st
+}
+
+func (s *callStatsT) onCall(latency time.Duration, err error) {
+	atomic.AddUint64(&s.numCalls, 1)
+	if err != nil {
+		atomic.AddUint64(&s.numErrors, 1)
+	}
+	atomic.AddInt64(&s.totalLatency, int64(latency))
+	for {
+		max := atomic.LoadInt64(&s.maxLatency)
+		if int64(latency) <= max || atomic.CompareAndSwapInt64(&s.maxLatency, max, int64(latency)) {
+			break
+		}
+	}
+}
+
+// GetCallStats returns the statistics of the UDFs having been called, sorted by
+// name.
+func GetCallStats() (stats []CallStats) {
+	callStatsMu.RLock()
+	defer callStatsMu.RUnlock()
+	for name, st := range callStats {
+		stats = append(stats, CallStats{
+			Name:         name,
+			NumCalls:     atomic.LoadUint64(&st.numCalls),
+			NumErrors:    atomic.LoadUint64(&st.numErrors),
+			TotalLatency: time.Duration(atomic.LoadInt64(&st.totalLatency)),
+			MaxLatency:   time.Duration(atomic.LoadInt64(&st.maxLatency)),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return
+}
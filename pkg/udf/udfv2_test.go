@@ -0,0 +1,91 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type recInfoUDF struct{}
+
+func (u *recInfoUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	return nil, errors.New("v1 called")
+}
+
+func (u *recInfoUDF) CallWithRecord(rec *RecordInfo, value []byte, params []byte) (res []byte, err error) {
+	if string(params) == "fail" {
+		return nil, errors.New("bad params")
+	}
+	return []byte(fmt.Sprintf("%s:%s:%d:%d:%d:%s", rec.Namespace, rec.Key, rec.Version,
+		rec.CreationTime, rec.TimeToLive, value)), nil
+}
+
+func (u *recInfoUDF) GetVersion() uint32 {
+	return 2
+}
+
+func (u *recInfoUDF) GetName() string {
+	return "recinfo"
+}
+
+func TestUDFCall(t *testing.T) {
+	mgr, _ := NewUDFManager("")
+	(*mgr.udfs[mgr.index])["recinfo"] = &recInfoUDF{}
+
+	rec := &RecordInfo{
+		Namespace:    []byte("ns"),
+		Key:          []byte("k1"),
+		Version:      3,
+		CreationTime: 100,
+		TimeToLive:   60,
+	}
+	res, err := mgr.Call("recinfo", rec, []byte("v"), nil)
+	if err != nil || string(res) != "ns:k1:3:100:60:v" {
+		t.Errorf("unexpected result %q, err=%v", res, err)
+	}
+	if _, err = mgr.Call("recinfo", rec, []byte("v"), []byte("fail")); err == nil || err.Error() != "bad params" {
+		t.Errorf("expected udf error, got %v", err)
+	}
+	// v1 udfs still get the key
+	if res, err = mgr.Call("echo", rec, []byte("v"), nil); err != nil || string(res) != "v" {
+		t.Errorf("unexpected result %q, err=%v", res, err)
+	}
+	if _, err = mgr.Call("nonexistent", rec, nil, nil); err != ErrNoUDF {
+		t.Errorf("expected ErrNoUDF, got %v", err)
+	}
+
+	var found bool
+	for _, st := range GetCallStats() {
+		if st.Name == "nonexistent" {
+			t.Error("stats recorded for nonexistent udf")
+		}
+		if st.Name == "recinfo" {
+			found = true
+			if st.NumCalls != 2 || st.NumErrors != 1 || st.MaxLatency > st.TotalLatency {
+				t.Errorf("unexpected stats %+v", st)
+			}
+		}
+	}
+	if !found {
+		t.Error("no stats for recinfo")
+	}
+}
@@ -0,0 +1,276 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"juno/third_party/forked/golang/glog"
+)
+
+// A WASM UDF is a module named <udf name>.wasm in the UDF dir, exporting
+//
+//	memory
+//	alloc(size i32) i32
+//	udf_call(keyPtr, keyLen, valuePtr, valueLen, paramsPtr, paramsLen i32) i64
+//	udf_version() i32 (optional, 1 if not exported)
+//
+// The inputs are copied to buffers allocated with alloc. udf_call returns the
+// location of the result, (ptr << 32) | len, or the negative of the location of
+// an error message.
+//
+// Each call runs in a new instance of the module, with the CPU time and memory
+// limited by Config.WasmCallTimeout and Config.WasmMemoryLimitPages.
+type WasmUDF struct {
+	// held for read by the calls, Close waits for them to drain
+	mtx      sync.RWMutex
+	closed   bool
+	name     string
+	version  uint32
+	timeout  time.Duration
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+var kWasmUDFNoVersion uint32 = 1
+
+type wasmSignatureT struct {
+	params  []api.ValueType
+	results []api.ValueType
+	opt     bool
+}
+
+// kWasmUDFExports is the ABI of the functions exported by a WASM UDF.
+var kWasmUDFExports = map[string]wasmSignatureT{
+	"alloc": {params: []api.ValueType{api.ValueTypeI32}, results: []api.ValueType{api.ValueTypeI32}},
+	"udf_call": {
+		params:  []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32},
+		results: []api.ValueType{api.ValueTypeI64},
+	},
+	"udf_version": {results: []api.ValueType{api.ValueTypeI32}, opt: true},
+}
+
+// checkWasmExports checks the module exports a memory and the functions of the
+// UDF ABI with the expected signatures.
+func checkWasmExports(compiled wazero.CompiledModule) error {
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok {
+		return errors.New("memory not exported")
+	}
+	exports := compiled.ExportedFunctions()
+	for name, sig := range kWasmUDFExports {
+		f, ok := exports[name]
+		if !ok {
+			if sig.opt {
+				continue
+			}
+			return fmt.Errorf("%s not exported", name)
+		}
+		if !sameValueTypes(f.ParamTypes(), sig.params) || !sameValueTypes(f.ResultTypes(), sig.results) {
+			return fmt.Errorf("%s has signature %v -> %v, expected %v -> %v", name,
+				f.ParamTypes(), f.ResultTypes(), sig.params, sig.results)
+		}
+	}
+	return nil
+}
+
+func sameValueTypes(a []api.ValueType, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func loadWasmUDF(path string, conf *Config) (u *WasmUDF, err error) {
+	var code []byte
+	if code, err = os.ReadFile(path); err != nil {
+		return
+	}
+	ctx := context.Background()
+	rtConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if conf.WasmMemoryLimitPages != 0 {
+		rtConfig = rtConfig.WithMemoryLimitPages(conf.WasmMemoryLimitPages)
+	}
+	rt := wazero.NewRuntimeWithConfig(ctx, rtConfig)
+	var compiled wazero.CompiledModule
+	if compiled, err = rt.CompileModule(ctx, code); err != nil {
+		rt.Close(ctx)
+		return
+	}
+	if err = checkWasmExports(compiled); err != nil {
+		rt.Close(ctx)
+		return
+	}
+	u = &WasmUDF{
+		name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		version:  kWasmUDFNoVersion,
+		timeout:  conf.WasmCallTimeout.Duration,
+		runtime:  rt,
+		compiled: compiled,
+	}
+	if _, ok := compiled.ExportedFunctions()["udf_version"]; ok {
+		var mod api.Module
+		var res []uint64
+		if mod, err = u.instantiate(ctx); err == nil {
+			res, err = mod.ExportedFunction("udf_version").Call(ctx)
+			mod.Close(ctx)
+		}
+		if err == nil && len(res) != 1 {
+			err = errors.New("udf_version returned no result")
+		}
+		if err != nil {
+			u.Close()
+			return nil, err
+		}
+		u.version = uint32(res[0])
+	}
+	return
+}
+
+// instantiate returns a new instance of the module. Anonymous modules can be
+// instantiated more than once in the same runtime.
+func (u *WasmUDF) instantiate(ctx context.Context) (api.Module, error) {
+	return u.runtime.InstantiateModule(ctx, u.compiled, wazero.NewModuleConfig().WithName(""))
+}
+
+func (u *WasmUDF) Call(key []byte, value []byte, params []byte) (res []byte, err error) {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+	if u.closed {
+		return nil, fmt.Errorf("udf %s unloaded", u.name)
+	}
+	ctx := context.Background()
+	if u.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+	var mod api.Module
+	if mod, err = u.instantiate(ctx); err != nil {
+		return
+	}
+	defer mod.Close(context.Background())
+
+	args := make([]uint64, 0, 6)
+	for _, in := range [][]byte{key, value, params} {
+		var ptr uint32
+		if len(in) != 0 {
+			if ptr, err = wasmAlloc(ctx, mod, in); err != nil {
+				return
+			}
+		}
+		args = append(args, uint64(ptr), uint64(len(in)))
+	}
+	var ret []uint64
+	if ret, err = mod.ExportedFunction("udf_call").Call(ctx, args...); err != nil {
+		return
+	}
+	if len(ret) != 1 {
+		return nil, errors.New("udf_call returned no result")
+	}
+	loc := int64(ret[0])
+	isErr := loc < 0
+	if isErr {
+		loc = -loc
+	}
+	out, ok := mod.Memory().Read(uint32(loc>>32), uint32(loc))
+	if !ok {
+		return nil, errors.New("udf result out of range")
+	}
+	if isErr {
+		return nil, errors.New(string(out))
+	}
+	// the memory is gone with the module
+	res = make([]byte, len(out))
+	copy(res, out)
+	return
+}
+
+func wasmAlloc(ctx context.Context, mod api.Module, in []byte) (ptr uint32, err error) {
+	var ret []uint64
+	if ret, err = mod.ExportedFunction("alloc").Call(ctx, uint64(len(in))); err != nil {
+		return
+	}
+	if len(ret) != 1 {
+		return 0, errors.New("alloc returned no result")
+	}
+	ptr = uint32(ret[0])
+	if !mod.Memory().Write(ptr, in) {
+		err = errors.New("udf memory limit exceeded")
+	}
+	return
+}
+
+func (u *WasmUDF) GetVersion() uint32 {
+	return u.version
+}
+
+func (u *WasmUDF) GetName() string {
+	return u.name
+}
+
+// Close waits for the calls in progress to complete, and releases the runtime
+// of the module. The UDF cannot be called after.
+func (u *WasmUDF) Close() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if !u.closed {
+		u.closed = true
+		u.runtime.Close(context.Background())
+	}
+}
+
+func loadWasmUDFs(conf *Config, mp *UDFMap) {
+	if len(conf.Dir) == 0 {
+		return
+	}
+	list, err := filepath.Glob(filepath.Join(conf.Dir, "*.wasm"))
+	if err != nil || len(list) == 0 {
+		return
+	}
+	for _, path := range list {
+		u, err := loadWasmUDF(path, conf)
+		if err != nil {
+			glog.Errorf("fail to load wasm udf %s: %s", path, err)
+			continue
+		}
+		if _, exists := (*mp)[u.name]; exists {
+			glog.Errorf("udf with same name %s already exists, ignore", u.name)
+			u.Close()
+			continue
+		}
+		(*mp)[u.name] = u
+		glog.Infof("loaded one wasm udf: %s version %d", u.name, u.version)
+	}
+}
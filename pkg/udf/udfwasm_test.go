@@ -0,0 +1,174 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package udf
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"juno/pkg/util"
+)
+
+// same as append.wasm, except that udf_call loops forever
+var spinWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x14, 0x03, 0x60,
+	0x01, 0x7f, 0x01, 0x7f, 0x60, 0x06, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f,
+	0x01, 0x7e, 0x60, 0x00, 0x01, 0x7f, 0x03, 0x04, 0x03, 0x00, 0x01, 0x02,
+	0x05, 0x03, 0x01, 0x00, 0x01, 0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80,
+	0x08, 0x0b, 0x07, 0x2b, 0x04, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x02, 0x00, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x00, 0x00, 0x08, 0x75,
+	0x64, 0x66, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x00, 0x01, 0x0b, 0x75, 0x64,
+	0x66, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x00, 0x02, 0x0a,
+	0x36, 0x03, 0x25, 0x01, 0x01, 0x7f, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00,
+	0x6a, 0x24, 0x00, 0x23, 0x00, 0x41, 0x10, 0x76, 0x41, 0x01, 0x6a, 0x3f,
+	0x00, 0x6b, 0x22, 0x01, 0x41, 0x00, 0x4a, 0x04, 0x40, 0x20, 0x01, 0x40,
+	0x00, 0x1a, 0x0b, 0x0b, 0x09, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42,
+	0x00, 0x0b, 0x04, 0x00, 0x41, 0x01, 0x0b,
+}
+
+func TestWasmUDF(t *testing.T) {
+	mgr, _ := NewUDFManager("./example_plugins/wasm")
+	udf := mgr.GetUDF("append")
+	if udf == nil {
+		t.Fatal("can't find the append wasm udf")
+	}
+	if udf.GetVersion() != 1 {
+		t.Errorf("wrong version %d", udf.GetVersion())
+	}
+	r, err := udf.Call([]byte("k1"), []byte("hello "), []byte("world"))
+	if err != nil || string(r) != "hello world" {
+		t.Errorf("got %q %v", r, err)
+	}
+	if _, err = udf.Call([]byte("k1"), []byte("hello"), nil); err == nil || err.Error() != "no param" {
+		t.Errorf("expected no param error, got %v", err)
+	}
+}
+
+func TestWasmUDFLimits(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "spin.wasm"), spinWasm, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile("./example_plugins/wasm/append.wasm")
+	os.WriteFile(filepath.Join(dir, "append.wasm"), data, 0644)
+
+	conf := DefaultConfig
+	conf.Dir = dir
+	conf.WasmCallTimeout = util.Duration{Duration: 50 * time.Millisecond}
+	conf.WasmMemoryLimitPages = 2
+	mgr, _ := NewUDFManagerWithConfig(conf)
+
+	start := time.Now()
+	if _, err := mgr.GetUDF("spin").Call(nil, nil, []byte("p")); err == nil {
+		t.Error("endless udf should be stopped")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("endless udf stopped after %s", elapsed)
+	}
+	if _, err := mgr.GetUDF("append").Call(nil, make([]byte, 200*1024), []byte("p")); err == nil {
+		t.Error("memory limit should be exceeded")
+	}
+}
+
+// patchWasm returns a copy of spinWasm with the bytes following the export
+// name replaced.
+func patchWasm(name string, off int, b byte) []byte {
+	code := append([]byte{}, spinWasm...)
+	code[bytes.Index(code, []byte(name))+off] = b
+	return code
+}
+
+func TestWasmUDFBadABI(t *testing.T) {
+	modules := map[string][]byte{
+		// memory exported as memorx
+		"nomem": patchWasm("memory", len("memory")-1, 'x'),
+		// udf_call exported as the alloc function
+		"badcall": patchWasm("udf_call", len("udf_call")+1, 0),
+		// udf_version exported as the alloc function
+		"badversion": patchWasm("udf_version", len("udf_version")+1, 0),
+	}
+	dir := t.TempDir()
+	conf := DefaultConfig
+	for name, code := range modules {
+		path := filepath.Join(dir, name+".wasm")
+		if err := os.WriteFile(path, code, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if u, err := loadWasmUDF(path, &conf); err == nil {
+			u.Close()
+			t.Errorf("%s should be rejected", name)
+		}
+	}
+	path := filepath.Join(dir, "spin.wasm")
+	os.WriteFile(path, spinWasm, 0644)
+	if u, err := loadWasmUDF(path, &conf); err != nil {
+		t.Errorf("fail to load spin.wasm: %s", err)
+	} else {
+		u.Close()
+	}
+}
+
+func TestWasmUDFReload(t *testing.T) {
+	dir := t.TempDir()
+	conf := DefaultConfig
+	conf.Dir = dir
+	conf.ReloadInterval = util.Duration{Duration: 10 * time.Millisecond}
+	mgr, _ := NewUDFManagerWithConfig(conf)
+	if mgr.GetUDF("append") != nil {
+		t.Fatal("udf should not exist yet")
+	}
+	data, _ := os.ReadFile("./example_plugins/wasm/append.wasm")
+	os.WriteFile(filepath.Join(dir, "append.wasm"), data, 0644)
+	for i := 0; i < 100 && mgr.GetUDF("append") == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if mgr.GetUDF("append") == nil {
+		t.Error("udf not loaded after being added")
+	}
+}
+
+func TestWasmUDFCloseDrains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spin.wasm")
+	os.WriteFile(path, spinWasm, 0644)
+	conf := DefaultConfig
+	conf.WasmCallTimeout = util.Duration{Duration: 100 * time.Millisecond}
+	u, err := loadWasmUDF(path, &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		u.Call(nil, nil, []byte("p"))
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	u.Close()
+	select {
+	case <-done:
+	default:
+		t.Error("Close returned before the call completed")
+	}
+	if _, err := u.Call(nil, nil, []byte("p")); err == nil {
+		t.Error("call after Close should fail")
+	}
+}
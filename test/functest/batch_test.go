@@ -0,0 +1,89 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package functest
+
+import (
+	"bytes"
+	"testing"
+
+	"juno/pkg/client"
+	"juno/test/testutil"
+)
+
+/***********************************************************************
+ *  Test batch operations
+ *  Batch set 10 records, batch get them together with a missing key,
+ *  the missing key returns no key error while others return their values
+ *  Batch destroy the records, batch get returns no key for all
+ ***********************************************************************/
+func TestBatchSetGetDestroy(t *testing.T) {
+	numKeys := 10
+	keys := make([][]byte, numKeys)
+	values := make([][]byte, numKeys)
+	for i := 0; i < numKeys; i++ {
+		keys[i] = testutil.GenerateRandomKey(32)
+		values[i] = testutil.GenerateRandomKey(64)
+	}
+
+	results, err := proxyClient.BatchSet(keys, values, client.WithTTL(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("batch set failed: %s", r.Err)
+		}
+	}
+
+	missingKey := testutil.GenerateRandomKey(32)
+	if results, err = proxyClient.BatchGet(append(keys, missingKey)); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != numKeys+1 {
+		t.Fatalf("expected %d results, got %d", numKeys+1, len(results))
+	}
+	for i := 0; i < numKeys; i++ {
+		if results[i].Err != nil {
+			t.Errorf("batch get failed: %s", results[i].Err)
+		} else if !bytes.Equal(results[i].Value, values[i]) {
+			t.Errorf("value mismatch for key %d", i)
+		}
+	}
+	if results[numKeys].Err != client.ErrNoKey {
+		t.Errorf("expected ErrNoKey for missing key, got %v", results[numKeys].Err)
+	}
+
+	if results, err = proxyClient.BatchDestroy(keys); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("batch destroy failed: %s", r.Err)
+		}
+	}
+	if results, err = proxyClient.BatchGet(keys); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != client.ErrNoKey {
+			t.Errorf("expected ErrNoKey after destroy, got %v", r.Err)
+		}
+	}
+}
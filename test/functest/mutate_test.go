@@ -0,0 +1,75 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package functest
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"juno/pkg/client"
+	"juno/test/testutil"
+)
+
+/***********************************************************************
+ *  Test read-modify-write
+ *  Concurrently increment a counter that does not exist yet with
+ *  Mutate and WithCreateIfMissing, no increment is lost
+ ***********************************************************************/
+func TestMutateConcurrentIncrement(t *testing.T) {
+	key := testutil.GenerateRandomKey(32)
+	numWorkers := 5
+	increment := func(old []byte, ctx client.IContext) ([]byte, error) {
+		n := 0
+		if old != nil {
+			var err error
+			if n, err = strconv.Atoi(string(old)); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(strconv.Itoa(n + 1)), nil
+	}
+	policy := client.RetryPolicy{MaxAttempts: 20}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := proxyClient.Mutate(key, increment, client.WithCreateIfMissing(),
+				client.WithMutatePolicy(policy), client.WithTTL(100)); err != nil {
+				t.Errorf("mutate failed: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _, err := proxyClient.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != strconv.Itoa(numWorkers) {
+		t.Errorf("expected %d, got %s", numWorkers, value)
+	}
+	if _, err = proxyClient.Mutate(testutil.GenerateRandomKey(32), increment); err != client.ErrNoKey {
+		t.Errorf("expected ErrNoKey without WithCreateIfMissing, got %v", err)
+	}
+	proxyClient.Destroy(key)
+}
@@ -0,0 +1,96 @@
+//
+//  Copyright 2023 PayPal Inc.
+//
+//  Licensed to the Apache Software Foundation (ASF) under one or more
+//  contributor license agreements.  See the NOTICE file distributed with
+//  this work for additional information regarding copyright ownership.
+//  The ASF licenses this file to You under the Apache License, Version 2.0
+//  (the "License"); you may not use this file except in compliance with
+//  the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package functest
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+
+	"juno/pkg/client"
+	"juno/test/testutil"
+)
+
+/***********************************************************************
+ *  Test UDFSet
+ *  Concurrently increment a counter that does not exist yet with the
+ *  built-in counter UDF, no increment is lost
+ ***********************************************************************/
+func TestUDFSetConcurrentIncrement(t *testing.T) {
+	key := testutil.GenerateRandomKey(32)
+	numWorkers := 5
+	delta := make([]byte, 4)
+	binary.BigEndian.PutUint32(delta, 2)
+	policy := client.RetryPolicy{MaxAttempts: 20}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := proxyClient.UDFSet(key, []byte("sc"), delta, client.WithRetry(policy),
+				client.WithTTL(100)); err != nil {
+				t.Errorf("udfset failed: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, recInfo, err := proxyClient.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value) != 4 || binary.BigEndian.Uint32(value) != uint32(2*numWorkers) {
+		t.Errorf("expected %d, got %v", 2*numWorkers, value)
+	}
+	if recInfo.GetVersion() != uint32(numWorkers) {
+		t.Errorf("expected version %d, got %d", numWorkers, recInfo.GetVersion())
+	}
+}
+
+/***********************************************************************
+ *  Test UDFSet errors
+ *  The record is not changed if the UDF fails or does not exist
+ ***********************************************************************/
+func TestUDFSetError(t *testing.T) {
+	key := testutil.GenerateRandomKey(32)
+	value := []byte("not a counter")
+	if _, err := proxyClient.Set(key, value); err != nil {
+		t.Fatal(err)
+	}
+	delta := make([]byte, 4)
+	_, err := proxyClient.UDFSet(key, []byte("sc"), delta)
+	var udfErr *client.UDFError
+	if !errors.As(err, &udfErr) || udfErr.Message == "" {
+		t.Errorf("expected UDFError, got %v", err)
+	}
+	if _, err = proxyClient.UDFSet(key, []byte("nonexistent"), delta); err != client.ErrNoUDF {
+		t.Errorf("expected ErrNoUDF, got %v", err)
+	}
+
+	v, recInfo, err := proxyClient.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v) != string(value) || recInfo.GetVersion() != 1 {
+		t.Errorf("record changed: %q version %d", v, recInfo.GetVersion())
+	}
+}